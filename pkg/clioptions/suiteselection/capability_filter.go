@@ -0,0 +1,103 @@
+package suiteselection
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	clientconfigv1 "github.com/openshift/client-go/config/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// clusterCapabilityFilter excludes the tests that cannot pass on the cluster because of its shape,
+// so that they are not scheduled only to skip themselves:
+//
+//   - [Capability:NAME] tests need every capability they name enabled in ClusterVersion.  A cluster
+//     that reports no capabilities, e.g. MicroShift without ClusterVersion, has every capability.
+//   - [Topology:MODE] tests need one of the control plane topologies they name in Infrastructure,
+//     e.g. SingleReplica, External for HyperShift, or HighlyAvailableArbiter.
+//   - [Platform:TYPE] tests need one of the platforms they name in Infrastructure, e.g. AWS.
+//   - [NetworkType:TYPE] tests need one of the network types they name in Network, e.g. OVNKubernetes.
+//
+// A topology, platform or network type that cannot be read from the cluster is empty, in which case
+// the tests that need one are excluded.
+type clusterCapabilityFilter struct {
+	// capabilities are the enabled capabilities, nil if the cluster does not report them
+	capabilities sets.String
+	topology     string
+	platform     string
+	networkType  string
+}
+
+func newClusterCapabilityFilter(ctx context.Context, configClient clientconfigv1.Interface) (*clusterCapabilityFilter, error) {
+	ret := &clusterCapabilityFilter{}
+
+	clusterVersion, err := configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	case clusterVersion.Status.Capabilities.EnabledCapabilities != nil:
+		ret.capabilities = sets.NewString()
+		for _, capability := range clusterVersion.Status.Capabilities.EnabledCapabilities {
+			ret.capabilities.Insert(string(capability))
+		}
+	}
+
+	infrastructure, err := configClient.ConfigV1().Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		ret.topology = string(infrastructure.Status.ControlPlaneTopology)
+		if infrastructure.Status.PlatformStatus != nil {
+			ret.platform = string(infrastructure.Status.PlatformStatus.Type)
+		}
+	}
+
+	network, err := configClient.ConfigV1().Networks().Get(ctx, "cluster", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		ret.networkType = network.Status.NetworkType
+	}
+
+	return ret, nil
+}
+
+func (f *clusterCapabilityFilter) includeTest(name string) bool {
+	if f.capabilities != nil && !f.capabilities.HasAll(labelValues(capabilityRegex, name)...) {
+		return false
+	}
+	return matchesAny(labelValues(topologyRegex, name), f.topology) &&
+		matchesAny(labelValues(platformRegex, name), f.platform) &&
+		matchesAny(labelValues(networkTypeRegex, name), f.networkType)
+}
+
+// matchesAny returns true if there are no values, or if one of them is value.
+func matchesAny(values []string, value string) bool {
+	return len(values) == 0 || (len(value) > 0 && sets.NewString(values...).Has(value))
+}
+
+func labelValues(re *regexp.Regexp, name string) []string {
+	values := []string{}
+	for _, match := range re.FindAllStringSubmatch(name, -1) {
+		if len(match) < 2 {
+			panic(fmt.Errorf("regexp match %v is invalid: len(match) < 2 for %v", match, name))
+		}
+		values = append(values, match[1])
+	}
+	return values
+}
+
+var (
+	capabilityRegex  = regexp.MustCompile(`\[Capability:([^]]*)\]`)
+	topologyRegex    = regexp.MustCompile(`\[Topology:([^]]*)\]`)
+	platformRegex    = regexp.MustCompile(`\[Platform:([^]]*)\]`)
+	networkTypeRegex = regexp.MustCompile(`\[NetworkType:([^]]*)\]`)
+)
@@ -0,0 +1,89 @@
+package suiteselection
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterCapabilityFilter(t *testing.T) {
+	sno := configfake.NewSimpleClientset(
+		&configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status: configv1.ClusterVersionStatus{
+				Capabilities: configv1.ClusterVersionCapabilitiesStatus{
+					EnabledCapabilities: []configv1.ClusterVersionCapability{"Console", "Ingress"},
+				},
+			},
+		},
+		&configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: configv1.InfrastructureStatus{
+				ControlPlaneTopology: configv1.SingleReplicaTopologyMode,
+				PlatformStatus:       &configv1.PlatformStatus{Type: configv1.AWSPlatformType},
+			},
+		},
+		&configv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.NetworkStatus{NetworkType: "OVNKubernetes"},
+		},
+	)
+
+	testCases := []struct {
+		name     string
+		test     string
+		expected bool
+	}{
+		{name: "no labels", test: "[sig-cli] oc works", expected: true},
+		{name: "enabled capability", test: "[sig-ui][Capability:Console] console works", expected: true},
+		{name: "disabled capability", test: "[sig-builds][Capability:Build] builds work"},
+		{name: "one of the capabilities disabled", test: "[sig-ui][Capability:Console][Capability:Build] console builds"},
+		{name: "matching topology", test: "[sig-node][Topology:SingleReplica] node reboots", expected: true},
+		{name: "one of the topologies", test: "[sig-etcd][Topology:HighlyAvailable][Topology:SingleReplica] etcd works", expected: true},
+		{name: "other topology", test: "[sig-etcd][Topology:HighlyAvailableArbiter] arbiter works"},
+		{name: "matching platform", test: "[sig-storage][Platform:AWS] EBS works", expected: true},
+		{name: "other platform", test: "[sig-storage][Platform:GCP] PD works"},
+		{name: "matching network type", test: "[sig-network][NetworkType:OVNKubernetes] egress IP works", expected: true},
+		{name: "other network type", test: "[sig-network][NetworkType:OpenShiftSDN] egress IP works"},
+	}
+
+	filter, err := newClusterCapabilityFilter(context.TODO(), sno)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := filter.includeTest(tc.test); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+
+	// a cluster without the config resources, e.g. MicroShift, has every capability and only runs
+	// the tests that need no topology, platform or network type
+	filter, err = newClusterCapabilityFilter(context.TODO(), configfake.NewSimpleClientset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		expected := !strings.Contains(tc.test, "[Topology:") && !strings.Contains(tc.test, "[Platform:") && !strings.Contains(tc.test, "[NetworkType:")
+		if got := filter.includeTest(tc.test); got != expected {
+			t.Errorf("%s: expected %t without config resources, got %t", tc.name, expected, got)
+		}
+	}
+
+	// a cluster version without capabilities predates them and has every capability
+	filter, err = newClusterCapabilityFilter(context.TODO(), configfake.NewSimpleClientset(
+		&configv1.ClusterVersion{ObjectMeta: metav1.ObjectMeta{Name: "version"}},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filter.includeTest("[sig-builds][Capability:Build] builds work") {
+		t.Errorf("expected capability tests to be included when the cluster reports no capabilities")
+	}
+}
@@ -37,6 +37,8 @@ type TestSuiteSelectionFlags struct {
 
 	// Regex allows a selection of a subset of tests
 	Regex string
+	// LabelQuery selects the tests whose labels satisfy a testginkgo.LabelQuery
+	LabelQuery string
 	// MatchFn if set is also used to filter the suite contents
 	MatchFn testginkgo.TestMatchFunc
 
@@ -52,6 +54,7 @@ func NewTestSuiteSelectionFlags(streams genericclioptions.IOStreams) *TestSuiteS
 func (f *TestSuiteSelectionFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&f.TestFile, "file", "f", f.TestFile, "Create a suite from the newline-delimited test names in this file.")
 	flags.StringVar(&f.Regex, "run", f.Regex, "Regular expression of tests to run.")
+	flags.StringVar(&f.LabelQuery, "label-query", f.LabelQuery, "Boolean expression over the bracketed labels of the tests to run, e.g. 'sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/*)'. Use the explain-labels command to see how it evaluates for a test.")
 }
 
 func (f *TestSuiteSelectionFlags) Validate() error {
@@ -100,18 +103,26 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 	if err != nil {
 		return nil, err
 	}
-	suite.AddRequiredMatchFunc(testFileMatchFn)
+	suite.AddNamedRequiredMatchFunc("file", testFileMatchFn)
 
 	if len(f.Regex) > 0 {
 		re, err := regexp.Compile(f.Regex)
 		if err != nil {
 			return nil, err
 		}
-		suite.AddRequiredMatchFunc(re.MatchString)
+		suite.AddNamedRequiredMatchFunc("run", re.MatchString)
 	}
 
-	suite.AddRequiredMatchFunc(f.MatchFn)
-	suite.AddRequiredMatchFunc(additionalMatchFn)
+	if len(f.LabelQuery) > 0 {
+		query, err := testginkgo.ParseLabelQuery(f.LabelQuery)
+		if err != nil {
+			return nil, err
+		}
+		suite.AddNamedRequiredMatchFunc("labels", query.Matches)
+	}
+
+	suite.AddNamedRequiredMatchFunc("match", f.MatchFn)
+	suite.AddNamedRequiredMatchFunc("provider", additionalMatchFn)
 
 	// Skip tests with [apigroup:GROUP] labels for apigroups which are not
 	// served by a cluster. E.g. MicroShift is not serving most of the openshift.io
@@ -135,7 +146,7 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 			if err != nil {
 				return nil, fmt.Errorf("unable to build api group filter: %w", err)
 			}
-			suite.AddRequiredMatchFunc(apiGroupFilter.includeTest)
+			suite.AddNamedRequiredMatchFunc("apigroup", apiGroupFilter.includeTest)
 		}
 	}
 
@@ -151,11 +162,21 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 		case apierrors.IsNotFound(err):
 			// In case we are unable to determine if there is support for feature gates, exclude all featuregated tests
 			// as the test target doesnt comply with preconditions.
-			suite.AddRequiredMatchFunc(includeNonFeatureGateTest)
+			suite.AddNamedRequiredMatchFunc("featuregate", includeNonFeatureGateTest)
 		case err != nil:
 			return nil, fmt.Errorf("unable to build FeatureGate filter: %w", err)
 		default:
-			suite.AddRequiredMatchFunc(featureGateFilter.includeTest)
+			suite.AddNamedRequiredMatchFunc("featuregate", featureGateFilter.includeTest)
+		}
+
+		capabilityFilter, err := newClusterCapabilityFilter(context.TODO(), configClient)
+		switch {
+		case err != nil && dryRun:
+			fmt.Fprintf(f.ErrOut, "Unable to read the cluster capabilities, skipping the capability check in the dry-run mode: %v\n", err)
+		case err != nil && !dryRun:
+			return nil, fmt.Errorf("unable to build the capability filter: %w", err)
+		default:
+			suite.AddNamedRequiredMatchFunc("capability", capabilityFilter.includeTest)
 		}
 	}
 
@@ -13,6 +13,36 @@ type UpgradeOptions struct {
 	Suite       string
 	ToImage     string
 	TestOptions []string
+	// Hops are the upgrades of a multi-hop upgrade, in order. When set, they replace ToImage.
+	Hops []UpgradeHop `json:",omitempty"`
+}
+
+// UpgradeHop is one upgrade of a multi-hop upgrade.
+type UpgradeHop struct {
+	ToImage string
+	// TestOptions are KEY=VALUE options of this hop only, overriding the TestOptions of the upgrade.
+	TestOptions []string `json:",omitempty"`
+}
+
+// ParseUpgradeHop parses a hop of the form IMAGE[,KEY=VALUE...].
+func ParseUpgradeHop(value string) (UpgradeHop, error) {
+	parts := strings.Split(value, ",")
+	if len(parts[0]) == 0 || strings.Contains(parts[0], "=") {
+		return UpgradeHop{}, fmt.Errorf("expected an upgrade hop of the form IMAGE[,KEY=VALUE...] instead of %q", value)
+	}
+	return UpgradeHop{ToImage: parts[0], TestOptions: parts[1:]}, nil
+}
+
+// TargetImages returns the images the upgrade goes through, in order.
+func (o *UpgradeOptions) TargetImages() []string {
+	if len(o.Hops) == 0 {
+		return strings.Split(o.ToImage, ",")
+	}
+	var images []string
+	for _, hop := range o.Hops {
+		images = append(images, hop.ToImage)
+	}
+	return images
 }
 
 func NewUpgradeOptionsFromYAML(yaml string) (*UpgradeOptions, error) {
@@ -47,26 +77,17 @@ func (o *UpgradeOptions) SetUpgradeGlobals() error {
 		return nil
 	}
 
-	for _, opt := range o.TestOptions {
-		parts := strings.SplitN(opt, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("expected option of the form KEY=VALUE instead of %q", opt)
-		}
-		switch parts[0] {
-		case "abort-at":
-			if err := upgrade.SetUpgradeAbortAt(parts[1]); err != nil {
-				return err
-			}
-		case "disrupt-reboot":
-			if err := upgrade.SetUpgradeDisruptReboot(parts[1]); err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("unrecognized upgrade option: %s", parts[0])
+	// the options of the upgrade are the defaults of the options of the hops, so they are set first
+	if err := setUpgradeOptions(0, o.TestOptions); err != nil {
+		return err
+	}
+	for i, hop := range o.Hops {
+		if err := setUpgradeOptions(i+1, hop.TestOptions); err != nil {
+			return fmt.Errorf("upgrade hop %d to %s: %w", i+1, hop.ToImage, err)
 		}
 	}
 
-	upgrade.SetToImage(o.ToImage)
+	upgrade.SetToImage(strings.Join(o.TargetImages(), ","))
 	switch o.Suite {
 	case "none":
 		return filterUpgrade(upgrade.NoTests(), func(string) bool { return true })
@@ -79,6 +100,19 @@ func (o *UpgradeOptions) SetUpgradeGlobals() error {
 	}
 }
 
+func setUpgradeOptions(hop int, options []string) error {
+	for _, opt := range options {
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("expected option of the form KEY=VALUE instead of %q", opt)
+		}
+		if err := upgrade.SetUpgradeOption(hop, parts[0], parts[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func filterUpgrade(tests []upgrades.Test, match func(string) bool) error {
 	var scope []upgrades.Test
 	for _, test := range tests {
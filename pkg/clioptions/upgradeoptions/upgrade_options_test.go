@@ -0,0 +1,65 @@
+package upgradeoptions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUpgradeHop(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  UpgradeHop
+		expectErr bool
+	}{
+		{value: "4.15.20", expected: UpgradeHop{ToImage: "4.15.20", TestOptions: []string{}}},
+		{value: "quay.io/openshift/release:4.16.3,pause-worker-pools=false,channel=eus-4.16", expected: UpgradeHop{ToImage: "quay.io/openshift/release:4.16.3", TestOptions: []string{"pause-worker-pools=false", "channel=eus-4.16"}}},
+		{value: "", expectErr: true},
+		{value: "abort-at=50", expectErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			hop, err := ParseUpgradeHop(test.value)
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", hop)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(hop, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, hop)
+			}
+		})
+	}
+}
+
+func TestUpgradeOptions_Hops(t *testing.T) {
+	o := &UpgradeOptions{
+		Suite:       "all",
+		TestOptions: []string{"abort-at=100"},
+		Hops: []UpgradeHop{
+			{ToImage: "4.15.20", TestOptions: []string{"pause-worker-pools=true"}},
+			{ToImage: "4.16.3", TestOptions: []string{"channel=eus-4.16"}},
+		},
+	}
+	parsed, err := NewUpgradeOptionsFromYAML(o.ToEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, o) {
+		t.Errorf("expected %#v, got %#v", o, parsed)
+	}
+	if images := parsed.TargetImages(); !reflect.DeepEqual(images, []string{"4.15.20", "4.16.3"}) {
+		t.Errorf("unexpected target images %v", images)
+	}
+	if err := parsed.SetUpgradeGlobals(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Hops[1].TestOptions = []string{"pause-worker-pools=maybe"}
+	if err := o.SetUpgradeGlobals(); err == nil {
+		t.Errorf("expected an invalid hop option to fail")
+	}
+}
@@ -0,0 +1,92 @@
+package explain_labels
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+)
+
+type ExplainLabelsOptions struct {
+	Query string
+	// Names are the test names to explain, read from In when empty.
+	Names []string
+
+	genericclioptions.IOStreams
+}
+
+func NewExplainLabelsOptions(streams genericclioptions.IOStreams) *ExplainLabelsOptions {
+	return &ExplainLabelsOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewExplainLabelsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewExplainLabelsOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "explain-labels QUERY [TEST_NAME...]",
+		Short: "Explain which terms of a label query include or exclude tests",
+		Long: templates.LongDesc(`
+		Explain which terms of a --label-query include or exclude tests
+
+		For every test name, print whether the query includes the test, the terms that
+		decided it, and the value of every part of the query. Without test names, the
+		names are read from standard input, one per line, quoted or not, so that the
+		output of run --dry-run can be piped in.
+
+		A query combines the bracketed labels of test names, without the brackets, with
+		&&, || and !, and parentheses, e.g.
+
+		  sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel)
+
+		A term ending in * matches every label starting with the part before it. Terms
+		containing spaces or operators are written with their brackets.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Query, o.Names = args[0], args[1:]
+			return o.Run()
+		},
+	}
+	return cmd
+}
+
+func (o *ExplainLabelsOptions) Run() error {
+	query, err := testginkgo.ParseLabelQuery(o.Query)
+	if err != nil {
+		return err
+	}
+
+	names := o.Names
+	if len(names) == 0 {
+		scanner := bufio.NewScanner(o.In)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if unquoted, err := strconv.Unquote(line); err == nil {
+				line = unquoted
+			}
+			if len(line) > 0 {
+				names = append(names, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(o.Out, "%s\n%s\n", name, query.Explain(name))
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package list
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
+	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
+	"github.com/openshift/origin/pkg/clioptions/suiteselection"
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/pkg/testsuites"
+	exutil "github.com/openshift/origin/test/extended/util"
+)
+
+type ListOptions struct {
+	Output             string
+	ProviderTypeOrJSON string
+	// All includes the tests the suite excludes.
+	All bool
+	// SuiteFile declares additional suites.
+	SuiteFile string
+	// ExternalBinaries replace the external test binaries of the release payload.
+	ExternalBinaries []string
+	// RebaseExclusions replaces the manifest of the tests excluded while a kube rebase is in progress.
+	RebaseExclusions string
+
+	TestSuiteSelectionFlags *suiteselection.TestSuiteSelectionFlags
+	AvailableSuites         []*testginkgo.TestSuite
+
+	genericclioptions.IOStreams
+}
+
+func NewListOptions(streams genericclioptions.IOStreams, availableSuites []*testginkgo.TestSuite) *ListOptions {
+	return &ListOptions{
+		Output:                  "json",
+		TestSuiteSelectionFlags: suiteselection.NewTestSuiteSelectionFlags(streams),
+		AvailableSuites:         availableSuites,
+		IOStreams:               streams,
+	}
+}
+
+func NewListCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewListOptions(streams, testsuites.StandardTestSuites())
+
+	cmd := &cobra.Command{
+		Use:   "list SUITE",
+		Short: "List the tests of a suite with their metadata",
+		Long: templates.LongDesc(`
+		List the tests of a suite with their metadata
+
+		Every test is listed with the labels parsed from its name, its code locations,
+		its timeout, the bucket of the suite it runs in and the external binary providing
+		it, if any. With --all, tests the suite excludes are listed as well, with the
+		filters that exclude them, and with the reason of the rebase exclusion excluding
+		them, if any.
+
+		Like run --dry-run, a kubeconfig is required. When the cluster cannot be reached,
+		the filters that depend on it (apigroup, featuregate and rebase) are not applied.
+		`) + testsuites.SuitesString(testsuites.StandardTestSuites(), "\n\nAvailable test suites:\n\n"),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run(cmd.Context(), args)
+		},
+	}
+	o.BindFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *ListOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "The output format: json, yaml or csv.")
+	flags.BoolVar(&o.All, "all", o.All, "Also list the tests the suite excludes.")
+	flags.StringVar(&o.SuiteFile, "suite-file", o.SuiteFile, "A YAML or JSON file declaring additional suites, see run --suite-file. If the file declares a single suite, it is listed when no suite is given.")
+	flags.StringArrayVar(&o.ExternalBinaries, "external-binary", o.ExternalBinaries, "NAME=PATH of an external test binary to list instead of the external binary of the release payload named NAME, e.g. k8s-tests, or in addition to them if the payload has none of that name. The release payload is not read when every required binary is given. PATH may be oci:DIR:PATH or oci-archive:FILE:PATH to use the binary at PATH in the image of an OCI layout directory or tarball. May be repeated.")
+	flags.StringVar(&o.RebaseExclusions, "rebase-exclusions", o.RebaseExclusions, "A YAML manifest of the tests excluded while a kube rebase is in progress to use instead of the manifest built into the binary, see run --rebase-exclusions.")
+	flags.StringVar(&o.ProviderTypeOrJSON, "provider", o.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
+	o.TestSuiteSelectionFlags.BindFlags(flags)
+}
+
+func (o *ListOptions) Validate() error {
+	switch o.Output {
+	case "json", "yaml", "csv":
+		return nil
+	default:
+		return fmt.Errorf("unsupported --output %q, expected json, yaml or csv", o.Output)
+	}
+}
+
+func (o *ListOptions) Run(ctx context.Context, args []string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	availableSuites := o.AvailableSuites
+	if len(o.SuiteFile) > 0 {
+		fileSuites, err := testsuites.LoadSuiteFile(o.SuiteFile, availableSuites)
+		if err != nil {
+			return err
+		}
+		availableSuites = append(append([]*testginkgo.TestSuite{}, availableSuites...), fileSuites...)
+		if len(args) == 0 && len(o.TestSuiteSelectionFlags.TestFile) == 0 && len(fileSuites) == 1 {
+			args = []string{fileSuites[0].Name}
+		}
+	}
+
+	providerConfig, err := clusterdiscovery.DecodeProvider(o.ProviderTypeOrJSON, true, true, nil)
+	if err != nil {
+		return err
+	}
+	if err := clusterdiscovery.InitializeTestFramework(exutil.TestContext, providerConfig, true); err != nil {
+		return err
+	}
+
+	var clusterVersion *testginkgo.ClusterVersion
+	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "Unable to get admin rest config, listing without the cluster: %v\n", err)
+		adminRESTConfig = &rest.Config{}
+	} else if clusterVersion, err = testginkgo.DiscoverClusterVersion(ctx, adminRESTConfig); err != nil {
+		fmt.Fprintf(o.ErrOut, "Unable to get the cluster version, skipping the rebase exclusions: %v\n", err)
+	}
+
+	suite, err := o.TestSuiteSelectionFlags.SelectSuite(
+		availableSuites,
+		args,
+		kubeconfig.NewDiscoveryGetter(adminRESTConfig),
+		kubeconfig.NewConfigClientGetter(adminRESTConfig),
+		true,
+		providerConfig.MatchFn(),
+	)
+	if err != nil {
+		return err
+	}
+
+	tests, err := testginkgo.ListTests(ctx, suite, clusterVersion, o.RebaseExclusions, o.ExternalBinaries, o.ErrOut)
+	if err != nil {
+		return err
+	}
+	if !o.All {
+		included := make([]testginkgo.TestMetadata, 0, len(tests))
+		for _, test := range tests {
+			if test.Included {
+				included = append(included, test)
+			}
+		}
+		tests = included
+	}
+	return printTests(o.Out, o.Output, tests)
+}
+
+func printTests(out io.Writer, format string, tests []testginkgo.TestMetadata) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(tests, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(tests)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	case "csv":
+		w := csv.NewWriter(out)
+		w.Write([]string{"name", "included", "excludedBy", "rebaseExclusionReason", "bucket", "timeout", "external", "binary", "sigs", "suites", "features", "featureGates", "apiGroups", "skipped", "serial", "otherLabels", "locations"})
+		for _, test := range tests {
+			w.Write([]string{
+				test.Name,
+				strconv.FormatBool(test.Included),
+				strings.Join(test.ExcludedBy, ";"),
+				test.RebaseExclusionReason,
+				test.Bucket,
+				test.Timeout,
+				strconv.FormatBool(test.External),
+				test.Binary,
+				strings.Join(test.Labels.Sigs, ";"),
+				strings.Join(test.Labels.Suites, ";"),
+				strings.Join(test.Labels.Features, ";"),
+				strings.Join(test.Labels.FeatureGates, ";"),
+				strings.Join(test.Labels.APIGroups, ";"),
+				strings.Join(test.Labels.Skipped, ";"),
+				strconv.FormatBool(test.Labels.Serial),
+				strings.Join(test.Labels.Other, ";"),
+				strings.Join(test.Locations, ";"),
+			})
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
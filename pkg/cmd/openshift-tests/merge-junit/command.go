@@ -0,0 +1,130 @@
+package merge_junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+type MergeJUnitOptions struct {
+	// Inputs are JUnit files or directories containing junit_e2e*.xml files.
+	Inputs []string
+	Output string
+	Name   string
+
+	genericclioptions.IOStreams
+}
+
+func NewMergeJUnitOptions(streams genericclioptions.IOStreams) *MergeJUnitOptions {
+	return &MergeJUnitOptions{
+		Name:      "openshift-tests",
+		IOStreams: streams,
+	}
+}
+
+func NewMergeJUnitCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewMergeJUnitOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "merge-junit --output=FILE FILE_OR_DIR...",
+		Short: "Merge the JUnit reports of a sharded run into one",
+		Long: templates.LongDesc(`
+		Merge the JUnit reports of a suite that was split with run --shard into a single
+		test suite, including the report of the serial shard.
+
+		Each argument is either a JUnit XML file or a directory, in which case every
+		junit_e2e*.xml file in the directory is read. Test cases are kept as reported, so
+		tests that failed and passed on retry are still reported as flakes.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Inputs = args
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+	o.BindFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *MergeJUnitOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "The file to write the merged JUnit report to.")
+	flags.StringVar(&o.Name, "name", o.Name, "The name of the merged test suite.")
+}
+
+func (o *MergeJUnitOptions) Validate() error {
+	if len(o.Inputs) == 0 {
+		return fmt.Errorf("at least one JUnit file or directory is required")
+	}
+	if len(o.Output) == 0 {
+		return fmt.Errorf("missing --output")
+	}
+	return nil
+}
+
+func (o *MergeJUnitOptions) Run() error {
+	var files []string
+	for _, input := range o.Inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, input)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(input, "junit_e2e*.xml"))
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no JUnit reports found in %s", strings.Join(o.Inputs, ", "))
+	}
+
+	var suites []*junitapi.JUnitTestSuite
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fileSuites, err := testginkgo.ReadJUnitTestSuites(data)
+		if err != nil {
+			return fmt.Errorf("unable to read JUnit report %s: %w", file, err)
+		}
+		fmt.Fprintf(o.Out, "Read %s\n", file)
+		suites = append(suites, fileSuites...)
+	}
+
+	merged := testginkgo.MergeJUnitTestSuites(o.Name, suites...)
+	flaky, failing := testginkgo.FlakyJUnitTestCaseNames(merged)
+	if len(flaky) > 0 {
+		fmt.Fprintf(o.Out, "Flaky tests:\n\n%s\n\n", strings.Join(flaky, "\n"))
+	}
+	if len(failing) > 0 {
+		fmt.Fprintf(o.Out, "Failing tests:\n\n%s\n\n", strings.Join(failing, "\n"))
+	}
+	fmt.Fprintf(o.Out, "%d tests, %d failures (%d flaky, %d failing), %d skipped\n", merged.NumTests, merged.NumFailed, len(flaky), len(failing), merged.NumSkipped)
+
+	out, err := xml.MarshalIndent(merged, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.ErrOut, "Writing JUnit report to %s\n", o.Output)
+	return os.WriteFile(o.Output, out, 0640)
+}
@@ -0,0 +1,179 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/origin/pkg/defaultmonitortests"
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitortestframework"
+)
+
+type ReplayFlags struct {
+	ArtifactDir         string
+	OutputDir           string
+	JUnitSuiteName      string
+	ClusterStability    string
+	ExactMonitorTests   []string
+	DisableMonitorTests []string
+
+	genericclioptions.IOStreams
+}
+
+func NewReplayFlags(streams genericclioptions.IOStreams) *ReplayFlags {
+	return &ReplayFlags{
+		JUnitSuiteName:   "openshift-tests",
+		ClusterStability: string(monitortestframework.Stable),
+		IOStreams:        streams,
+	}
+}
+
+func NewReplayCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewReplayFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Run the monitor tests against the artifacts of a finished job run",
+		Long: templates.LongDesc(`
+		Run the monitor tests against the artifacts of a finished job run, without a cluster
+
+		Every run stored below --artifacts, one per e2e-events_<timestamp>.json file, is replayed with the
+		resource-<type>_<timestamp>.zip files and the e2e-monitor-tests_<timestamp>.xml junit next to it: the
+		monitor tests able to collect their data from the artifacts collect it, then every monitor test computes
+		its intervals and evaluates its tests.  The junit, e2e-monitor-tests_<timestamp>.xml, and the content
+		the monitor tests store are written to --output-dir for every run.
+
+		Only audit-log-analyzer collects its data again, from the gathered audit logs.  The setup and collection
+		junits of the other monitor tests are those of the stored run, and their tests are evaluated from the
+		intervals they collected when the job ran.  The intervals computed when the job ran are dropped and
+		computed again, of the intervals collected again only those that differ are added.  The phases of
+		monitor tests that fail without the collection they did not run again are reported as skipped.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run(cmd.Context())
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *ReplayFlags) BindFlags(flags *pflag.FlagSet) {
+	monitorNames := defaultmonitortests.ListAllMonitorTests()
+
+	flags.StringVar(&f.ArtifactDir, "artifacts", f.ArtifactDir, "The directory holding the artifacts of the job run to replay.")
+	flags.StringVar(&f.OutputDir, "output-dir", f.OutputDir, "The directory where the junit and the content of the monitor tests are written.  Defaults to a new temporary directory.")
+	flags.StringVar(&f.JUnitSuiteName, "junit-suite", f.JUnitSuiteName, "The name of the junit suite of the monitor tests.")
+	flags.StringVar(&f.ClusterStability, "cluster-stability", f.ClusterStability,
+		fmt.Sprintf("The stability of the cluster during the job run, which selects the monitor tests: [%s, %s]", monitortestframework.Stable, monitortestframework.Disruptive))
+	flags.StringSliceVar(&f.ExactMonitorTests, "monitor", f.ExactMonitorTests,
+		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
+	flags.StringSliceVar(&f.DisableMonitorTests, "disable-monitor", f.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+}
+
+func (f *ReplayFlags) ToOptions() (*ReplayOptions, error) {
+	if len(f.ArtifactDir) == 0 {
+		return nil, fmt.Errorf("missing --artifacts")
+	}
+	clusterStability := monitortestframework.ClusterStabilityDuringTest(f.ClusterStability)
+	switch clusterStability {
+	case monitortestframework.Stable, monitortestframework.Disruptive:
+	default:
+		return nil, fmt.Errorf("unknown --cluster-stability %q", f.ClusterStability)
+	}
+
+	outputDir := f.OutputDir
+	if len(outputDir) == 0 {
+		var err error
+		if outputDir, err = os.MkdirTemp("", "monitor-replay-"); err != nil {
+			return nil, err
+		}
+	} else if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &ReplayOptions{
+		ArtifactDir:    f.ArtifactDir,
+		OutputDir:      outputDir,
+		JUnitSuiteName: f.JUnitSuiteName,
+		MonitorTestInfo: monitortestframework.MonitorTestInitializationInfo{
+			ClusterStabilityDuringTest: clusterStability,
+			ExactMonitorTests:          f.ExactMonitorTests,
+			DisableMonitorTests:        f.DisableMonitorTests,
+		},
+		IOStreams: f.IOStreams,
+	}, nil
+}
+
+type ReplayOptions struct {
+	ArtifactDir     string
+	OutputDir       string
+	JUnitSuiteName  string
+	MonitorTestInfo monitortestframework.MonitorTestInitializationInfo
+
+	genericclioptions.IOStreams
+}
+
+// Run replays every run stored in the artifacts and fails if the monitor tests of any of them failed.
+func (o *ReplayOptions) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	runs, err := monitor.FindReplayRuns(o.ArtifactDir)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no e2e-events file found in %s", o.ArtifactDir)
+	}
+
+	failedRuns := []string{}
+	for _, run := range runs {
+		fmt.Fprintf(o.Out, "Replaying the run%s from %s: %d intervals, %d resource types\n", run.TimeSuffix, run.Dir, len(run.Intervals), len(run.Resources))
+
+		// the monitor tests keep the data of the run they collect, every run needs its own
+		monitorTests, err := defaultmonitortests.NewMonitorTestsFor(o.MonitorTestInfo)
+		if err != nil {
+			return err
+		}
+		m, err := monitor.NewReplayMonitor(o.ArtifactDir, o.OutputDir, run, monitorTests)
+		if err != nil {
+			return err
+		}
+		if err := m.Start(ctx); err != nil {
+			return err
+		}
+		resultState, err := m.Stop(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.SerializeResults(ctx, o.JUnitSuiteName, run.TimeSuffix); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Monitor tests of the run%s: %s\n", run.TimeSuffix, resultState)
+		if resultState != monitor.Succeeded {
+			failedRuns = append(failedRuns, run.TimeSuffix)
+		}
+	}
+
+	fmt.Fprintf(o.Out, "Results written to %s\n", o.OutputDir)
+	if len(failedRuns) > 0 {
+		return fmt.Errorf("monitor tests failed for the runs %s", strings.Join(failedRuns, ", "))
+	}
+	return nil
+}
@@ -34,6 +34,15 @@ func NewRunUpgradeCommand(streams genericclioptions.IOStreams) *cobra.Command {
 		* disrupt-reboot=POLICY - During upgrades, periodically reboot master nodes. If set to 'graceful'
 		the reboot will allow the node to shut down services in an orderly fashion. If set to 'force' the
 		machine will terminate immediately without clean shutdown.
+		* channel=CHANNEL - Set the channel of the cluster version along with the desired update.
+		* pause-worker-pools=BOOL - Pause the machine config pools other than master during the
+		upgrade, so that only the control plane is updated. A later hop that does not pause them
+		unpauses the pools.
+
+		A multi-hop upgrade goes through several versions in order, e.g. from one EUS release to the
+		next. Specify each hop with --upgrade-hop=IMAGE[,KEY=VALUE...], where the options apply to
+		that hop only and override --options. The tests and cluster events of each hop are tagged
+		with it, so that disruption can be attributed to the hop it happened in.
 
 		`) + testsuites.SuitesString(testsuites.UpgradeTestSuites(), "\n\nAvailable upgrade suites:\n\n"),
 
@@ -6,6 +6,7 @@ import (
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
 	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
 	"github.com/openshift/origin/pkg/clioptions/suiteselection"
+	"github.com/openshift/origin/pkg/clioptions/upgradeoptions"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -25,6 +26,7 @@ type RunUpgradeSuiteFlags struct {
 	UpgradeSuite string
 	ToImage      string
 	TestOptions  []string
+	UpgradeHops  []string
 
 	// Shared by initialization code
 	config *clusterdiscovery.ClusterConfiguration
@@ -49,6 +51,7 @@ func (f *RunUpgradeSuiteFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&f.ProviderTypeOrJSON, "provider", f.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
 	flags.StringVar(&f.ToImage, "to-image", f.ToImage, "Specify the image to test an upgrade to.")
 	flags.StringSliceVar(&f.TestOptions, "options", f.TestOptions, "A set of KEY=VALUE options to control the test. See the help text.")
+	flags.StringArrayVar(&f.UpgradeHops, "upgrade-hop", f.UpgradeHops, "An upgrade of a multi-hop upgrade, of the form IMAGE[,KEY=VALUE...] with options of this hop only. May be repeated instead of --to-image, in the order of the hops.")
 	f.GinkgoRunSuiteOptions.BindFlags(flags)
 	f.TestSuiteSelectionFlags.BindFlags(flags)
 	f.OutputFlags.BindFlags(flags)
@@ -76,8 +79,19 @@ func (f *RunUpgradeSuiteFlags) ToOptions(args []string) (*RunUpgradeSuiteOptions
 	// and when the CVO hangs.
 	ginkgoOptions.IncludeSuccessOutput = true
 
-	if len(f.ToImage) == 0 {
-		return nil, fmt.Errorf("--to-image must be specified to run an upgrade test")
+	var hops []upgradeoptions.UpgradeHop
+	for _, value := range f.UpgradeHops {
+		hop, err := upgradeoptions.ParseUpgradeHop(value)
+		if err != nil {
+			return nil, err
+		}
+		hops = append(hops, hop)
+	}
+	switch {
+	case len(hops) > 0 && len(f.ToImage) > 0:
+		return nil, fmt.Errorf("--to-image and --upgrade-hop may not be combined")
+	case len(hops) == 0 && len(f.ToImage) == 0:
+		return nil, fmt.Errorf("--to-image or --upgrade-hop must be specified to run an upgrade test")
 	}
 
 	suite, err := f.TestSuiteSelectionFlags.SelectSuite(
@@ -98,6 +112,7 @@ func (f *RunUpgradeSuiteFlags) ToOptions(args []string) (*RunUpgradeSuiteOptions
 		ToImage:               f.ToImage,
 		FromRepository:        f.FromRepository,
 		TestOptions:           f.TestOptions,
+		Hops:                  hops,
 		CloseFn:               closeFn,
 		IOStreams:             f.IOStreams,
 	}
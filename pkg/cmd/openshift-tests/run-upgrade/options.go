@@ -32,6 +32,8 @@ type RunUpgradeSuiteOptions struct {
 	// CloudProviderJSON string
 
 	TestOptions []string
+	// Hops are the upgrades of a multi-hop upgrade, when ToImage is not set.
+	Hops []upgradeoptions.UpgradeHop
 
 	CloseFn iooptions.CloseFunc
 
@@ -55,6 +57,7 @@ func (o *RunUpgradeSuiteOptions) TestCommandEnvironment() []string {
 		Suite:       o.Suite.Name,
 		ToImage:     o.ToImage,
 		TestOptions: o.TestOptions,
+		Hops:        o.Hops,
 	}
 	args = append(args, fmt.Sprintf("TEST_UPGRADE_OPTIONS=%s", upgradeOptions.ToEnv()))
 
@@ -105,10 +108,13 @@ func (o *RunUpgradeSuiteOptions) Run(ctx context.Context) error {
 		return err
 	}
 
+	upgradeOptions := upgradeoptions.UpgradeOptions{ToImage: o.ToImage, Hops: o.Hops}
+	targetImages := upgradeOptions.TargetImages()
+
 	// TODO the gingkoRunSuiteOptions needs to have flags then calculated options to express specified versus computed values
 	monitorTestInfo := monitortestframework.MonitorTestInitializationInfo{
 		ClusterStabilityDuringTest:        monitortestframework.Stable,
-		UpgradeTargetPayloadImagePullSpec: o.ToImage,
+		UpgradeTargetPayloadImagePullSpec: targetImages[len(targetImages)-1],
 		ExactMonitorTests:                 o.GinkgoRunSuiteOptions.ExactMonitorTests,
 		DisableMonitorTests:               o.GinkgoRunSuiteOptions.DisableMonitorTests,
 	}
@@ -2,11 +2,14 @@ package run
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
 	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
 	"github.com/openshift/origin/pkg/clioptions/suiteselection"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/pkg/testsuites"
 	exutil "github.com/openshift/origin/test/extended/util"
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -22,6 +25,8 @@ type RunSuiteFlags struct {
 
 	FromRepository     string
 	ProviderTypeOrJSON string
+	QuarantineFile     string
+	SuiteFile          string
 
 	// Passed to the test process if set
 	UpgradeSuite string
@@ -65,6 +70,8 @@ func (f *RunSuiteFlags) SuiteWithKubeTestInitializationPreSuite() (*clusterdisco
 func (f *RunSuiteFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
 	flags.StringVar(&f.ProviderTypeOrJSON, "provider", f.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
+	flags.StringVar(&f.QuarantineFile, "quarantine-file", f.QuarantineFile, "A YAML or JSON file of known flaky tests, each with a name regex, owning component, bug and expiry date. Quarantined tests still run, but their failures are reported as flakes. Expired entries fail the run.")
+	flags.StringVar(&f.SuiteFile, "suite-file", f.SuiteFile, "A YAML or JSON file declaring additional suites by name, description, include and exclude label queries, parallelism, test timeout, allowed flakes and cluster stability. If the file declares a single suite, it is run when no suite is given.")
 	f.GinkgoRunSuiteOptions.BindFlags(flags)
 	f.TestSuiteSelectionFlags.BindFlags(flags)
 	f.OutputFlags.BindFlags(flags)
@@ -76,6 +83,18 @@ func (f *RunSuiteFlags) SetIOStreams(streams genericclioptions.IOStreams) {
 }
 
 func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
+	availableSuites := f.AvailableSuites
+	if len(f.SuiteFile) > 0 {
+		fileSuites, err := testsuites.LoadSuiteFile(f.SuiteFile, availableSuites)
+		if err != nil {
+			return nil, err
+		}
+		availableSuites = append(append([]*testginkgo.TestSuite{}, availableSuites...), fileSuites...)
+		if len(args) == 0 && len(f.TestSuiteSelectionFlags.TestFile) == 0 && len(fileSuites) == 1 {
+			args = []string{fileSuites[0].Name}
+		}
+	}
+
 	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
 	switch {
 	case err != nil && f.GinkgoRunSuiteOptions.DryRun:
@@ -98,7 +117,7 @@ func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
 		return nil, err
 	}
 	suite, err := f.TestSuiteSelectionFlags.SelectSuite(
-		f.AvailableSuites,
+		availableSuites,
 		args,
 		kubeconfig.NewDiscoveryGetter(adminRESTConfig),
 		kubeconfig.NewConfigClientGetter(adminRESTConfig),
@@ -108,6 +127,12 @@ func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(f.QuarantineFile) > 0 {
+		suite.Quarantined, err = testsuites.LoadQuarantineFile(f.QuarantineFile, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	o := &RunSuiteOptions{
 		GinkgoRunSuiteOptions: ginkgoOptions,
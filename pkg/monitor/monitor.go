@@ -31,6 +31,11 @@ type Monitor struct {
 
 	recorder monitorapi.Recorder
 	junits   []*junitapi.JUnitTestCase
+	// replay is set when the monitor replays a finished run instead of monitoring a cluster.
+	replay *replayArtifacts
+	// resumedFrom is the start of the intervals of the interrupted run this run resumes, which are serialized with
+	// the intervals of this run.
+	resumedFrom time.Time
 
 	lock      sync.Mutex
 	stopFn    context.CancelFunc
@@ -52,6 +57,23 @@ func NewMonitor(
 	}
 }
 
+// NewResumedMonitor creates a monitor for a run resuming an interrupted one, whose intervals recorded from
+// resumedFrom on are serialized with the intervals of this run.
+func NewResumedMonitor(
+	recorder monitorapi.Recorder,
+	adminKubeConfig *rest.Config,
+	storageDir string,
+	monitorTestRegistry monitortestframework.MonitorTestRegistry,
+	resumedFrom time.Time) Interface {
+	return &Monitor{
+		adminKubeConfig:     adminKubeConfig,
+		recorder:            recorder,
+		monitorTestRegistry: monitorTestRegistry,
+		storageDir:          storageDir,
+		resumedFrom:         resumedFrom,
+	}
+}
+
 var _ Interface = &Monitor{}
 
 // Start begins monitoring the cluster referenced by the default kube configuration until context is finished.
@@ -64,6 +86,12 @@ func (m *Monitor) Start(ctx context.Context) error {
 	ctx, m.stopFn = context.WithCancel(ctx)
 	m.startTime = time.Now()
 
+	if m.replay != nil {
+		m.startTime = m.replay.beginning
+		fmt.Printf("Replaying monitor tests from %s.\n", m.replay.artifactDir)
+		return nil
+	}
+
 	localJunits, err := m.monitorTestRegistry.StartCollection(ctx, m.adminKubeConfig, m.recorder)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting data collection, continuing, junit will reflect this. %v\n", err)
@@ -86,31 +114,44 @@ func (m *Monitor) Stop(ctx context.Context) (ResultState, error) {
 	m.stopFn = nil
 
 	preStopTime := time.Now()
+	if m.replay != nil {
+		preStopTime = m.replay.end
+	}
 
 	fmt.Fprintf(os.Stderr, "Collecting data.\n")
-	collectedIntervals, collectionJunits, err := m.monitorTestRegistry.CollectData(ctx, m.storageDir, m.startTime, preStopTime)
+	var collectedIntervals monitorapi.Intervals
+	var collectionJunits []*junitapi.JUnitTestCase
+	var err error
+	if m.replay != nil {
+		collectedIntervals, collectionJunits, err = m.monitorTestRegistry.CollectDataFromArtifacts(ctx, m.replay.artifactDir, m.replay.junits, m.startTime, preStopTime)
+	} else {
+		collectedIntervals, collectionJunits, err = m.monitorTestRegistry.CollectData(ctx, m.storageDir, m.startTime, preStopTime)
+	}
 	if err != nil {
 		// these errors are represented as junit, always continue to the next step
 		fmt.Fprintf(os.Stderr, "Error collecting data, continuing, junit will reflect this. %v\n", err)
 	}
-	m.recorder.AddIntervals(collectedIntervals...)
+	m.addIntervals(collectedIntervals)
 	m.junits = append(m.junits, collectionJunits...)
 
 	// set the stop time for after we finished.
 	m.stopTime = time.Now()
+	if m.replay != nil {
+		m.stopTime = m.replay.end
+	}
 
 	fmt.Fprintf(os.Stderr, "Computing intervals.\n")
 	computedIntervals, computedJunit, err := m.monitorTestRegistry.ConstructComputedIntervals(
 		ctx,
 		m.recorder.Intervals(time.Time{}, time.Time{}), // compute intervals based on *all* the intervals.
-		m.recorder.CurrentResourceState(),
+		m.currentResourceState(),
 		m.startTime, // still allow computation to understand the beginning and end for bounding.
 		m.stopTime)  // still allow computation to understand the beginning and end for bounding.
 	if err != nil {
 		// these errors are represented as junit, always continue to the next step
 		fmt.Fprintf(os.Stderr, "Error computing intervals, continuing, junit will reflect this. %v\n", err)
 	}
-	m.recorder.AddIntervals(computedIntervals...)
+	m.addIntervals(computedIntervals)
 	m.junits = append(m.junits, computedJunit...)
 
 	fmt.Fprintf(os.Stderr, "Evaluating tests.\n")
@@ -167,9 +208,14 @@ func (m *Monitor) SerializeResults(ctx context.Context, junitSuiteName, timeSuff
 	// tests that check intervals for the e2e phase will not see intervals during upgrade
 	// phase and vice versa).  If it turns out visibility throughout the entire run yields
 	// useful testing, we can comeback and tweak this accordingly.
-	finalIntervals := m.recorder.Intervals(m.startTime, m.stopTime)
+	// The intervals of the run a resumed run resumes are part of its phase.
+	intervalsFrom := m.startTime
+	if !m.resumedFrom.IsZero() && m.resumedFrom.Before(intervalsFrom) {
+		intervalsFrom = m.resumedFrom
+	}
+	finalIntervals := m.recorder.Intervals(intervalsFrom, m.stopTime)
 
-	finalResources := m.recorder.CurrentResourceState()
+	finalResources := m.currentResourceState()
 	// TODO stop taking timesuffix as an arg and make this authoritative.
 	// timeSuffix := fmt.Sprintf("_%s", time.Now().UTC().Format("20060102-150405"))
 
@@ -181,6 +227,9 @@ func (m *Monitor) SerializeResults(ctx context.Context, junitSuiteName, timeSuff
 
 	fmt.Fprintf(os.Stderr, "Writing to storage.\n")
 	fmt.Fprintf(os.Stderr, "  m.startTime = %s\n", m.startTime)
+	if !m.resumedFrom.IsZero() {
+		fmt.Fprintf(os.Stderr, "  m.resumedFrom = %s\n", m.resumedFrom)
+	}
 	fmt.Fprintf(os.Stderr, "  m.stopTime  = %s\n", m.stopTime)
 
 	monitorTestJunits, err := m.monitorTestRegistry.WriteContentToStorage(
@@ -196,6 +245,11 @@ func (m *Monitor) SerializeResults(ctx context.Context, junitSuiteName, timeSuff
 	}
 	m.junits = append(m.junits, monitorTestJunits...)
 
+	// the monitor tests were cleaned up when stopping
+	if err := m.monitorTestRegistry.WriteUsage(m.storageDir, timeSuffix); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the usage of the monitor tests, err: %v\n", err)
+	}
+
 	fmt.Fprintf(os.Stderr, "Writing junits.\n")
 	var junitSuite *junitapi.JUnitTestSuite
 	if junitSuite, err = m.serializeJunit(ctx, m.storageDir, junitSuiteName, timeSuffix); err != nil {
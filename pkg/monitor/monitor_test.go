@@ -1,12 +1,16 @@
 package monitor
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/client-go/rest"
 )
 
 func TestMonitor_Newlines(t *testing.T) {
@@ -114,3 +118,78 @@ func TestMonitor_Events(t *testing.T) {
 		})
 	}
 }
+
+// storedMonitorTest records the intervals it writes to storage.
+type storedMonitorTest struct {
+	stored monitorapi.Intervals
+}
+
+func (t *storedMonitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	return nil
+}
+
+func (t *storedMonitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return nil, nil, nil
+}
+
+func (t *storedMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (t *storedMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, nil
+}
+
+func (t *storedMonitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	t.stored = finalIntervals
+	return nil
+}
+
+func (t *storedMonitorTest) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+func TestResumedMonitor_SerializeResults(t *testing.T) {
+	resumedFrom := time.Now().Add(-time.Hour)
+	interval := func(message string, at time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("node")).
+			Message(monitorapi.NewMessage().HumanMessage(message)).
+			Build(at, at)
+	}
+
+	for _, resumed := range []bool{false, true} {
+		stored := &storedMonitorTest{}
+		registry := monitortestframework.NewMonitorTestRegistry()
+		registry.AddMonitorTestOrDie("stored", "Test Framework", stored)
+		recorder := NewRecorder()
+		recorder.AddIntervals(interval("before the interrupted run", resumedFrom.Add(-time.Minute)), interval("interrupted run", resumedFrom))
+
+		m := NewMonitor(recorder, nil, t.TempDir(), registry)
+		if resumed {
+			m = NewResumedMonitor(recorder, nil, t.TempDir(), registry, resumedFrom)
+		}
+		if err := m.Start(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+		recorder.AddIntervals(interval("this run", time.Now()))
+		if _, err := m.Stop(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.SerializeResults(context.TODO(), "openshift-tests", "_suffix"); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"this run"}
+		if resumed {
+			expected = []string{"interrupted run", "this run"}
+		}
+		got := []string{}
+		for _, interval := range stored.stored {
+			got = append(got, interval.Message.HumanMessage)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("resumed=%v: expected %q to be stored, got %q", resumed, expected, got)
+		}
+	}
+}
@@ -448,6 +448,13 @@ func (b *LocatorBuilder) E2ETest(testName string) Locator {
 	return b.Build()
 }
 
+// TestRunner locates a component of the test runner, e.g. parallelism.
+func (b *LocatorBuilder) TestRunner(component string) Locator {
+	b.targetType = LocatorTypeTestRunner
+	b.annotations[LocatorTestRunnerKey] = component
+	return b.Build()
+}
+
 func (b *LocatorBuilder) ClusterOperator(name string) Locator {
 	b.targetType = LocatorTypeClusterOperator
 	b.annotations[LocatorClusterOperatorKey] = name
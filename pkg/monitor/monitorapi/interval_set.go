@@ -0,0 +1,417 @@
+package monitorapi
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// IntervalSet is an immutable, sorted set of intervals indexed by locator type, source, reason, locator key and
+// time, for monitor tests that look up the final intervals many times.  Building a set costs about as much as
+// sorting the intervals, so it pays off from the second lookup on.
+type IntervalSet struct {
+	intervals Intervals
+
+	byLocatorType map[LocatorType][]int
+	bySource      map[IntervalSource][]int
+	byReason      map[IntervalReason][]int
+	byLocatorKey  map[LocatorKey]map[string][]int
+
+	// ends is an interval tree over the sorted intervals: the interval in the middle of every range of intervals
+	// the tree is searched in holds the latest end of the range, see overlapping.
+	ends []subtreeEnd
+}
+
+type subtreeEnd struct {
+	to time.Time
+	// open is set if an interval of the subtree has no end.
+	open bool
+}
+
+func (e subtreeEnd) endsBefore(from time.Time) bool {
+	return !e.open && e.to.Before(from)
+}
+
+func (e subtreeEnd) merge(other subtreeEnd) subtreeEnd {
+	if other.open || other.to.After(e.to) {
+		e.to = other.to
+	}
+	e.open = e.open || other.open
+	return e
+}
+
+// NewIntervalSet indexes a sorted copy of the intervals.
+func NewIntervalSet(intervals Intervals) *IntervalSet {
+	s := &IntervalSet{
+		intervals:     append(Intervals{}, intervals...),
+		byLocatorType: map[LocatorType][]int{},
+		bySource:      map[IntervalSource][]int{},
+		byReason:      map[IntervalReason][]int{},
+		byLocatorKey:  map[LocatorKey]map[string][]int{},
+		ends:          make([]subtreeEnd, len(intervals)),
+	}
+	sort.Sort(s.intervals)
+
+	for i, interval := range s.intervals {
+		s.byLocatorType[interval.Locator.Type] = append(s.byLocatorType[interval.Locator.Type], i)
+		s.bySource[interval.Source] = append(s.bySource[interval.Source], i)
+		s.byReason[interval.Message.Reason] = append(s.byReason[interval.Message.Reason], i)
+		for key, value := range interval.Locator.Keys {
+			values, ok := s.byLocatorKey[key]
+			if !ok {
+				values = map[string][]int{}
+				s.byLocatorKey[key] = values
+			}
+			values[value] = append(values[value], i)
+		}
+	}
+	s.indexEnds(0, len(s.intervals))
+	return s
+}
+
+func (s *IntervalSet) indexEnds(lo, hi int) subtreeEnd {
+	if lo >= hi {
+		return subtreeEnd{}
+	}
+	mid := int(uint(lo+hi) >> 1)
+	end := subtreeEnd{to: s.intervals[mid].To, open: s.intervals[mid].To.IsZero()}
+	end = end.merge(s.indexEnds(lo, mid)).merge(s.indexEnds(mid+1, hi))
+	s.ends[mid] = end
+	return end
+}
+
+// Len returns the number of intervals in the set.
+func (s *IntervalSet) Len() int {
+	return len(s.intervals)
+}
+
+// Intervals returns a copy of the sorted intervals of the set.
+func (s *IntervalSet) Intervals() Intervals {
+	return append(Intervals{}, s.intervals...)
+}
+
+// Query returns a copy of the intervals matching the query, in the order of the set.  Only the intervals found by the
+// indexes the query can use are matched against it.
+func (s *IntervalSet) Query(query IntervalQuery) Intervals {
+	candidates, indexed := query.candidates(s)
+	matched := Intervals{}
+	if !indexed {
+		for _, interval := range s.intervals {
+			if query.Matches(interval) {
+				matched = append(matched, interval)
+			}
+		}
+		return matched
+	}
+	for _, i := range candidates {
+		if query.Matches(s.intervals[i]) {
+			matched = append(matched, s.intervals[i])
+		}
+	}
+	return matched
+}
+
+// overlapping returns the indexes of the intervals overlapping the range, in order.  Intervals are sorted by From,
+// so the search stops at the first interval starting after to, and skips the ranges of intervals that all end
+// before from.
+func (s *IntervalSet) overlapping(from, to time.Time) []int {
+	found := []int{}
+	var search func(lo, hi int)
+	search = func(lo, hi int) {
+		if lo >= hi {
+			return
+		}
+		mid := int(uint(lo+hi) >> 1)
+		if !from.IsZero() && s.ends[mid].endsBefore(from) {
+			return
+		}
+		search(lo, mid)
+		if !to.IsZero() && s.intervals[mid].From.After(to) {
+			return
+		}
+		if overlapsRange(s.intervals[mid], from, to) {
+			found = append(found, mid)
+		}
+		search(mid+1, hi)
+	}
+	search(0, len(s.intervals))
+	return found
+}
+
+// IntervalQuery matches intervals like an EventIntervalMatchesFunc, and also knows which indexes of an IntervalSet
+// find the intervals it may match.
+type IntervalQuery interface {
+	// Matches returns true if the interval matches the query.
+	Matches(interval Interval) bool
+	// MatchesFunc returns the query as an EventIntervalMatchesFunc, to filter Intervals.
+	MatchesFunc() EventIntervalMatchesFunc
+
+	// candidates returns the sorted indexes of the intervals of the set the query may match, or false if the query
+	// cannot use the indexes of the set and every interval must be matched.
+	candidates(s *IntervalSet) ([]int, bool)
+}
+
+type intervalQuery struct {
+	matches EventIntervalMatchesFunc
+	lookup  func(s *IntervalSet) ([]int, bool)
+}
+
+func (q intervalQuery) Matches(interval Interval) bool {
+	return q.matches(interval)
+}
+
+func (q intervalQuery) MatchesFunc() EventIntervalMatchesFunc {
+	return q.matches
+}
+
+func (q intervalQuery) candidates(s *IntervalSet) ([]int, bool) {
+	return q.lookup(s)
+}
+
+// unindexed is the candidates of queries that cannot use the indexes.
+func unindexed(*IntervalSet) ([]int, bool) {
+	return nil, false
+}
+
+// QueryFunc matches the intervals the function matches, without using the indexes: every interval of the set is
+// matched, unless the query is in a QueryAnd with a query using them.  Prefer the indexed queries below for the
+// EventIntervalMatchesFunc they replace.
+func QueryFunc(matches EventIntervalMatchesFunc) IntervalQuery {
+	return intervalQuery{matches: matches, lookup: unindexed}
+}
+
+// QueryDisruptionEvent matches like IsDisruptionEvent.
+func QueryDisruptionEvent() IntervalQuery {
+	return QuerySource(SourceDisruption)
+}
+
+// QueryForDisruptionBackend matches like IsForDisruptionBackend, for a backend that is not empty.
+func QueryForDisruptionBackend(backend string) IntervalQuery {
+	return QueryLocatorKey(LocatorBackendDisruptionNameKey, backend)
+}
+
+// QueryInNamespaces matches like IsInNamespaces.
+func QueryInNamespaces(namespaces sets.String) IntervalQuery {
+	return QueryLocatorKey(LocatorNamespaceKey, namespaces.List()...)
+}
+
+// QueryNodeUpdate matches like NodeUpdate.
+func QueryNodeUpdate() IntervalQuery {
+	return QueryReason(NodeUpdateReason)
+}
+
+// QueryNodeLeaseBackoff matches like NodeLeaseBackoff.
+func QueryNodeLeaseBackoff() IntervalQuery {
+	return QueryReason(NodeFailedLeaseBackoff)
+}
+
+// QueryLocatorType matches intervals with any of the locator types.
+func QueryLocatorType(locatorTypes ...LocatorType) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			for _, locatorType := range locatorTypes {
+				if interval.Locator.Type == locatorType {
+					return true
+				}
+			}
+			return false
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			return unionOf(s.byLocatorType, locatorTypes), true
+		},
+	}
+}
+
+// QuerySource matches intervals from any of the sources.
+func QuerySource(sources ...IntervalSource) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			for _, source := range sources {
+				if interval.Source == source {
+					return true
+				}
+			}
+			return false
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			return unionOf(s.bySource, sources), true
+		},
+	}
+}
+
+// QueryReason matches intervals with any of the reasons.
+func QueryReason(reasons ...IntervalReason) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			for _, reason := range reasons {
+				if interval.Message.Reason == reason {
+					return true
+				}
+			}
+			return false
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			return unionOf(s.byReason, reasons), true
+		},
+	}
+}
+
+// QueryLocatorKey matches intervals whose locator has the key with any of the values.
+func QueryLocatorKey(key LocatorKey, values ...string) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			actual, ok := interval.Locator.Keys[key]
+			if !ok {
+				return false
+			}
+			for _, value := range values {
+				if actual == value {
+					return true
+				}
+			}
+			return false
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			return unionOf(s.byLocatorKey[key], values), true
+		},
+	}
+}
+
+// QueryOverlapping matches intervals overlapping the range from from to to, either of which may be zero for an open
+// ended range.  Intervals without an end overlap every range ending after they start.
+func QueryOverlapping(from, to time.Time) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			return overlapsRange(interval, from, to)
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			return s.overlapping(from, to), true
+		},
+	}
+}
+
+func overlapsRange(interval Interval, from, to time.Time) bool {
+	if !to.IsZero() && interval.From.After(to) {
+		return false
+	}
+	return from.IsZero() || interval.To.IsZero() || !interval.To.Before(from)
+}
+
+// QueryAnd matches intervals matching all the queries, looking up the intervals found by the indexes of every query
+// that uses them.
+func QueryAnd(queries ...IntervalQuery) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			for _, query := range queries {
+				if !query.Matches(interval) {
+					return false
+				}
+			}
+			return true
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			var found []int
+			indexed := false
+			for _, query := range queries {
+				candidates, ok := query.candidates(s)
+				if !ok {
+					continue
+				}
+				if !indexed {
+					found, indexed = candidates, true
+					continue
+				}
+				found = intersectSorted(found, candidates)
+			}
+			return found, indexed
+		},
+	}
+}
+
+// QueryOr matches intervals matching any of the queries.  It uses the indexes only if every query does.
+func QueryOr(queries ...IntervalQuery) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			for _, query := range queries {
+				if query.Matches(interval) {
+					return true
+				}
+			}
+			return false
+		},
+		lookup: func(s *IntervalSet) ([]int, bool) {
+			found := []int{}
+			for _, query := range queries {
+				candidates, ok := query.candidates(s)
+				if !ok {
+					return nil, false
+				}
+				found = unionSorted(found, candidates)
+			}
+			return found, true
+		},
+	}
+}
+
+// QueryNot matches intervals not matching the query, without using the indexes.
+func QueryNot(query IntervalQuery) IntervalQuery {
+	return intervalQuery{
+		matches: func(interval Interval) bool {
+			return !query.Matches(interval)
+		},
+		lookup: unindexed,
+	}
+}
+
+func unionOf[K comparable](index map[K][]int, keys []K) []int {
+	found := []int{}
+	for _, key := range keys {
+		found = unionSorted(found, index[key])
+	}
+	return found
+}
+
+func intersectSorted(a, b []int) []int {
+	found := []int{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			found = append(found, a[i])
+			i++
+			j++
+		}
+	}
+	return found
+}
+
+func unionSorted(a, b []int) []int {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	found := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			found = append(found, a[i])
+			i++
+		case a[i] > b[j]:
+			found = append(found, b[j])
+			j++
+		default:
+			found = append(found, a[i])
+			i++
+			j++
+		}
+	}
+	found = append(found, a[i:]...)
+	return append(found, b[j:]...)
+}
@@ -0,0 +1,125 @@
+package monitorapi
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestIntervalSet_Query(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	intervals := Intervals{}
+	for i := 0; i < 200; i++ {
+		locator := NewLocator().NodeFromName(fmt.Sprintf("node-%d", i%4))
+		var source IntervalSource = SourceNodeState
+		if i%3 == 0 {
+			locator = NewLocator().PodFromNames(fmt.Sprintf("ns-%d", i%5), "pod", "")
+			source = SourcePodState
+		}
+		reason := NodeUpdateReason
+		if i%7 == 0 {
+			reason = NodeFailedLeaseBackoff
+		}
+		to := at(i + i%13)
+		if i%17 == 0 {
+			to = time.Time{}
+		}
+		intervals = append(intervals, NewInterval(source, Info).
+			Locator(locator).
+			Message(NewMessage().Reason(reason).HumanMessage(fmt.Sprintf("interval %d", i))).
+			Build(at(200-i), to))
+	}
+	set := NewIntervalSet(intervals)
+
+	tests := []struct {
+		name  string
+		query IntervalQuery
+	}{
+		{name: "locator type", query: QueryLocatorType(LocatorTypePod)},
+		{name: "source", query: QuerySource(SourceNodeState, SourcePodState)},
+		{name: "reason", query: QueryReason(NodeFailedLeaseBackoff)},
+		{name: "missing reason", query: QueryReason(MachineCreated)},
+		{name: "locator key", query: QueryLocatorKey(LocatorNamespaceKey, "ns-1", "ns-3")},
+		{name: "overlapping", query: QueryOverlapping(at(50), at(60))},
+		{name: "overlapping from", query: QueryOverlapping(at(180), time.Time{})},
+		{name: "overlapping to", query: QueryOverlapping(time.Time{}, at(10))},
+		{name: "overlapping nothing", query: QueryOverlapping(at(500), at(600))},
+		{name: "and", query: QueryAnd(QueryLocatorType(LocatorTypeNode), QueryReason(NodeUpdateReason), QueryOverlapping(at(20), at(40)))},
+		{name: "and with an unindexed query", query: QueryAnd(QuerySource(SourcePodState), QueryNot(QueryLocatorKey(LocatorNamespaceKey, "ns-2")))},
+		{name: "or", query: QueryOr(QueryLocatorKey(LocatorNodeKey, "node-1"), QueryReason(NodeFailedLeaseBackoff))},
+		{name: "or with an unindexed query", query: QueryOr(QueryReason(NodeFailedLeaseBackoff), QueryFunc(IsInE2ENamespace))},
+		{name: "not", query: QueryNot(QueryLocatorType(LocatorTypeNode))},
+	}
+	sorted := append(Intervals{}, intervals...)
+	sort.Sort(sorted)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := sorted.Filter(test.query.MatchesFunc())
+			if expected == nil {
+				expected = Intervals{}
+			}
+			assert.Equal(t, expected, set.Query(test.query))
+		})
+	}
+	assert.Equal(t, sorted, set.Intervals())
+	assert.Equal(t, 200, set.Len())
+}
+
+func TestIntervalSet_QueryMatchers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	intervals := Intervals{}
+	for i := 0; i < 60; i++ {
+		var interval Interval
+		switch i % 4 {
+		case 0:
+			interval = NewInterval(SourceDisruption, Error).
+				Locator(NewLocator().LocateDisruptionCheck(fmt.Sprintf("backend-%d", i%3), "instance", NewConnectionType)).
+				Message(NewMessage().HumanMessage("disrupted")).
+				Build(start.Add(time.Duration(i)*time.Second), start.Add(time.Duration(i+1)*time.Second))
+		case 1:
+			interval = NewInterval(SourcePodState, Info).
+				Locator(NewLocator().PodFromNames(fmt.Sprintf("ns-%d", i%5), "pod", "")).
+				Message(NewMessage().HumanMessage("pod")).
+				Build(start.Add(time.Duration(i)*time.Second), start.Add(time.Duration(i+1)*time.Second))
+		default:
+			reason := NodeUpdateReason
+			if i%3 == 0 {
+				reason = NodeFailedLeaseBackoff
+			}
+			interval = NewInterval(SourceNodeState, Info).
+				Locator(NewLocator().NodeFromName("node")).
+				Message(NewMessage().Reason(reason).HumanMessage("node")).
+				Build(start.Add(time.Duration(i)*time.Second), start.Add(time.Duration(i+1)*time.Second))
+		}
+		intervals = append(intervals, interval)
+	}
+	set := NewIntervalSet(intervals)
+
+	tests := []struct {
+		name    string
+		matches EventIntervalMatchesFunc
+		query   IntervalQuery
+	}{
+		{name: "disruption event", matches: IsDisruptionEvent, query: QueryDisruptionEvent()},
+		{name: "disruption backend", matches: IsForDisruptionBackend("backend-1"), query: QueryForDisruptionBackend("backend-1")},
+		{name: "namespaces", matches: IsInNamespaces(sets.NewString("ns-1", "ns-3")), query: QueryInNamespaces(sets.NewString("ns-1", "ns-3"))},
+		{name: "node update", matches: NodeUpdate, query: QueryNodeUpdate()},
+		{name: "node lease backoff", matches: NodeLeaseBackoff, query: QueryNodeLeaseBackoff()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := intervals.Filter(test.matches)
+			assert.NotEmpty(t, expected)
+			assert.Equal(t, expected, set.Query(test.query))
+			assert.Equal(t, expected, intervals.Filter(test.query.MatchesFunc()))
+		})
+	}
+}
@@ -111,6 +111,7 @@ const (
 	LocatorTypeCloudMetrics    LocatorType = "CloudMetrics"
 
 	LocatorTypeAPIUnreachableFromClient LocatorType = "APIUnreachableFromClient"
+	LocatorTypeTestRunner               LocatorType = "TestRunner"
 )
 
 type LocatorKey string
@@ -147,6 +148,7 @@ const (
 	LocatorMetricKey                LocatorKey = "metric"
 
 	LocatorAPIUnreachableHostKey LocatorKey = "host"
+	LocatorTestRunnerKey         LocatorKey = "test-runner"
 )
 
 type Locator struct {
@@ -203,6 +205,9 @@ const (
 	// Kubelet tries to get lease five times and then gives up
 	NodeFailedLeaseBackoff IntervalReason = "FailedToUpdateLeaseInBackoff"
 
+	// a metrics endpoint was down while its node was neither updating nor rebooting
+	MetricsEndpointDownOutsideNodeUpdateReason IntervalReason = "MetricsEndpointDownOutsideNodeUpdate"
+
 	MachineConfigChangeReason  IntervalReason = "MachineConfigChange"
 	MachineConfigReachedReason IntervalReason = "MachineConfigReached"
 
@@ -229,6 +234,9 @@ const (
 
 	NodeInstallerReason IntervalReason = "NodeInstaller"
 
+	ParallelismChangedReason      IntervalReason = "ParallelismChanged"
+	ClusterHealthGateFailedReason IntervalReason = "ClusterHealthGateFailed"
+
 	// client metrics show error connecting to the kube-apiserver
 	APIUnreachableFromClientMetrics IntervalReason = "APIUnreachableFromClientMetrics"
 
@@ -271,6 +279,12 @@ const (
 	AnnotationStatus         AnnotationKey = "status"
 	AnnotationCondition      AnnotationKey = "condition"
 	AnnotationPercentage     AnnotationKey = "percentage"
+	// AnnotationUpgradeHop is the hop, starting at 1, of the multi-hop upgrade a cluster version event belongs to.
+	AnnotationUpgradeHop AnnotationKey = "upgrade-hop"
+	// AnnotationParallelism is the number of tests the runner runs at once.
+	AnnotationParallelism AnnotationKey = "parallelism"
+	// AnnotationTestRun identifies one run of an e2e test, to tell apart runs of the same test at the same time.
+	AnnotationTestRun AnnotationKey = "test-run"
 )
 
 // ConstructionOwner was originally meant to signify that an interval was derived from other intervals.
@@ -288,6 +302,7 @@ const (
 	ConstructionOwnerEtcdLifecycle    = "etcd-lifecycle-constructor"
 	ConstructionOwnerMachineLifecycle = "machine-lifecycle-constructor"
 	ConstructionOwnerLeaseChecker     = "lease-checker"
+	ConstructionOwnerMetricsEndpoint  = "metrics-endpoint-down-constructor"
 )
 
 type Message struct {
@@ -336,6 +351,8 @@ const (
 
 	SourceAPIUnreachableFromClient IntervalSource = "APIUnreachableFromClient"
 	SourceMachine                  IntervalSource = "MachineMonitor"
+	SourceTestParallelism          IntervalSource = "TestParallelism"
+	SourceClusterHealthGate        IntervalSource = "ClusterHealthGate"
 )
 
 type Interval struct {
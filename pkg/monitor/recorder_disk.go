@@ -0,0 +1,484 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const diskSegmentPattern = "segment-%08d.jsonl"
+
+// diskSegmentSize is the number of records of a segment before the next one is started.
+var diskSegmentSize = 10000
+
+// diskRecord is a line of a segment: an interval added with its id, or the new end of the interval with the id.
+type diskRecord struct {
+	ID       int                  `json:"id"`
+	Interval *monitorapi.Interval `json:"interval,omitempty"`
+	To       *time.Time           `json:"to,omitempty"`
+}
+
+// diskSegment is the time index of a segment.
+type diskSegment struct {
+	path    string
+	firstID int
+	// minFrom, maxFrom and maxTo bound the intervals added in the segment, open is set if any was added without an
+	// end.
+	minFrom time.Time
+	maxFrom time.Time
+	maxTo   time.Time
+	open    bool
+	// endedIn are the indexes of the later segments the ends of intervals added in the segment were written to.
+	endedIn []int
+}
+
+func (s *diskSegment) add(interval monitorapi.Interval) {
+	if s.minFrom.IsZero() || interval.From.Before(s.minFrom) {
+		s.minFrom = interval.From
+	}
+	if interval.From.After(s.maxFrom) {
+		s.maxFrom = interval.From
+	}
+	s.end(interval.To)
+	if interval.To.IsZero() {
+		s.open = true
+	}
+}
+
+func (s *diskSegment) end(to time.Time) {
+	if to.After(s.maxTo) {
+		s.maxTo = to
+	}
+}
+
+// startsBy returns whether the segment may hold intervals starting by to, which is open ended when zero.
+func (s *diskSegment) startsBy(to time.Time) bool {
+	return to.IsZero() || !s.minFrom.After(to)
+}
+
+// DiskRecorder is a recorder that appends the intervals to segments in a directory instead of keeping them in
+// memory, for runs recording more intervals than fit in memory.  Only the time index of the segments and the intervals
+// started and not ended yet are kept in memory, and the intervals are read from the segments that may hold intervals
+// of the range asked for.  Recorded resources are kept in memory.
+//
+// Every change is written before it returns, so the intervals of a runner that crashed are recorded again when a
+// recorder resuming them is created for the same directory.
+type DiskRecorder struct {
+	resources *recorder
+
+	lock     sync.Mutex
+	dir      string
+	segments []*diskSegment
+	file     *os.File
+	writer   *bufio.Writer
+	written  int
+	nextID   int
+	// started are the intervals started and not ended yet.
+	started map[int]monitorapi.Interval
+	// resumedFrom is the start of the earliest interval resumed from the directory.
+	resumedFrom time.Time
+}
+
+var _ monitorapi.Recorder = &DiskRecorder{}
+
+// NewDiskRecorder creates a recorder storing its intervals in dir.  If resume is set, the intervals already stored
+// there by a runner that crashed are kept, and the intervals it started and did not end can still be ended.
+// Otherwise the intervals stored there are removed.
+func NewDiskRecorder(dir string, resume bool) (*DiskRecorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	m := &DiskRecorder{
+		resources: NewRecorder().(*recorder),
+		dir:       dir,
+		started:   map[int]monitorapi.Interval{},
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "segment-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	if !resume {
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+		paths = nil
+	}
+	for _, path := range paths {
+		segment := &diskSegment{path: path, firstID: m.nextID}
+		m.segments = append(m.segments, segment)
+		err := readDiskSegment(path, func(record diskRecord) {
+			switch {
+			case record.Interval != nil:
+				segment.add(*record.Interval)
+				m.nextID = max(m.nextID, record.ID+1)
+				if record.Interval.To.IsZero() {
+					m.started[record.ID] = *record.Interval
+				}
+			case record.To != nil:
+				m.end(record.ID, *record.To)
+				delete(m.started, record.ID)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", path, err)
+		}
+		if !segment.minFrom.IsZero() && (m.resumedFrom.IsZero() || segment.minFrom.Before(m.resumedFrom)) {
+			m.resumedFrom = segment.minFrom
+		}
+	}
+
+	if err := m.startSegment(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readDiskSegment passes every record of a segment to handle.  A line that cannot be decoded, like the last line of a
+// runner that crashed while writing it, is skipped.
+func readDiskSegment(path string, handle func(diskRecord)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			record := diskRecord{}
+			if decodeErr := json.Unmarshal(line, &record); decodeErr == nil {
+				handle(record)
+			}
+		}
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// startSegment must be called with the lock held.
+func (m *DiskRecorder) startSegment() error {
+	if m.file != nil {
+		if err := m.file.Close(); err != nil {
+			return err
+		}
+	}
+	segment := &diskSegment{
+		path:    filepath.Join(m.dir, fmt.Sprintf(diskSegmentPattern, len(m.segments))),
+		firstID: m.nextID,
+	}
+	file, err := os.OpenFile(segment.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	m.segments = append(m.segments, segment)
+	m.file = file
+	m.writer = bufio.NewWriter(file)
+	m.written = 0
+	return nil
+}
+
+// write appends the records to the segments and must be called with the lock held.  Errors are logged rather than
+// returned, like the serialization errors of the in-memory recorder, so that a full disk does not stop the run.
+func (m *DiskRecorder) write(records ...diskRecord) {
+	for _, record := range records {
+		if m.written >= diskSegmentSize {
+			if err := m.writer.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "error writing intervals: %v\n", err)
+			}
+			if err := m.startSegment(); err != nil {
+				fmt.Fprintf(os.Stderr, "error starting a segment of intervals: %v\n", err)
+			}
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializing: %v\n", err)
+			continue
+		}
+		m.writer.Write(data)
+		m.writer.WriteByte('\n')
+		m.written++
+
+		switch {
+		case record.Interval != nil:
+			m.segments[len(m.segments)-1].add(*record.Interval)
+		case record.To != nil:
+			m.end(record.ID, *record.To)
+		}
+	}
+	if err := m.writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing intervals: %v\n", err)
+	}
+}
+
+// end indexes the end of the interval with the id, written to the last segment, and must be called with the lock held.
+func (m *DiskRecorder) end(id int, to time.Time) {
+	segment := m.segmentFor(id)
+	segment.end(to)
+	last := len(m.segments) - 1
+	if segment != m.segments[last] && (len(segment.endedIn) == 0 || segment.endedIn[len(segment.endedIn)-1] != last) {
+		segment.endedIn = append(segment.endedIn, last)
+	}
+}
+
+// segmentFor returns the segment the interval with the id was added to.
+func (m *DiskRecorder) segmentFor(id int) *diskSegment {
+	i := sort.Search(len(m.segments), func(i int) bool {
+		return m.segments[i].firstID > id
+	})
+	return m.segments[max(0, i-1)]
+}
+
+// ResumedFrom returns the start of the earliest interval resumed from the directory, zero if none was.
+func (m *DiskRecorder) ResumedFrom() time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.resumedFrom
+}
+
+// Close writes the intervals not written yet and closes the current segment.
+func (m *DiskRecorder) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.file == nil {
+		return nil
+	}
+	if err := m.writer.Flush(); err != nil {
+		return err
+	}
+	err := m.file.Close()
+	m.file = nil
+	return err
+}
+
+func (m *DiskRecorder) CurrentResourceState() monitorapi.ResourcesMap {
+	return m.resources.CurrentResourceState()
+}
+
+func (m *DiskRecorder) RecordResource(resourceType string, obj runtime.Object) {
+	m.resources.RecordResource(resourceType, obj)
+}
+
+// Record captures one or more conditions at the current time. All conditions are recorded
+// in monotonic order as EventInterval objects.
+func (m *DiskRecorder) Record(conditions ...monitorapi.Condition) {
+	m.RecordAt(time.Now().UTC(), conditions...)
+}
+
+// RecordAt captures one or more conditions at the provided time. All conditions are recorded
+// as EventInterval objects.
+func (m *DiskRecorder) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
+	if len(conditions) == 0 {
+		return
+	}
+	intervals := monitorapi.Intervals{}
+	for _, condition := range conditions {
+		intervals = append(intervals, monitorapi.Interval{
+			Condition: condition,
+			From:      t,
+			To:        t,
+		})
+	}
+	m.AddIntervals(intervals...)
+}
+
+// AddIntervals provides a mechanism to directly inject eventIntervals
+func (m *DiskRecorder) AddIntervals(eventIntervals ...monitorapi.Interval) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	records := make([]diskRecord, 0, len(eventIntervals))
+	for i := range eventIntervals {
+		records = append(records, diskRecord{ID: m.nextID, Interval: &eventIntervals[i]})
+		m.nextID++
+	}
+	m.write(records...)
+}
+
+// StartInterval inserts a record at time t with the provided condition and returns an opaque
+// locator to the interval. The caller may close the sample at any point by invoking EndInterval().
+func (m *DiskRecorder) StartInterval(interval monitorapi.Interval) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.write(diskRecord{ID: id, Interval: &interval})
+	m.started[id] = interval
+	return id
+}
+
+// EndInterval updates the To of the interval started by StartInterval if it is greater than
+// the from.
+func (m *DiskRecorder) EndInterval(startedInterval int, t time.Time) *monitorapi.Interval {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	interval, ok := m.started[startedInterval]
+	if !ok {
+		// ended before, or started before a restart
+		found, err := m.readInterval(startedInterval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading interval %d: %v\n", startedInterval, err)
+		}
+		if found == nil {
+			return nil
+		}
+		interval = *found
+	}
+	delete(m.started, startedInterval)
+
+	if interval.From.Before(t) {
+		interval.To = t
+		m.write(diskRecord{ID: startedInterval, To: &t})
+	}
+	return &interval
+}
+
+// readInterval must be called with the lock held.
+func (m *DiskRecorder) readInterval(id int) (*monitorapi.Interval, error) {
+	if id < 0 || id >= m.nextID {
+		return nil, nil
+	}
+	if err := m.writer.Flush(); err != nil {
+		return nil, err
+	}
+	ids, events, err := readDiskIntervals(*m.segmentFor(id), func(segment int) (map[int]time.Time, error) {
+		return readDiskEnds(m.segments[segment].path)
+	})
+	for i := range ids {
+		if ids[i] == id {
+			return &events[i], err
+		}
+	}
+	return nil, err
+}
+
+// Intervals returns all events that occur between from and to, including
+// any sampled conditions that were encountered during that period.
+// Intervals are returned in order of their occurrence. The returned slice
+// is a copy of the monitor's state and is safe to update.
+// The intervals are those the in-memory recorder returns, see Intervals.Slice,
+// but only the segments that may hold them are read.
+func (m *DiskRecorder) Intervals(from, to time.Time) monitorapi.Intervals {
+	m.lock.Lock()
+	if err := m.writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing intervals: %v\n", err)
+	}
+	segments := make([]diskSegment, 0, len(m.segments))
+	for _, segment := range m.segments {
+		segments = append(segments, *segment)
+	}
+	m.lock.Unlock()
+
+	ends := map[int]map[int]time.Time{}
+	endsIn := func(segment int) (map[int]time.Time, error) {
+		if _, ok := ends[segment]; !ok {
+			segmentEnds, err := readDiskEnds(segments[segment].path)
+			if err != nil {
+				return nil, err
+			}
+			ends[segment] = segmentEnds
+		}
+		return ends[segment], nil
+	}
+	read := map[int]monitorapi.Intervals{}
+	readSegments := func(matches func(segment diskSegment) bool) monitorapi.Intervals {
+		events := monitorapi.Intervals{}
+		for i, segment := range segments {
+			if !matches(segment) {
+				continue
+			}
+			if _, ok := read[i]; !ok {
+				_, segmentEvents, err := readDiskIntervals(segment, endsIn)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error reading %s: %v\n", segment.path, err)
+				}
+				read[i] = segmentEvents
+			}
+			events = append(events, read[i]...)
+		}
+		sort.Sort(events)
+		return events
+	}
+
+	if from.IsZero() {
+		return readSegments(func(segment diskSegment) bool {
+			return segment.startsBy(to)
+		}).Slice(from, to)
+	}
+	// Slice starts from the first interval ending from from on, and returns every interval after it that starts by to,
+	// even those ending before from.  The first interval is in the segments that may hold intervals ending from from on.
+	first := readSegments(func(segment diskSegment) bool {
+		return segment.startsBy(to) && (segment.open || !segment.maxTo.Before(from))
+	}).Slice(from, to)
+	if len(first) == 0 {
+		return monitorapi.Intervals{}
+	}
+	firstFrom := first[0].From
+	return readSegments(func(segment diskSegment) bool {
+		return segment.startsBy(to) && !segment.maxFrom.Before(firstFrom)
+	}).Slice(from, to)
+}
+
+// readDiskIntervals returns the intervals added in a segment and their ids, with the ends written to the segment and
+// to the later segments the intervals were ended in, which endsIn returns by index.
+func readDiskIntervals(segment diskSegment, endsIn func(segment int) (map[int]time.Time, error)) ([]int, monitorapi.Intervals, error) {
+	ids := []int{}
+	events := monitorapi.Intervals{}
+	indexes := map[int]int{}
+	err := readDiskSegment(segment.path, func(record diskRecord) {
+		switch {
+		case record.Interval != nil:
+			indexes[record.ID] = len(events)
+			ids = append(ids, record.ID)
+			events = append(events, *record.Interval)
+		case record.To != nil:
+			if i, ok := indexes[record.ID]; ok {
+				events[i].To = *record.To
+			}
+		}
+	})
+	if err != nil {
+		return ids, events, err
+	}
+	for _, later := range segment.endedIn {
+		ends, err := endsIn(later)
+		if err != nil {
+			return ids, events, err
+		}
+		for id, to := range ends {
+			if i, ok := indexes[id]; ok {
+				events[i].To = to
+			}
+		}
+	}
+	return ids, events, nil
+}
+
+// readDiskEnds returns the ends written to a segment by the id of the interval they end.
+func readDiskEnds(path string) (map[int]time.Time, error) {
+	ends := map[int]time.Time{}
+	err := readDiskSegment(path, func(record diskRecord) {
+		if record.To != nil {
+			ends[record.ID] = *record.To
+		}
+	})
+	return ends, err
+}
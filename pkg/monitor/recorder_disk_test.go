@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestDiskRecorder(t *testing.T) {
+	newInterval := func(i int, from, to time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("worker-0")).
+			Message(monitorapi.NewMessage().HumanMessage(fmt.Sprintf("interval %d", i))).
+			Build(from, to)
+	}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 123, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	defer func(size int) { diskSegmentSize = size }(diskSegmentSize)
+	diskSegmentSize = 100
+
+	dir := t.TempDir()
+	disk, err := NewDiskRecorder(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumedFrom := disk.ResumedFrom(); !resumedFrom.IsZero() {
+		t.Errorf("expected no resumed intervals in a new directory, got %s", resumedFrom)
+	}
+	memory := NewRecorder()
+	record := func(fn func(recorder monitorapi.Recorder)) {
+		fn(disk)
+		fn(memory)
+	}
+
+	// enough intervals for several segments
+	for i := 0; i < 2*diskSegmentSize+10; i++ {
+		interval := newInterval(i, at(i), at(i+1))
+		record(func(recorder monitorapi.Recorder) { recorder.AddIntervals(interval) })
+	}
+	record(func(recorder monitorapi.Recorder) {
+		recorder.RecordAt(at(50), monitorapi.Condition{Message: monitorapi.NewMessage().HumanMessage("condition").Build()})
+	})
+	// a long interval makes the ranges in it start from it, with the intervals that ended before the range
+	long := newInterval(-4, at(5), at(160))
+	record(func(recorder monitorapi.Recorder) { recorder.AddIntervals(long) })
+	var diskStarted, memoryStarted int
+	diskStarted = disk.StartInterval(newInterval(-1, at(10), time.Time{}))
+	memoryStarted = memory.StartInterval(newInterval(-1, at(10), time.Time{}))
+	diskEnded := disk.EndInterval(diskStarted, at(300))
+	memory.EndInterval(memoryStarted, at(300))
+	if diskEnded == nil || !diskEnded.To.Equal(at(300)) {
+		t.Errorf("expected the interval to be ended, got %#v", diskEnded)
+	}
+	// an interval still open when the runner crashes
+	diskOpen := disk.StartInterval(newInterval(-3, at(20), time.Time{}))
+	memoryOpen := memory.StartInterval(newInterval(-3, at(20), time.Time{}))
+	if len(disk.segments) != 3 {
+		t.Errorf("expected 3 segments, got %d", len(disk.segments))
+	}
+
+	ranges := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{name: "all"},
+		// SerializeResults writes the intervals between the start and the stop of the monitor
+		{name: "the run", from: start, to: at(2*diskSegmentSize + 10)},
+		{name: "range in the first segment", from: at(10), to: at(20)},
+		{name: "range in the last segment", from: at(199), to: at(250)},
+		{name: "after the intervals", from: at(250), to: at(400)},
+		{name: "range in the long interval", from: at(150), to: at(155)},
+		{name: "from", from: at(150)},
+		{name: "from after the long interval", from: at(170)},
+		{name: "to", to: at(5)},
+	}
+	assertSame := func(recorder monitorapi.Recorder) {
+		t.Helper()
+		for _, r := range ranges {
+			expected := memory.Intervals(r.from, r.to)
+			got := recorder.Intervals(r.from, r.to)
+			if len(got) != len(expected) {
+				t.Errorf("%s: expected %d intervals, got %d", r.name, len(expected), len(got))
+				continue
+			}
+			for i := range expected {
+				if got[i].String() != expected[i].String() || !got[i].From.Equal(expected[i].From) || !got[i].To.Equal(expected[i].To) {
+					t.Errorf("%s: expected %v, got %v", r.name, expected[i], got[i])
+					break
+				}
+			}
+		}
+	}
+	assertSame(disk)
+
+	// a runner that crashed while writing
+	if err := disk.Close(); err != nil {
+		t.Fatal(err)
+	}
+	last := disk.segments[len(disk.segments)-1].path
+	file, err := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"id":99999,"interval":{"lev`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	disk, err = NewDiskRecorder(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSame(disk)
+	if resumedFrom := disk.ResumedFrom(); !resumedFrom.Equal(start) {
+		t.Errorf("expected the intervals to be resumed from %s, got %s", start, resumedFrom)
+	}
+
+	// the interval started before the restart is still started, the one ended is not
+	if _, ok := disk.started[diskOpen]; !ok || len(disk.started) != 1 {
+		t.Errorf("expected only interval %d to be started after the restart, got %v", diskOpen, disk.started)
+	}
+	diskEnded = disk.EndInterval(diskOpen, at(350))
+	memory.EndInterval(memoryOpen, at(350))
+	if diskEnded == nil || !diskEnded.To.Equal(at(350)) {
+		t.Errorf("expected the interval started before the restart to be ended, got %#v", diskEnded)
+	}
+	// the end is written to the segment started after the restart, and read from it with the interval
+	if endedIn := disk.segmentFor(diskOpen).endedIn; len(endedIn) != 1 || endedIn[0] != len(disk.segments)-1 {
+		t.Errorf("expected the interval to be ended in the last segment, got %v", endedIn)
+	}
+	assertSame(disk)
+
+	// recording continues after the intervals recorded before the restart
+	interval := newInterval(-2, at(400), at(401))
+	record(func(recorder monitorapi.Recorder) { recorder.AddIntervals(interval) })
+	assertSame(disk)
+	if matches, _ := filepath.Glob(filepath.Join(dir, "segment-*.jsonl")); len(matches) != 4 {
+		t.Errorf("expected a new segment after the restart, got %v", matches)
+	}
+
+	// a new run does not report the intervals of the previous one
+	if err := disk.Close(); err != nil {
+		t.Fatal(err)
+	}
+	disk, err = NewDiskRecorder(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer disk.Close()
+	if intervals := disk.Intervals(time.Time{}, time.Time{}); len(intervals) != 0 {
+		t.Errorf("expected no intervals in a new run, got %d", len(intervals))
+	}
+	if resumedFrom := disk.ResumedFrom(); !resumedFrom.IsZero() {
+		t.Errorf("expected no resumed intervals in a new run, got %s", resumedFrom)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "segment-*.jsonl")); len(matches) != 1 {
+		t.Errorf("expected only the segment of the new run, got %v", matches)
+	}
+}
@@ -0,0 +1,197 @@
+package monitor
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// ReplayRun is a finished run stored in the artifacts of a job: the intervals, the tracked resources and the junits of
+// the monitor tests it wrote.
+type ReplayRun struct {
+	// TimeSuffix is the suffix of the files of the run, e.g. _20230214-203340.
+	TimeSuffix string
+	// Dir is the directory holding the files of the run.
+	Dir       string
+	Intervals monitorapi.Intervals
+	Resources monitorapi.ResourcesMap
+	// JUnits are the junits of the monitor tests of the run, if they were stored.
+	JUnits []*junitapi.JUnitTestCase
+}
+
+// Bounds returns the beginning of the first interval and the end of the last interval of the run.
+func (r *ReplayRun) Bounds() (time.Time, time.Time) {
+	var beginning, end time.Time
+	for _, interval := range r.Intervals {
+		if beginning.IsZero() || interval.From.Before(beginning) {
+			beginning = interval.From
+		}
+		if interval.To.After(end) {
+			end = interval.To
+		}
+		if interval.From.After(end) {
+			end = interval.From
+		}
+	}
+	return beginning, end
+}
+
+// FindReplayRuns reads every run stored below artifactDir, one per e2e-events file, with the resource-*.zip files and
+// the e2e-monitor-tests junit written next to it, ordered by their time suffix.  Upgrade jobs store one run for the upgrade and one for the tests.
+func FindReplayRuns(artifactDir string) ([]*ReplayRun, error) {
+	runs := []*ReplayRun{}
+	timeSuffixes := map[string]string{}
+	err := filepath.WalkDir(artifactDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(d.Name(), "e2e-events") || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		timeSuffix := strings.TrimSuffix(strings.TrimPrefix(d.Name(), "e2e-events"), ".json")
+		if previous, ok := timeSuffixes[timeSuffix]; ok {
+			fmt.Fprintf(os.Stderr, "Ignoring %s, the run was already read from %s\n", path, previous)
+			return nil
+		}
+		timeSuffixes[timeSuffix] = path
+
+		run, err := readReplayRun(filepath.Dir(path), timeSuffix)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].TimeSuffix < runs[j].TimeSuffix
+	})
+	return runs, nil
+}
+
+func readReplayRun(dir, timeSuffix string) (*ReplayRun, error) {
+	intervals, err := monitorserialization.EventsFromFile(filepath.Join(dir, fmt.Sprintf("e2e-events%s.json", timeSuffix)))
+	if err != nil {
+		return nil, err
+	}
+	run := &ReplayRun{
+		TimeSuffix: timeSuffix,
+		Dir:        dir,
+		Intervals:  intervals,
+		Resources:  monitorapi.ResourcesMap{},
+	}
+
+	resourceFilenames, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("resource-*%s.zip", timeSuffix)))
+	if err != nil {
+		return nil, err
+	}
+	for _, resourceFilename := range resourceFilenames {
+		resourceType := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(resourceFilename), "resource-"), timeSuffix+".zip")
+		instances, err := monitorserialization.InstanceMapFromFile(resourceFilename, resourceType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", resourceFilename, err)
+		}
+		run.Resources[resourceType] = instances
+	}
+
+	junitFilename := filepath.Join(dir, fmt.Sprintf("e2e-monitor-tests_%s.xml", timeSuffix))
+	data, err := os.ReadFile(junitFilename)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		junitSuite := &junitapi.JUnitTestSuite{}
+		if err := xml.Unmarshal(data, junitSuite); err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", junitFilename, err)
+		}
+		run.JUnits = junitSuite.TestCases
+	}
+	return run, nil
+}
+
+type replayArtifacts struct {
+	artifactDir string
+	resources   monitorapi.ResourcesMap
+	junits      []*junitapi.JUnitTestCase
+	beginning   time.Time
+	end         time.Time
+	// replayed are the intervals of the run without the computed ones, which already include the intervals collected
+	// by the monitor tests, keyed by their serialization.
+	replayed map[string]bool
+}
+
+// isComputedInterval returns true if the interval was computed by a monitor test from other intervals, which annotates
+// it with what constructed it.
+func isComputedInterval(interval monitorapi.Interval) bool {
+	return len(interval.Message.Annotations[monitorapi.AnnotationConstructed]) > 0
+}
+
+// NewReplayMonitor creates a monitor that replays a finished run instead of monitoring a cluster.  Nothing is
+// started, data is collected from the artifacts stored in artifactDir by the monitor tests able to, and the intervals
+// are computed and the tests evaluated from the intervals and resources of the run.  The intervals computed when the
+// job ran are dropped and computed again.  The other intervals already include those collected when the job ran, so
+// only the collected intervals that differ are added.
+func NewReplayMonitor(artifactDir, storageDir string, run *ReplayRun, monitorTestRegistry monitortestframework.MonitorTestRegistry) (Interface, error) {
+	replay := &replayArtifacts{
+		artifactDir: artifactDir,
+		resources:   run.Resources,
+		junits:      run.JUnits,
+		replayed:    map[string]bool{},
+	}
+	replay.beginning, replay.end = run.Bounds()
+	// the intervals computed when the job ran are computed again, by the monitor tests as they are now
+	intervals := run.Intervals.Filter(func(interval monitorapi.Interval) bool {
+		return !isComputedInterval(interval)
+	})
+	for _, interval := range intervals {
+		key, err := monitorserialization.IntervalToOneLineJSON(interval)
+		if err != nil {
+			return nil, err
+		}
+		replay.replayed[string(key)] = true
+	}
+
+	recorder := NewRecorder()
+	recorder.AddIntervals(intervals...)
+	return &Monitor{
+		recorder:            recorder,
+		monitorTestRegistry: monitorTestRegistry,
+		storageDir:          storageDir,
+		replay:              replay,
+	}, nil
+}
+
+// addIntervals records the intervals, without those already part of the replayed run.
+func (m *Monitor) addIntervals(intervals monitorapi.Intervals) {
+	if m.replay == nil {
+		m.recorder.AddIntervals(intervals...)
+		return
+	}
+	for _, interval := range intervals {
+		if key, err := monitorserialization.IntervalToOneLineJSON(interval); err == nil && m.replay.replayed[string(key)] {
+			continue
+		}
+		m.recorder.AddIntervals(interval)
+	}
+}
+
+func (m *Monitor) currentResourceState() monitorapi.ResourcesMap {
+	if m.replay != nil {
+		return m.replay.resources
+	}
+	return m.recorder.CurrentResourceState()
+}
@@ -0,0 +1,261 @@
+package monitor
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+type replayedMonitorTest struct {
+	collected   monitorapi.Intervals
+	computed    monitorapi.Intervals
+	resources   monitorapi.ResourcesMap
+	evaluated   monitorapi.Intervals
+	artifactDir string
+}
+
+func (t *replayedMonitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	panic("started collection while replaying")
+}
+
+func (t *replayedMonitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	panic("collected data from the cluster while replaying")
+}
+
+func (t *replayedMonitorTest) CollectDataFromArtifacts(ctx context.Context, artifactDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	t.artifactDir = artifactDir
+	return t.collected, nil, nil
+}
+
+func (t *replayedMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	t.resources = recordedResources
+	return t.computed, nil
+}
+
+func (t *replayedMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	t.evaluated = finalIntervals
+	return []*junitapi.JUnitTestCase{{Name: "replayed test"}}, nil
+}
+
+func (t *replayedMonitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (t *replayedMonitorTest) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// statefulMonitorTest evaluates its tests with what it set up when starting the collection.
+type statefulMonitorTest struct {
+	adminRESTConfig *rest.Config
+}
+
+func (t *statefulMonitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	t.adminRESTConfig = adminRESTConfig
+	return nil
+}
+
+func (t *statefulMonitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	panic("collected data from the cluster while replaying")
+}
+
+func (t *statefulMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (t *statefulMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if t.adminRESTConfig == nil {
+		return nil, fmt.Errorf("collection was not started")
+	}
+	return nil, nil
+}
+
+func (t *statefulMonitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (t *statefulMonitorTest) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+func TestReplayMonitor(t *testing.T) {
+	artifactDir := t.TempDir()
+	runDir := filepath.Join(artifactDir, "e2e", "artifacts", "junit")
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	newInterval := func(message string, from, to time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("worker-0")).
+			Message(monitorapi.NewMessage().HumanMessage(message)).
+			Build(from, to)
+	}
+	newNodeState := func(message string, from, to time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceNodeState, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("worker-0")).
+			Message(monitorapi.NewMessage().HumanMessage(message)).
+			Build(from, to)
+	}
+	newComputed := func(message string, constructedBy monitorapi.ConstructionOwner, from, to time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("worker-0")).
+			Message(monitorapi.NewMessage().Constructed(constructedBy).HumanMessage(message)).
+			Build(from, to)
+	}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	stored := monitorapi.Intervals{
+		newInterval("stored", start, start.Add(time.Minute)),
+		newComputed("computed when the job ran", "replayed", start.Add(time.Minute), start.Add(time.Hour)),
+		newComputed("constructed when the job ran", monitorapi.ConstructionOwnerNodeLifecycle, start.Add(time.Minute), start.Add(time.Hour)),
+		newInterval("collected", start.Add(2*time.Minute), start.Add(3*time.Minute)),
+	}
+	if err := monitorserialization.EventsToFile(filepath.Join(runDir, "e2e-events_20240101-100000.json"), stored); err != nil {
+		t.Fatal(err)
+	}
+	pods := monitorapi.InstanceMap{
+		{Namespace: "ns", Name: "pod", UID: "uid"}: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod", UID: "uid"}},
+	}
+	if err := monitorserialization.InstanceMapToFile(filepath.Join(runDir, "resource-pods_20240101-100000.zip"), "pods", pods); err != nil {
+		t.Fatal(err)
+	}
+	configMaps := monitorapi.InstanceMap{
+		{Namespace: "ns", Name: "config", UID: "uid"}: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config", UID: "uid"}},
+	}
+	if err := monitorserialization.InstanceMapToFile(filepath.Join(runDir, "resource-configmaps_20240101-100000.zip"), "configmaps", configMaps); err != nil {
+		t.Fatal(err)
+	}
+	storedJunits, err := xml.Marshal(&junitapi.JUnitTestSuite{
+		Name: "openshift-tests",
+		TestCases: []*junitapi.JUnitTestCase{
+			{Name: `[Jira:"Test Framework"] monitor test replayed setup`, SystemOut: "stored"},
+			{Name: `[Jira:"Test Framework"] monitor test replayed collection`, SystemOut: "stored"},
+			{Name: `[Jira:"Test Framework"] monitor test stateful setup`, SystemOut: "stored"},
+			{Name: `[Jira:"Test Framework"] monitor test stateful collection`, SystemOut: "stored"},
+			{Name: `[Jira:"Test Framework"] monitor test stateful test evaluation`, SystemOut: "stored"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "e2e-monitor-tests__20240101-100000.xml"), storedJunits, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := FindReplayRuns(artifactDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	run := runs[0]
+	if run.TimeSuffix != "_20240101-100000" || len(run.Intervals) != 4 || len(run.JUnits) != 5 {
+		t.Errorf("unexpected run %s with %d intervals and %d junits", run.TimeSuffix, len(run.Intervals), len(run.JUnits))
+	}
+	if beginning, end := run.Bounds(); !beginning.Equal(start) || !end.Equal(start.Add(time.Hour)) {
+		t.Errorf("unexpected bounds %s - %s", beginning, end)
+	}
+	if pod, ok := run.Resources["pods"][monitorapi.InstanceKey{Namespace: "ns", Name: "pod", UID: "uid"}].(*corev1.Pod); !ok || pod.Name != "pod" {
+		t.Errorf("expected the recorded pod, got %#v", run.Resources["pods"])
+	}
+	if _, ok := run.Resources["configmaps"][monitorapi.InstanceKey{Namespace: "ns", Name: "config", UID: "uid"}].(*unstructured.Unstructured); !ok {
+		t.Errorf("expected the recorded config map, got %#v", run.Resources["configmaps"])
+	}
+
+	monitorTest := &replayedMonitorTest{
+		collected: monitorapi.Intervals{newInterval("collected", start.Add(2*time.Minute), start.Add(3*time.Minute))},
+		computed: monitorapi.Intervals{
+			newInterval("computed when the job ran", start.Add(time.Minute), start.Add(time.Hour)),
+			newInterval("computed by the change", start.Add(4*time.Minute), start.Add(5*time.Minute)),
+			newNodeState("node state computed again", start.Add(time.Minute), start.Add(30*time.Minute)),
+		},
+	}
+	registry := monitortestframework.NewMonitorTestRegistry()
+	registry.AddMonitorTestOrDie("replayed", "Test Framework", monitorTest)
+	registry.AddMonitorTestOrDie("stateful", "Test Framework", &statefulMonitorTest{})
+
+	storageDir := t.TempDir()
+	m, err := NewReplayMonitor(artifactDir, storageDir, run, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	resultState, err := m.Stop(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultState != Succeeded {
+		t.Errorf("expected the replay to succeed, got %s", resultState)
+	}
+	if monitorTest.artifactDir != artifactDir {
+		t.Errorf("expected data to be collected from %s, got %q", artifactDir, monitorTest.artifactDir)
+	}
+	if _, ok := monitorTest.resources["pods"]; !ok {
+		t.Errorf("expected the recorded resources to be replayed, got %v", monitorTest.resources)
+	}
+	messages := []string{}
+	for _, interval := range monitorTest.evaluated {
+		messages = append(messages, interval.Message.HumanMessage)
+		if interval.Message.HumanMessage == "computed by the change" && interval.Message.Annotations[monitorapi.AnnotationConstructed] != "replayed" {
+			t.Errorf("expected the computed interval to be annotated as constructed by its monitor test: %v", interval)
+		}
+	}
+	expected := []string{"stored", "node state computed again", "computed when the job ran", "collected", "computed by the change"}
+	if len(messages) != len(expected) {
+		t.Fatalf("expected intervals %q, got %q", expected, messages)
+	}
+	for i := range expected {
+		if messages[i] != expected[i] {
+			t.Errorf("expected intervals %q, got %q", expected, messages)
+			break
+		}
+	}
+
+	// the setup and collection junits are those of the stored run, except the collection of the artifact collectors,
+	// and the evaluation of the monitor test that needs its collection is skipped
+	junits := map[string]*junitapi.JUnitTestCase{}
+	for _, junit := range m.(*Monitor).junits {
+		junits[junit.Name] = junit
+	}
+	for name, expected := range map[string]string{
+		`[Jira:"Test Framework"] monitor test replayed setup`:           "stored",
+		`[Jira:"Test Framework"] monitor test replayed collection`:      "replayed",
+		`[Jira:"Test Framework"] monitor test stateful setup`:           "stored",
+		`[Jira:"Test Framework"] monitor test stateful collection`:      "stored",
+		`[Jira:"Test Framework"] monitor test stateful test evaluation`: "skipped",
+	} {
+		junit, ok := junits[name]
+		switch {
+		case !ok:
+			t.Errorf("expected a junit %s", name)
+		case expected == "stored" && junit.SystemOut != "stored",
+			expected == "replayed" && (junit.SystemOut == "stored" || junit.FailureOutput != nil || junit.SkipMessage != nil),
+			expected == "skipped" && junit.SkipMessage == nil:
+			t.Errorf("expected the junit %s to be %s, got %#v", name, expected, junit)
+		}
+	}
+
+	if err := m.SerializeResults(context.TODO(), "openshift-tests", run.TimeSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(storageDir, "e2e-monitor-tests__20240101-100000.xml")); err != nil {
+		t.Errorf("expected the junit to be written: %v", err)
+	}
+}
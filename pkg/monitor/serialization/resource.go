@@ -4,10 +4,14 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 
+	machine "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -73,3 +77,63 @@ func InstanceMapToFile(filename string, resourceType string, instances monitorap
 
 	return ioutil.WriteFile(filename, byteBuffer.Bytes(), 0644)
 }
+
+// typedResources are the resource types recorded by the monitor tests that are read back as typed objects, because
+// the monitor tests consuming them expect their types.  Other resource types are read back as unstructured objects.
+var typedResources = map[string]func() runtime.Object{
+	"events":     func() runtime.Object { return &corev1.Event{} },
+	"machines":   func() runtime.Object { return &machine.Machine{} },
+	"namespaces": func() runtime.Object { return &corev1.Namespace{} },
+	"pods":       func() runtime.Object { return &corev1.Pod{} },
+}
+
+// InstanceMapFromFile reads the instances written by InstanceMapToFile, keyed the way the recorder keys them.
+func InstanceMapFromFile(filename string, resourceType string) (monitorapi.InstanceMap, error) {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	instances := monitorapi.InstanceMap{}
+	for _, nsFile := range zipReader.File {
+		nsReader, err := nsFile.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(nsReader)
+		nsReader.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		// the lists are written without a kind, so they are not decoded as an UnstructuredList
+		nsItems := struct {
+			Items []map[string]interface{} `json:"items"`
+		}{}
+		if err := json.Unmarshal(data, &nsItems); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", nsFile.Name, err)
+		}
+		for _, item := range nsItems.Items {
+			var obj runtime.Object = &unstructured.Unstructured{Object: item}
+			if newObj, ok := typedResources[resourceType]; ok {
+				obj = newObj()
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item, obj); err != nil {
+					return nil, fmt.Errorf("unable to decode %s: %w", nsFile.Name, err)
+				}
+			}
+			metadata, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode %s: %w", nsFile.Name, err)
+			}
+			key := monitorapi.InstanceKey{
+				Namespace: metadata.GetNamespace(),
+				Name:      metadata.GetName(),
+				UID:       fmt.Sprintf("%v", metadata.GetUID()),
+			}
+			instances[key] = obj
+		}
+	}
+
+	return instances, nil
+}
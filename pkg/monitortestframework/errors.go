@@ -1,6 +1,9 @@
 package monitortestframework
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // NotSupportedError represents an error when a monitor test is unsupported for the given environment.
 type NotSupportedError struct {
@@ -19,3 +22,13 @@ type FlakeError struct {
 func (e *FlakeError) Error() string {
 	return fmt.Sprintf("test flake with error: %v", e.Err)
 }
+
+// TimeoutError represents an error when a monitor test did not finish a phase before its timeout.
+type TimeoutError struct {
+	Phase   Phase
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out in %v after %v", e.Phase, e.Timeout)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,11 +21,18 @@ import (
 
 type monitorTestRegistry struct {
 	monitorTests map[string]*monitorTesttItem
+
+	// notCollected are the monitor tests whose data was not collected again when replaying a finished run.
+	notCollected sets.String
+
+	usageLock sync.Mutex
+	usage     []PhaseUsage
 }
 
 type monitorTesttItem struct {
 	name          string
 	jiraComponent string
+	phaseTimeouts map[Phase]time.Duration
 
 	monitorTest MonitorTest
 }
@@ -35,21 +43,26 @@ func NewMonitorTestRegistry() MonitorTestRegistry {
 	}
 }
 
-func (r *monitorTestRegistry) AddMonitorTest(name, jiraComponent string, monitorTest MonitorTest) error {
+func (r *monitorTestRegistry) AddMonitorTest(name, jiraComponent string, monitorTest MonitorTest, opts ...MonitorTestOption) error {
 	if _, ok := r.monitorTests[name]; ok {
 		return fmt.Errorf("%q is already registered", name)
 	}
-	r.monitorTests[name] = &monitorTesttItem{
+	item := &monitorTesttItem{
 		name:          name,
 		jiraComponent: jiraComponent,
+		phaseTimeouts: map[Phase]time.Duration{},
 		monitorTest:   monitorTest,
 	}
+	for _, opt := range opts {
+		opt(item)
+	}
+	r.monitorTests[name] = item
 
 	return nil
 }
 
-func (r *monitorTestRegistry) AddMonitorTestOrDie(name, jiraComponent string, monitorTest MonitorTest) {
-	err := r.AddMonitorTest(name, jiraComponent, monitorTest)
+func (r *monitorTestRegistry) AddMonitorTestOrDie(name, jiraComponent string, monitorTest MonitorTest, opts ...MonitorTestOption) {
+	err := r.AddMonitorTest(name, jiraComponent, monitorTest, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -92,7 +105,14 @@ func (r *monitorTestRegistry) StartCollection(ctx context.Context, adminRESTConf
 			logrus.Infof("  Starting %v for %v", invariant.name, invariant.jiraComponent)
 
 			start := time.Now()
-			err := startCollectionWithPanicProtection(ctx, invariant.monitorTest, adminRESTConfig, recorder)
+			setupCtx, setupRecorder := newSetupRecorder(ctx, recorder)
+			_, err := runPhase(setupCtx, r, invariant, PhaseSetup, func(ctx context.Context) (struct{}, error) {
+				return struct{}{}, startCollectionWithPanicProtection(ctx, invariant.monitorTest, adminRESTConfig, setupRecorder)
+			})
+			var timeoutErr *TimeoutError
+			if errors.As(err, &timeoutErr) {
+				setupRecorder.abandon()
+			}
 			end := time.Now()
 			duration := end.Sub(start)
 			if err != nil {
@@ -109,12 +129,10 @@ func (r *monitorTestRegistry) StartCollection(ctx context.Context, adminRESTConf
 				}
 				errCh <- err
 				junitCh <- &junitapi.JUnitTestCase{
-					Name:     testName,
-					Duration: duration.Seconds(),
-					FailureOutput: &junitapi.FailureOutput{
-						Output: fmt.Sprintf("failed during setup\n%v", err),
-					},
-					SystemOut: fmt.Sprintf("failed during setup\n%v", err),
+					Name:          testName,
+					Duration:      duration.Seconds(),
+					FailureOutput: phaseFailure(PhaseSetup, err),
+					SystemOut:     fmt.Sprintf("failed during setup\n%v", err),
 				}
 				var flakeErr *FlakeError
 				if !errors.As(err, &flakeErr) {
@@ -147,13 +165,56 @@ func (r *monitorTestRegistry) StartCollection(ctx context.Context, adminRESTConf
 }
 
 func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return r.collectData(ctx, r.monitorTests, func(ctx context.Context, monitorTest MonitorTest) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+		return collectDataWithPanicProtection(ctx, monitorTest, storageDir, beginning, end)
+	})
+}
+
+func (r *monitorTestRegistry) CollectDataFromArtifacts(ctx context.Context, artifactDir string, storedJunits []*junitapi.JUnitTestCase, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	collectors := map[string]*monitorTesttItem{}
+	r.notCollected = sets.NewString()
+	for name, monitorTest := range r.monitorTests {
+		if _, ok := monitorTest.monitorTest.(ArtifactCollector); ok {
+			collectors[name] = monitorTest
+			continue
+		}
+		r.notCollected.Insert(name)
+	}
+
+	intervals, junits, err := r.collectData(ctx, collectors, func(ctx context.Context, monitorTest MonitorTest) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+		return collectDataFromArtifactsWithPanicProtection(ctx, monitorTest, artifactDir, beginning, end)
+	})
+
+	// the monitor tests are not set up again, and only the artifact collectors collect their data again, so the other
+	// setup and collection junits are those of the stored run
+	stored := map[string][]*junitapi.JUnitTestCase{}
+	for _, junit := range storedJunits {
+		stored[junit.Name] = append(stored[junit.Name], junit)
+	}
+	for _, name := range sets.StringKeySet(r.monitorTests).List() {
+		monitorTest := r.monitorTests[name]
+		junits = append(junits, stored[fmt.Sprintf("[Jira:%q] monitor test %v setup", monitorTest.jiraComponent, monitorTest.name)]...)
+		if r.notCollected.Has(name) {
+			junits = append(junits, stored[fmt.Sprintf("[Jira:%q] monitor test %v collection", monitorTest.jiraComponent, monitorTest.name)]...)
+		}
+	}
+	return intervals, junits, err
+}
+
+// collectData runs collect for the monitor tests concurrently and reports each collection as a junit.
+func (r *monitorTestRegistry) collectData(ctx context.Context, monitorTests map[string]*monitorTesttItem, collect func(context.Context, MonitorTest) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	type collected struct {
+		intervals monitorapi.Intervals
+		junits    []*junitapi.JUnitTestCase
+	}
+
 	wg := sync.WaitGroup{}
-	intervalsCh := make(chan monitorapi.Intervals, len(r.monitorTests))
-	junitCh := make(chan []*junitapi.JUnitTestCase, 3*len(r.monitorTests))
-	errCh := make(chan error, len(r.monitorTests))
+	intervalsCh := make(chan monitorapi.Intervals, len(monitorTests))
+	junitCh := make(chan []*junitapi.JUnitTestCase, 3*len(monitorTests))
+	errCh := make(chan error, len(monitorTests))
 
 	logrus.Infof("Starting CollectData for all monitor tests")
-	for i := range r.monitorTests {
+	for i := range monitorTests {
 		wg.Add(1)
 		go func(ctx context.Context, monitorTest *monitorTesttItem) {
 			defer wg.Done()
@@ -161,9 +222,12 @@ func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string
 
 			start := time.Now()
 			logrus.Infof("  Starting CollectData for %s", testName)
-			localIntervals, localJunits, err := collectDataWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, beginning, end)
-			intervalsCh <- localIntervals
-			junitCh <- localJunits
+			local, err := runPhase(ctx, r, monitorTest, PhaseCollection, func(ctx context.Context) (collected, error) {
+				intervals, junits, err := collect(ctx, monitorTest.monitorTest)
+				return collected{intervals: intervals, junits: junits}, err
+			})
+			intervalsCh <- local.intervals
+			junitCh <- local.junits
 			end := time.Now()
 			duration := end.Sub(start)
 			if err != nil {
@@ -183,12 +247,10 @@ func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string
 				}
 				junitCh <- []*junitapi.JUnitTestCase{
 					{
-						Name:     testName,
-						Duration: duration.Seconds(),
-						FailureOutput: &junitapi.FailureOutput{
-							Output: fmt.Sprintf("failed during collection\n%v", err),
-						},
-						SystemOut: fmt.Sprintf("failed during collection\n%v", err),
+						Name:          testName,
+						Duration:      duration.Seconds(),
+						FailureOutput: phaseFailure(PhaseCollection, err),
+						SystemOut:     fmt.Sprintf("failed during collection\n%v", err),
 					},
 				}
 				var flakeErr *FlakeError
@@ -205,7 +267,7 @@ func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string
 				},
 			}
 			logrus.Infof("  Finished CollectData for %s", testName)
-		}(ctx, r.monitorTests[i])
+		}(ctx, monitorTests[i])
 	}
 
 	wg.Wait()
@@ -231,64 +293,173 @@ func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string
 }
 
 func (r *monitorTestRegistry) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	dependencies, unordered := r.computedIntervalsDependencies()
+
+	lock := sync.Mutex{}
 	intervals := monitorapi.Intervals{}
 	junits := []*junitapi.JUnitTestCase{}
 	errs := []error{}
+	computedIntervals := map[string]monitorapi.Intervals{}
+	constructed := map[string]chan struct{}{}
+	for name := range r.monitorTests {
+		constructed[name] = make(chan struct{})
+	}
 
-	for _, monitorTest := range r.monitorTests {
+	wg := sync.WaitGroup{}
+	for name, monitorTest := range r.monitorTests {
 		testName := fmt.Sprintf("[Jira:%q] monitor test %v interval construction", monitorTest.jiraComponent, monitorTest.name)
 
-		start := time.Now()
-		localIntervals, err := constructComputedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, startingIntervals, recordedResources, beginning, end)
-		intervals = append(intervals, localIntervals...)
-		end := time.Now()
-		duration := end.Sub(start)
-		if err != nil {
-			var nsErr *NotSupportedError
-			if errors.As(err, &nsErr) {
+		if unordered.Has(name) {
+			err := fmt.Errorf("dependency cycle between the computed intervals of %v", strings.Join(unordered.List(), ", "))
+			lock.Lock()
+			errs = append(errs, err)
+			junits = append(junits, &junitapi.JUnitTestCase{
+				Name:          testName,
+				FailureOutput: phaseFailure(PhaseIntervalConstruction, err),
+				SystemOut:     fmt.Sprintf("failed during interval construction\n%v", err),
+			})
+			lock.Unlock()
+			close(constructed[name])
+			continue
+		}
+
+		wg.Add(1)
+		go func(ctx context.Context, name, testName string, monitorTest *monitorTesttItem) {
+			defer wg.Done()
+			defer close(constructed[name])
+
+			// every monitor test gets its own slice, so that sorting or replacing intervals does not race with the
+			// others.  The locator keys and annotations of the intervals are still shared.
+			localStartingIntervals := append(monitorapi.Intervals{}, startingIntervals...)
+			if len(dependencies[name]) > 0 {
+				for _, dependency := range dependencies[name] {
+					<-constructed[dependency]
+					lock.Lock()
+					localStartingIntervals = append(localStartingIntervals, computedIntervals[dependency]...)
+					lock.Unlock()
+				}
+				sort.Sort(localStartingIntervals)
+			}
+
+			start := time.Now()
+			localIntervals, err := runPhase(ctx, r, monitorTest, PhaseIntervalConstruction, func(ctx context.Context) (monitorapi.Intervals, error) {
+				return constructComputedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, localStartingIntervals, recordedResources, beginning, end)
+			})
+			duration := time.Since(start)
+			localIntervals = markConstructed(localIntervals, name)
+
+			lock.Lock()
+			defer lock.Unlock()
+			computedIntervals[name] = localIntervals
+			intervals = append(intervals, localIntervals...)
+			if err != nil {
+				var nsErr *NotSupportedError
+				if errors.As(err, &nsErr) {
+					junits = append(junits, &junitapi.JUnitTestCase{
+						Name:     testName,
+						Duration: duration.Seconds(),
+						SkipMessage: &junitapi.SkipMessage{
+							Message: nsErr.Reason,
+						},
+					})
+					return
+				}
+
+				errs = append(errs, err)
 				junits = append(junits, &junitapi.JUnitTestCase{
-					Name:     testName,
-					Duration: duration.Seconds(),
-					SkipMessage: &junitapi.SkipMessage{
-						Message: nsErr.Reason,
-					},
+					Name:          testName,
+					Duration:      duration.Seconds(),
+					FailureOutput: phaseFailure(PhaseIntervalConstruction, err),
+					SystemOut:     fmt.Sprintf("failed during interval construction\n%v", err),
 				})
-				continue
+				var flakeErr *FlakeError
+				if !errors.As(err, &flakeErr) {
+					return
+				}
 			}
 
-			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
 				Name:     testName,
 				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during interval construction\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during interval construction\n%v", err),
 			})
-			var flakeErr *FlakeError
-			if !errors.As(err, &flakeErr) {
+		}(ctx, name, testName, monitorTest)
+	}
+	wg.Wait()
+
+	return intervals, junits, utilerrors.NewAggregate(errs)
+}
+
+// markConstructed annotates the computed intervals without a constructor as constructed by the monitor test, so that
+// every computed interval can be told apart from the collected ones.  The annotations are copied, as computed intervals
+// may share them with the intervals they were computed from.
+func markConstructed(intervals monitorapi.Intervals, constructedBy string) monitorapi.Intervals {
+	for i := range intervals {
+		if len(intervals[i].Message.Annotations[monitorapi.AnnotationConstructed]) > 0 {
+			continue
+		}
+		annotations := make(map[monitorapi.AnnotationKey]string, len(intervals[i].Message.Annotations)+1)
+		for key, value := range intervals[i].Message.Annotations {
+			annotations[key] = value
+		}
+		annotations[monitorapi.AnnotationConstructed] = constructedBy
+		intervals[i].Message.Annotations = annotations
+	}
+	return intervals
+}
+
+// computedIntervalsDependencies returns the registered monitor tests every monitor test depends on for computed
+// intervals, and the monitor tests that cannot be ordered because they are part of, or depend on, a dependency cycle.
+func (r *monitorTestRegistry) computedIntervalsDependencies() (map[string][]string, sets.String) {
+	dependencies := map[string][]string{}
+	for name, monitorTest := range r.monitorTests {
+		dependent, ok := monitorTest.monitorTest.(ComputedIntervalsDependent)
+		if !ok {
+			continue
+		}
+		for _, dependency := range sets.NewString(dependent.ComputedIntervalsDependencies()...).List() {
+			if _, ok := r.monitorTests[dependency]; !ok {
+				logrus.Warningf("monitor test %v depends on the computed intervals of %v, which is not registered", name, dependency)
 				continue
 			}
+			dependencies[name] = append(dependencies[name], dependency)
 		}
-
-		junits = append(junits, &junitapi.JUnitTestCase{
-			Name:     testName,
-			Duration: duration.Seconds(),
-		})
 	}
 
-	return intervals, junits, utilerrors.NewAggregate(errs)
+	// order the monitor tests whose dependencies are all ordered until no more can be, the rest is in or behind a cycle
+	ordered := sets.NewString()
+	unordered := sets.StringKeySet(r.monitorTests)
+	for progress := true; progress; {
+		progress = false
+		for _, name := range unordered.List() {
+			if ordered.HasAll(dependencies[name]...) {
+				ordered.Insert(name)
+				unordered.Delete(name)
+				progress = true
+			}
+		}
+	}
+	return dependencies, unordered
 }
 
 func (r *monitorTestRegistry) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
 	junits := []*junitapi.JUnitTestCase{}
 	errs := []error{}
 
+	var finalIntervalSet *monitorapi.IntervalSet
+	for _, monitorTest := range r.monitorTests {
+		if _, ok := monitorTest.monitorTest.(IntervalSetEvaluator); ok {
+			finalIntervalSet = monitorapi.NewIntervalSet(finalIntervals)
+			break
+		}
+	}
+
 	for _, monitorTest := range r.monitorTests {
 		testName := fmt.Sprintf("[Jira:%q] monitor test %v test evaluation", monitorTest.jiraComponent, monitorTest.name)
 
 		start := time.Now()
-		localJunits, err := evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, finalIntervals)
+		localJunits, err := runPhase(ctx, r, monitorTest, PhaseTestEvaluation, func(ctx context.Context) ([]*junitapi.JUnitTestCase, error) {
+			return evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, finalIntervals, finalIntervalSet)
+		})
 		junits = append(junits, localJunits...)
 		end := time.Now()
 		duration := end.Sub(start)
@@ -307,12 +478,10 @@ func (r *monitorTestRegistry) EvaluateTestsFromConstructedIntervals(ctx context.
 
 			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
-				Name:     testName,
-				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during test evaluation\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during test evaluation\n%v", err),
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: phaseFailure(PhaseTestEvaluation, err),
+				SystemOut:     fmt.Sprintf("failed during test evaluation\n%v", err),
 			})
 			var flakeErr *FlakeError
 			if !errors.As(err, &flakeErr) {
@@ -346,7 +515,9 @@ func (r *monitorTestRegistry) WriteContentToStorage(ctx context.Context, storage
 			fmt.Fprintf(os.Stderr, "  last interval time: From = %s; To = %s\n", finalIntervals[finalIntervalLength-1].From, finalIntervals[finalIntervalLength-1].To)
 		}
 
-		err := writeContentToStorageWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, timeSuffix, finalIntervals, finalResourceState)
+		_, err := runPhase(ctx, r, monitorTest, PhaseWritingToStorage, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, writeContentToStorageWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, timeSuffix, finalIntervals, finalResourceState)
+		})
 		end := time.Now()
 		duration := end.Sub(start)
 		if err != nil {
@@ -364,12 +535,10 @@ func (r *monitorTestRegistry) WriteContentToStorage(ctx context.Context, storage
 
 			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
-				Name:     testName,
-				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during test evaluation\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during test evaluation\n%v", err),
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: phaseFailure(PhaseWritingToStorage, err),
+				SystemOut:     fmt.Sprintf("failed during writing to storage\n%v", err),
 			})
 			var flakeErr *FlakeError
 			if !errors.As(err, &flakeErr) {
@@ -396,7 +565,9 @@ func (r *monitorTestRegistry) Cleanup(ctx context.Context) ([]*junitapi.JUnitTes
 
 		start := time.Now()
 		log.Info("beginning cleanup")
-		err := cleanupWithPanicProtection(ctx, monitorTest.monitorTest)
+		_, err := runPhase(ctx, r, monitorTest, PhaseCleanup, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, cleanupWithPanicProtection(ctx, monitorTest.monitorTest)
+		})
 		end := time.Now()
 		duration := end.Sub(start)
 		if err != nil {
@@ -415,12 +586,10 @@ func (r *monitorTestRegistry) Cleanup(ctx context.Context) ([]*junitapi.JUnitTes
 			log.WithError(err).Error("failed during cleanup")
 			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
-				Name:     testName,
-				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during cleanup\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during cleanup\n%v", err),
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: phaseFailure(PhaseCleanup, err),
+				SystemOut:     fmt.Sprintf("failed during cleanup\n%v", err),
 			})
 			var flakeErr *FlakeError
 			if !errors.As(err, &flakeErr) {
@@ -439,7 +608,7 @@ func (r *monitorTestRegistry) Cleanup(ctx context.Context) ([]*junitapi.JUnitTes
 
 func (r *monitorTestRegistry) AddRegistryOrDie(registry MonitorTestRegistry) {
 	for _, v := range registry.getMonitorTests() {
-		r.AddMonitorTestOrDie(v.name, v.jiraComponent, v.monitorTest)
+		r.AddMonitorTestOrDie(v.name, v.jiraComponent, v.monitorTest, withPhaseTimeouts(v.phaseTimeouts))
 	}
 }
 
@@ -0,0 +1,190 @@
+package monitortestframework
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// computingMonitorTest computes one interval from its name, and records the intervals it was started from.
+type computingMonitorTest struct {
+	name         string
+	dependencies []string
+	// clobber replaces the starting intervals, which must not change those of the other monitor tests.
+	clobber bool
+
+	lock     sync.Mutex
+	starting monitorapi.Intervals
+}
+
+func (t *computingMonitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	return nil
+}
+
+func (t *computingMonitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return nil, nil, nil
+}
+
+func (t *computingMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.starting = append(monitorapi.Intervals{}, startingIntervals...)
+	if t.clobber {
+		for i := range startingIntervals {
+			startingIntervals[i] = monitorapi.Interval{}
+		}
+	}
+	return monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("node")).
+			Message(monitorapi.NewMessage().HumanMessage(t.name)).
+			Build(beginning, end),
+	}, nil
+}
+
+func (t *computingMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, nil
+}
+
+func (t *computingMonitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (t *computingMonitorTest) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+func (t *computingMonitorTest) ComputedIntervalsDependencies() []string {
+	return t.dependencies
+}
+
+func (t *computingMonitorTest) startingMessages() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	messages := []string{}
+	for _, interval := range t.starting {
+		messages = append(messages, interval.Message.HumanMessage)
+	}
+	return messages
+}
+
+func TestConstructComputedIntervalsDependencies(t *testing.T) {
+	monitorTests := map[string]*computingMonitorTest{
+		"raw":        {name: "raw", clobber: true},
+		"nodes":      {name: "nodes"},
+		"operators":  {name: "operators", dependencies: []string{"nodes"}},
+		"summary":    {name: "summary", dependencies: []string{"operators", "nodes", "disabled"}},
+		"cycle-a":    {name: "cycle-a", dependencies: []string{"cycle-b"}},
+		"cycle-b":    {name: "cycle-b", dependencies: []string{"cycle-a"}},
+		"after-loop": {name: "after-loop", dependencies: []string{"cycle-b", "nodes"}},
+	}
+	registry := NewMonitorTestRegistry()
+	for name, monitorTest := range monitorTests {
+		registry.AddMonitorTestOrDie(name, "Test Framework", monitorTest)
+	}
+
+	beginning := time.Now()
+	starting := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("node")).
+			Message(monitorapi.NewMessage().HumanMessage("starting")).
+			Build(beginning.Add(-time.Minute), beginning),
+	}
+	intervals, junits, err := registry.ConstructComputedIntervals(context.TODO(), starting, nil, beginning, beginning.Add(time.Hour))
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle between the computed intervals of after-loop, cycle-a, cycle-b") {
+		t.Errorf("expected a dependency cycle error, got %v", err)
+	}
+	if len(intervals) != 4 {
+		t.Errorf("expected the intervals of the 4 ordered monitor tests, got %d", len(intervals))
+	}
+
+	expectedStarting := map[string][]string{
+		"raw":       {"starting"},
+		"nodes":     {"starting"},
+		"operators": {"starting", "nodes"},
+		// the computed intervals are sorted with the starting ones
+		"summary":    {"starting", "nodes", "operators"},
+		"cycle-a":    {},
+		"cycle-b":    {},
+		"after-loop": {},
+	}
+	for name, expected := range expectedStarting {
+		got := monitorTests[name].startingMessages()
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("%s: expected to start from %q, got %q", name, expected, got)
+		}
+	}
+
+	failed := map[string]bool{}
+	for _, junit := range junits {
+		if junit.FailureOutput != nil {
+			failed[junit.Name] = true
+		}
+	}
+	for _, name := range []string{"cycle-a", "cycle-b", "after-loop"} {
+		if !failed[`[Jira:"Test Framework"] monitor test `+name+` interval construction`] {
+			t.Errorf("expected the interval construction of %s to fail", name)
+		}
+	}
+	if len(failed) != 3 {
+		t.Errorf("expected 3 failures, got %v", failed)
+	}
+}
+
+// intervalSetMonitorTest records the interval set its tests are evaluated from.
+type intervalSetMonitorTest struct {
+	computingMonitorTest
+
+	finalIntervalSet *monitorapi.IntervalSet
+}
+
+func (t *intervalSetMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, fmt.Errorf("expected the tests to be evaluated from the interval set")
+}
+
+func (t *intervalSetMonitorTest) EvaluateTestsFromIntervalSet(ctx context.Context, finalIntervals monitorapi.Intervals, finalIntervalSet *monitorapi.IntervalSet) ([]*junitapi.JUnitTestCase, error) {
+	t.finalIntervalSet = finalIntervalSet
+	return nil, nil
+}
+
+func TestEvaluateTestsFromIntervalSet(t *testing.T) {
+	first, second := &intervalSetMonitorTest{}, &intervalSetMonitorTest{}
+	registry := NewMonitorTestRegistry()
+	registry.AddMonitorTestOrDie("first", "Test Framework", first)
+	registry.AddMonitorTestOrDie("second", "Test Framework", second)
+	registry.AddMonitorTestOrDie("computing", "Test Framework", &computingMonitorTest{name: "computing"})
+
+	beginning := time.Now()
+	finalIntervals := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("node")).
+			Message(monitorapi.NewMessage().HumanMessage("final")).
+			Build(beginning, beginning.Add(time.Minute)),
+	}
+	junits, err := registry.EvaluateTestsFromConstructedIntervals(context.TODO(), finalIntervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, junit := range junits {
+		if junit.FailureOutput != nil {
+			t.Errorf("%s: unexpected failure %v", junit.Name, junit.FailureOutput.Output)
+		}
+	}
+	if first.finalIntervalSet == nil || first.finalIntervalSet != second.finalIntervalSet {
+		t.Fatalf("expected the monitor tests to share the final interval set, got %p and %p", first.finalIntervalSet, second.finalIntervalSet)
+	}
+	if got := first.finalIntervalSet.Query(monitorapi.QueryNodeUpdate()); len(got) != 0 {
+		t.Errorf("expected no node updates, got %v", got)
+	}
+	if got := first.finalIntervalSet.Intervals(); len(got) != 1 || got[0].Message.HumanMessage != "final" {
+		t.Errorf("expected the final intervals in the set, got %v", got)
+	}
+}
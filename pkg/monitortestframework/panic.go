@@ -39,6 +39,23 @@ func collectDataWithPanicProtection(ctx context.Context, monitortest MonitorTest
 	return
 }
 
+func collectDataFromArtifactsWithPanicProtection(ctx context.Context, monitortest MonitorTest, artifactDir string, beginning, end time.Time) (intervals monitorapi.Intervals, junit []*junitapi.JUnitTestCase, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic: %v", r)
+			logrus.Error("recovering from panic")
+			fmt.Print(debug.Stack())
+		}
+	}()
+
+	artifactCollector, ok := monitortest.(ArtifactCollector)
+	if !ok {
+		return nil, nil, &NotSupportedError{Reason: "collection is not replayed from artifacts"}
+	}
+	intervals, junit, err = artifactCollector.CollectDataFromArtifacts(ctx, artifactDir, beginning, end)
+	return
+}
+
 func constructComputedIntervalsWithPanicProtection(ctx context.Context, monitortest MonitorTest, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (intervals monitorapi.Intervals, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -52,7 +69,7 @@ func constructComputedIntervalsWithPanicProtection(ctx context.Context, monitort
 	return
 }
 
-func evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx context.Context, monitortest MonitorTest, finalIntervals monitorapi.Intervals) (junits []*junitapi.JUnitTestCase, err error) {
+func evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx context.Context, monitortest MonitorTest, finalIntervals monitorapi.Intervals, finalIntervalSet *monitorapi.IntervalSet) (junits []*junitapi.JUnitTestCase, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("caught panic: %v", r)
@@ -61,6 +78,10 @@ func evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx context.Contex
 		}
 	}()
 
+	if evaluator, ok := monitortest.(IntervalSetEvaluator); ok {
+		junits, err = evaluator.EvaluateTestsFromIntervalSet(ctx, finalIntervals, finalIntervalSet)
+		return
+	}
 	junits, err = monitortest.EvaluateTestsFromConstructedIntervals(ctx, finalIntervals)
 	return
 }
@@ -0,0 +1,226 @@
+package monitortestframework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// Phase is a step of the lifecycle of the monitor tests, named like the junit of the step.
+type Phase string
+
+const (
+	PhaseSetup                Phase = "setup"
+	PhaseCollection           Phase = "collection"
+	PhaseIntervalConstruction Phase = "interval construction"
+	PhaseTestEvaluation       Phase = "test evaluation"
+	PhaseWritingToStorage     Phase = "writing to storage"
+	PhaseCleanup              Phase = "cleanup"
+)
+
+var phases = []Phase{PhaseSetup, PhaseCollection, PhaseIntervalConstruction, PhaseTestEvaluation, PhaseWritingToStorage, PhaseCleanup}
+
+// MonitorTestOption configures how a registry runs a monitor test.
+type MonitorTestOption func(*monitorTesttItem)
+
+// WithPhaseTimeout sets how long the monitor test may take in a phase, zero for no timeout.  Phases have no timeout
+// unless set.  A monitor test that times out is reported as failed and abandoned, so that a single hung monitor test,
+// e.g. one streaming logs from an unreachable node, does not keep the monitor from stopping.
+func WithPhaseTimeout(phase Phase, timeout time.Duration) MonitorTestOption {
+	return func(item *monitorTesttItem) {
+		item.phaseTimeouts[phase] = timeout
+	}
+}
+
+// withPhaseTimeouts copies the timeouts of a monitor test registered in another registry.
+func withPhaseTimeouts(phaseTimeouts map[Phase]time.Duration) MonitorTestOption {
+	return func(item *monitorTesttItem) {
+		for phase, timeout := range phaseTimeouts {
+			item.phaseTimeouts[phase] = timeout
+		}
+	}
+}
+
+func (i *monitorTesttItem) phaseTimeout(phase Phase) time.Duration {
+	return i.phaseTimeouts[phase]
+}
+
+// PhaseUsage is what a monitor test used in a phase.
+type PhaseUsage struct {
+	MonitorTest string  `json:"monitorTest"`
+	Phase       Phase   `json:"phase"`
+	Seconds     float64 `json:"seconds"`
+	TimedOut    bool    `json:"timedOut,omitempty"`
+}
+
+// runPhase runs a phase of a monitor test, at most for the timeout of the phase, and records what it used.  The context
+// of the phase is canceled at the timeout, except for setup, whose context must outlive it for collection to continue,
+// see setupRecorder.  A monitor test that does not return by the timeout is abandoned and a TimeoutError is returned.
+// When replaying a run, the errors of monitor tests that did not collect their data again are NotSupportedErrors.
+func runPhase[T any](ctx context.Context, r *monitorTestRegistry, monitorTest *monitorTesttItem, phase Phase, fn func(context.Context) (T, error)) (T, error) {
+	start := time.Now()
+
+	value, err := runWithTimeout(ctx, phase, monitorTest.phaseTimeout(phase), fn)
+
+	var timeoutErr *TimeoutError
+	r.recordUsage(PhaseUsage{
+		MonitorTest: monitorTest.name,
+		Phase:       phase,
+		Seconds:     time.Since(start).Seconds(),
+		TimedOut:    errors.As(err, &timeoutErr),
+	})
+	if err != nil && phase != PhaseCollection && r.notCollected.Has(monitorTest.name) {
+		var nsErr *NotSupportedError
+		if !errors.As(err, &nsErr) {
+			err = &NotSupportedError{Reason: fmt.Sprintf("cannot be replayed without collecting its data: %v", err)}
+		}
+	}
+	return value, err
+}
+
+func runWithTimeout[T any](ctx context.Context, phase Phase, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	phaseCtx := ctx
+	if phase != PhaseSetup {
+		var cancel context.CancelFunc
+		phaseCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		value, err := fn(phaseCtx)
+		resultCh <- result{value: value, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-resultCh:
+		// monitor tests honoring the context fail with its error shortly before the timer fires
+		if r.err != nil && errors.Is(phaseCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return r.value, &TimeoutError{Phase: phase, Timeout: timeout}
+		}
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, &TimeoutError{Phase: phase, Timeout: timeout}
+	}
+}
+
+// setupRecorder is the recorder the setup of a monitor test records to, with the context of the setup.  Once the setup
+// is abandoned, the context is canceled and everything the monitor test records is dropped.
+type setupRecorder struct {
+	monitorapi.RecorderWriter
+
+	cancel    context.CancelFunc
+	abandoned atomic.Bool
+}
+
+func newSetupRecorder(ctx context.Context, recorder monitorapi.RecorderWriter) (context.Context, *setupRecorder) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, &setupRecorder{RecorderWriter: recorder, cancel: cancel}
+}
+
+func (r *setupRecorder) abandon() {
+	r.abandoned.Store(true)
+	r.cancel()
+}
+
+func (r *setupRecorder) RecordResource(resourceType string, obj kruntime.Object) {
+	if !r.abandoned.Load() {
+		r.RecorderWriter.RecordResource(resourceType, obj)
+	}
+}
+
+func (r *setupRecorder) Record(conditions ...monitorapi.Condition) {
+	if !r.abandoned.Load() {
+		r.RecorderWriter.Record(conditions...)
+	}
+}
+
+func (r *setupRecorder) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
+	if !r.abandoned.Load() {
+		r.RecorderWriter.RecordAt(t, conditions...)
+	}
+}
+
+func (r *setupRecorder) AddIntervals(eventIntervals ...monitorapi.Interval) {
+	if !r.abandoned.Load() {
+		r.RecorderWriter.AddIntervals(eventIntervals...)
+	}
+}
+
+func (r *setupRecorder) StartInterval(interval monitorapi.Interval) int {
+	if r.abandoned.Load() {
+		return -1
+	}
+	return r.RecorderWriter.StartInterval(interval)
+}
+
+func (r *setupRecorder) EndInterval(startedInterval int, t time.Time) *monitorapi.Interval {
+	if r.abandoned.Load() || startedInterval < 0 {
+		return nil
+	}
+	return r.RecorderWriter.EndInterval(startedInterval, t)
+}
+
+func (r *monitorTestRegistry) recordUsage(usage PhaseUsage) {
+	r.usageLock.Lock()
+	defer r.usageLock.Unlock()
+	r.usage = append(r.usage, usage)
+}
+
+// WriteUsage writes what every monitor test used in every phase, ordered by monitor test and phase.
+func (r *monitorTestRegistry) WriteUsage(storageDir, timeSuffix string) error {
+	r.usageLock.Lock()
+	usage := append([]PhaseUsage{}, r.usage...)
+	r.usageLock.Unlock()
+
+	phaseOrder := map[Phase]int{}
+	for i, phase := range phases {
+		phaseOrder[phase] = i
+	}
+	sort.SliceStable(usage, func(i, j int) bool {
+		if usage[i].MonitorTest != usage[j].MonitorTest {
+			return usage[i].MonitorTest < usage[j].MonitorTest
+		}
+		return phaseOrder[usage[i].Phase] < phaseOrder[usage[j].Phase]
+	})
+
+	data, err := json.MarshalIndent(usage, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(storageDir, fmt.Sprintf("monitor-test-usage%s.json", timeSuffix)), data, 0644)
+}
+
+// phaseFailure returns the failure of a monitor test that failed a phase, telling timeouts apart.
+func phaseFailure(phase Phase, err error) *junitapi.FailureOutput {
+	failure := &junitapi.FailureOutput{
+		Output: fmt.Sprintf("failed during %v\n%v", phase, err),
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		failure.Message = timeoutErr.Error()
+	}
+	return failure
+}
@@ -0,0 +1,162 @@
+package monitortestframework
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// hangingMonitorTest never finishes collecting, waits for its context to be done to finish setting up and evaluating,
+// and records an interval when its setup is done.
+type hangingMonitorTest struct {
+	release chan struct{}
+	setUp   chan struct{}
+}
+
+func (t *hangingMonitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	defer close(t.setUp)
+	<-ctx.Done()
+	recorder.AddIntervals(monitorapi.Interval{})
+	return ctx.Err()
+}
+
+func (t *hangingMonitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	<-t.release
+	return nil, nil, nil
+}
+
+func (t *hangingMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (t *hangingMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (t *hangingMonitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (t *hangingMonitorTest) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// countingRecorder counts the intervals added to it.
+type countingRecorder struct {
+	monitorapi.RecorderWriter
+
+	lock  sync.Mutex
+	added int
+}
+
+func (r *countingRecorder) AddIntervals(eventIntervals ...monitorapi.Interval) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.added += len(eventIntervals)
+}
+
+func TestPhaseTimeouts(t *testing.T) {
+	hanging := &hangingMonitorTest{release: make(chan struct{}), setUp: make(chan struct{})}
+	defer close(hanging.release)
+
+	registry := NewMonitorTestRegistry()
+	registry.AddMonitorTestOrDie("hanging", "Test Framework", hanging,
+		WithPhaseTimeout(PhaseSetup, 10*time.Millisecond),
+		WithPhaseTimeout(PhaseCollection, 10*time.Millisecond),
+		WithPhaseTimeout(PhaseTestEvaluation, 10*time.Millisecond),
+	)
+	// the timeouts are kept when the monitor test is added to another registry
+	registry, err := registry.GetRegistryFor("hanging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined := NewMonitorTestRegistry()
+	combined.AddRegistryOrDie(registry)
+	combined.AddMonitorTestOrDie("computing", "Test Framework", &computingMonitorTest{name: "computing"})
+
+	recorder := &countingRecorder{}
+	junits, err := combined.StartCollection(context.TODO(), nil, recorder)
+	if err == nil {
+		t.Errorf("expected the setup to time out")
+	}
+	// the abandoned setup is canceled, and what it records after is dropped
+	<-hanging.setUp
+	if recorder.added != 0 {
+		t.Errorf("expected the intervals of the abandoned setup to be dropped, got %d", recorder.added)
+	}
+
+	_, collectionJunits, err := combined.CollectData(context.TODO(), t.TempDir(), time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTimedOut := func(junits []*junitapi.JUnitTestCase, testName, message string) {
+		t.Helper()
+		for _, junit := range junits {
+			if junit.Name != testName {
+				continue
+			}
+			if junit.FailureOutput == nil || junit.FailureOutput.Message != message {
+				t.Errorf("expected %s to time out, got %#v", testName, junit.FailureOutput)
+			}
+			return
+		}
+		t.Errorf("missing %s", testName)
+	}
+	assertTimedOut(junits, `[Jira:"Test Framework"] monitor test hanging setup`, "timed out in setup after 10ms")
+	assertTimedOut(collectionJunits, `[Jira:"Test Framework"] monitor test hanging collection`, "timed out in collection after 10ms")
+
+	junits, err = combined.EvaluateTestsFromConstructedIntervals(context.TODO(), nil)
+	if err == nil {
+		t.Errorf("expected the evaluation to time out")
+	}
+	assertTimedOut(junits, `[Jira:"Test Framework"] monitor test hanging test evaluation`, "timed out in test evaluation after 10ms")
+
+	storageDir := t.TempDir()
+	if _, err := combined.WriteContentToStorage(context.TODO(), storageDir, "_suffix", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := combined.Cleanup(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err := combined.WriteUsage(storageDir, "_suffix"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(storageDir, "monitor-test-usage_suffix.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := []PhaseUsage{}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		t.Fatal(err)
+	}
+	expected := []PhaseUsage{
+		{MonitorTest: "computing", Phase: PhaseSetup},
+		{MonitorTest: "computing", Phase: PhaseCollection},
+		{MonitorTest: "computing", Phase: PhaseTestEvaluation},
+		{MonitorTest: "computing", Phase: PhaseWritingToStorage},
+		{MonitorTest: "computing", Phase: PhaseCleanup},
+		{MonitorTest: "hanging", Phase: PhaseSetup, TimedOut: true},
+		{MonitorTest: "hanging", Phase: PhaseCollection, TimedOut: true},
+		{MonitorTest: "hanging", Phase: PhaseTestEvaluation, TimedOut: true},
+		{MonitorTest: "hanging", Phase: PhaseWritingToStorage},
+		{MonitorTest: "hanging", Phase: PhaseCleanup},
+	}
+	if len(usage) != len(expected) {
+		t.Fatalf("expected %d usages, got %#v", len(expected), usage)
+	}
+	for i := range expected {
+		if usage[i].MonitorTest != expected[i].MonitorTest || usage[i].Phase != expected[i].Phase || usage[i].TimedOut != expected[i].TimedOut {
+			t.Errorf("expected usage %d to be %#v, got %#v", i, expected[i], usage[i])
+		}
+	}
+}
@@ -48,7 +48,10 @@ type MonitorTest interface {
 	CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
 
 	// ConstructComputedIntervals is called after all InvariantTests have produced raw Intervals.
-	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed.
+	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed, unless the
+	// InvariantTest is a ComputedIntervalsDependent, and they run concurrently.  startingIntervals is a copy
+	// of the slice for every InvariantTest, but the intervals share their locator keys and annotations, and
+	// recordedResources is shared: DeepCopy an interval or a resource before modifying it.
 	// Return *only* the constructed intervals.
 	// Errors reported will be indicated as junit test failure and will cause job runs to fail.
 	ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (constructedIntervals monitorapi.Intervals, err error)
@@ -74,14 +77,40 @@ type MonitorTest interface {
 	Cleanup(ctx context.Context) error
 }
 
+// ComputedIntervalsDependent is implemented by the monitor tests whose ConstructComputedIntervals needs the intervals
+// computed by other monitor tests, e.g. the node states computed by node-state-analyzer.
+type ComputedIntervalsDependent interface {
+	// ComputedIntervalsDependencies returns the names the monitor tests are registered with whose computed intervals are
+	// added to the startingIntervals of ConstructComputedIntervals.  Their ConstructComputedIntervals is called first.
+	// Dependencies missing from the registry are ignored, dependency cycles fail interval construction.
+	ComputedIntervalsDependencies() []string
+}
+
+// IntervalSetEvaluator is implemented by the monitor tests that look up the final intervals many times, e.g. per
+// backend or per namespace.  The registry indexes the final intervals once for all of them.
+type IntervalSetEvaluator interface {
+	// EvaluateTestsFromIntervalSet is called instead of EvaluateTestsFromConstructedIntervals, with the final intervals
+	// and an IntervalSet of them shared with the other IntervalSetEvaluators.
+	EvaluateTestsFromIntervalSet(ctx context.Context, finalIntervals monitorapi.Intervals, finalIntervalSet *monitorapi.IntervalSet) ([]*junitapi.JUnitTestCase, error)
+}
+
+// ArtifactCollector is implemented by the monitor tests that can collect their data from the artifacts of a
+// finished run instead of from the cluster, so that they can be replayed without a cluster.
+type ArtifactCollector interface {
+	// CollectDataFromArtifacts is the equivalent of CollectData for the artifacts of a finished run stored in
+	// artifactDir.  StartCollection is not called before it, so the cluster must not be used.
+	CollectDataFromArtifacts(ctx context.Context, artifactDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
+}
+
 type MonitorTestRegistry interface {
 	AddRegistryOrDie(registry MonitorTestRegistry)
 
 	// AddMonitorTest adds an invariant test with a particular name, the name will be used to create a testsuite.
 	// The jira component will be forced into every JunitTestCase.
-	AddMonitorTest(name, jiraComponent string, monitorTest MonitorTest) error
+	// The phases of the invariant test have no timeout unless set WithPhaseTimeout.
+	AddMonitorTest(name, jiraComponent string, monitorTest MonitorTest, opts ...MonitorTestOption) error
 
-	AddMonitorTestOrDie(name, jiraComponent string, monitorTest MonitorTest)
+	AddMonitorTestOrDie(name, jiraComponent string, monitorTest MonitorTest, opts ...MonitorTestOption)
 
 	GetRegistryFor(names ...string) (MonitorTestRegistry, error)
 	ListMonitorTests() sets.String
@@ -95,9 +124,16 @@ type MonitorTestRegistry interface {
 	// Errors reported will be indicated as junit test failure and will cause job runs to fail.
 	CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
 
+	// CollectDataFromArtifacts replaces CollectData when replaying the artifacts of a finished run stored in artifactDir.
+	// Only the ArtifactCollectors collect their data again.  The setup junits, and the collection junits of the other
+	// monitor tests, are those of storedJunits, the junits of the stored run.  The later phases of the other monitor
+	// tests may need the state of the collection they did not run, their errors are reported as skipped.
+	CollectDataFromArtifacts(ctx context.Context, artifactDir string, storedJunits []*junitapi.JUnitTestCase, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
+
 	// ConstructComputedIntervals is called after all InvariantTests have produced raw Intervals.
-	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed.
-	// Return *only* the constructed intervals.
+	// InvariantTests run concurrently, each after the InvariantTests it depends on as a ComputedIntervalsDependent.
+	// Return *only* the constructed intervals.  Those without an AnnotationConstructed are annotated as constructed
+	// by the InvariantTest.
 	// Errors reported will be indicated as junit test failure and will cause job runs to fail.
 	ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
 
@@ -120,5 +156,9 @@ type MonitorTestRegistry interface {
 	// Errors reported will cause job runs to fail to ensure cleanup functions work reliably.
 	Cleanup(ctx context.Context) ([]*junitapi.JUnitTestCase, error)
 
+	// WriteUsage writes the time and memory every invariant test used in every phase so far to
+	// monitor-test-usage<timeSuffix>.json.  It is called last, after Cleanup, so that every phase is included.
+	WriteUsage(storageDir, timeSuffix string) error
+
 	getMonitorTests() map[string]*monitorTesttItem
 }
@@ -105,6 +105,7 @@ func createDisruptionJunit(
 	disruptionDetails string,
 	locator monitorapi.Locator,
 	disruptedIntervals monitorapi.Intervals,
+	upgradeHops []platformidentification.UpgradeHop,
 	jobType *platformidentification.JobType) *junitapi.JUnitTestCase {
 
 	// Not sure what these are, but this will help find them, and we don't get any value from testing these:
@@ -159,9 +160,11 @@ func createDisruptionJunit(
 	roundedFinal := int64(math.Round(allowedSecsWithGrace))
 	finalAllowedDisruption := time.Duration(roundedFinal) * time.Second
 
+	byHop := disruptionByUpgradeHop(disruptedIntervals, upgradeHops)
 	if roundedDisruptionDuration <= finalAllowedDisruption {
 		return &junitapi.JUnitTestCase{
-			Name: testName,
+			Name:      testName,
+			SystemOut: byHop,
 		}
 	}
 
@@ -171,6 +174,9 @@ func createDisruptionJunit(
 		roundedDisruptionDuration, finalAllowedDisruption,
 		strings.Join(allowedDetails, "\n"),
 		strings.Join(describe, "\n"))
+	if len(byHop) > 0 {
+		failureMessage += "\n\n" + byHop
+	}
 
 	return &junitapi.JUnitTestCase{
 		Name: testName,
@@ -181,6 +187,27 @@ func createDisruptionJunit(
 	}
 }
 
+// disruptionByUpgradeHop describes how long the backend was disrupted during each hop of a multi-hop
+// upgrade, so that disruption is attributed to the hop it happened in.
+func disruptionByUpgradeHop(disruptedIntervals monitorapi.Intervals, upgradeHops []platformidentification.UpgradeHop) string {
+	if len(upgradeHops) == 0 {
+		return ""
+	}
+	byHop := map[string]monitorapi.Intervals{}
+	for _, interval := range disruptedIntervals {
+		hop := platformidentification.UpgradeHopAt(upgradeHops, interval.From)
+		byHop[hop] = append(byHop[hop], interval)
+	}
+	lines := []string{"Disruption by upgrade hop:"}
+	for _, hop := range upgradeHops {
+		lines = append(lines, fmt.Sprintf("hop %s: %s", hop.Hop, byHop[hop.Hop].Duration(1*time.Second).Round(time.Second)))
+	}
+	if outside := byHop[""]; len(outside) > 0 {
+		lines = append(lines, fmt.Sprintf("outside of the upgrade: %s", outside.Duration(1*time.Second).Round(time.Second)))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (w *Availability) junitForNewConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) (*junitapi.JUnitTestCase, error) {
 	newConnectionAllowed, newConnectionDisruptionDetails, err := historicalAllowedDisruption(ctx, w.newConnectionDisruptionSampler, jobType)
 	if err != nil {
@@ -194,6 +221,7 @@ func (w *Availability) junitForNewConnections(ctx context.Context, finalInterval
 					monitorapi.IsErrorEvent,
 				),
 			),
+			platformidentification.UpgradeHops(finalIntervals),
 			jobType,
 		),
 		nil
@@ -212,6 +240,7 @@ func (w *Availability) junitForReusedConnections(ctx context.Context, finalInter
 					monitorapi.IsErrorEvent,
 				),
 			),
+			platformidentification.UpgradeHops(finalIntervals),
 			jobType,
 		),
 		nil
@@ -20,3 +20,45 @@ func DidUpgradeHappenDuringCollection(intervals monitorapi.Intervals, beginning,
 	}
 	return false
 }
+
+// UpgradeHop is the window of one hop of a multi-hop upgrade, from the event that started it to the
+// event that completed or failed it. To is zero if the hop did not end during the collection.
+type UpgradeHop struct {
+	Hop  string
+	From time.Time
+	To   time.Time
+}
+
+// UpgradeHops returns the hops of a multi-hop upgrade from the cluster version events tagged with
+// their hop. Single hop upgrades are not tagged, so they have no hops.
+func UpgradeHops(intervals monitorapi.Intervals) []UpgradeHop {
+	var hops []UpgradeHop
+	for _, event := range intervals {
+		if event.Source != monitorapi.SourceKubeEvent || event.Locator.Keys[monitorapi.LocatorClusterVersionKey] != "cluster" {
+			continue
+		}
+		hop, ok := event.Message.Annotations[monitorapi.AnnotationUpgradeHop]
+		if !ok {
+			continue
+		}
+		switch event.Message.Reason {
+		case monitorapi.UpgradeStartedReason:
+			hops = append(hops, UpgradeHop{Hop: hop, From: event.From})
+		case monitorapi.UpgradeCompleteReason, monitorapi.UpgradeFailedReason:
+			if last := len(hops) - 1; last >= 0 && hops[last].Hop == hop && hops[last].To.IsZero() {
+				hops[last].To = event.From
+			}
+		}
+	}
+	return hops
+}
+
+// UpgradeHopAt returns the hop in progress at t, or an empty string if no hop was.
+func UpgradeHopAt(hops []UpgradeHop, t time.Time) string {
+	for _, hop := range hops {
+		if !t.Before(hop.From) && (hop.To.IsZero() || t.Before(hop.To)) {
+			return hop.Hop
+		}
+	}
+	return ""
+}
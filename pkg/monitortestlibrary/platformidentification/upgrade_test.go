@@ -0,0 +1,42 @@
+package platformidentification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestUpgradeHops(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(minute int, reason monitorapi.IntervalReason, hop string) monitorapi.Interval {
+		message := monitorapi.NewMessage().Reason(reason).HumanMessage("hop/" + hop + " version/4.16.3")
+		if len(hop) > 0 {
+			message = message.WithAnnotation(monitorapi.AnnotationUpgradeHop, hop)
+		}
+		at := start.Add(time.Duration(minute) * time.Minute)
+		return monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Info).
+			Locator(monitorapi.Locator{Keys: map[monitorapi.LocatorKey]string{monitorapi.LocatorClusterVersionKey: "cluster"}}).
+			Message(message).Build(at, at)
+	}
+
+	hops := UpgradeHops(monitorapi.Intervals{
+		event(0, monitorapi.UpgradeStartedReason, "1"),
+		event(40, monitorapi.UpgradeVersionReason, "1"),
+		event(50, monitorapi.UpgradeCompleteReason, "1"),
+		event(55, monitorapi.UpgradeStartedReason, "2"),
+	})
+	if len(hops) != 2 || hops[0].Hop != "1" || !hops[0].To.Equal(start.Add(50*time.Minute)) || hops[1].Hop != "2" || !hops[1].To.IsZero() {
+		t.Fatalf("unexpected hops %#v", hops)
+	}
+
+	for minute, expected := range map[int]string{0: "1", 49: "1", 52: "", 55: "2", 200: "2"} {
+		if got := UpgradeHopAt(hops, start.Add(time.Duration(minute)*time.Minute)); got != expected {
+			t.Errorf("expected hop %q at minute %d, got %q", expected, minute, got)
+		}
+	}
+
+	if hops := UpgradeHops(monitorapi.Intervals{event(0, monitorapi.UpgradeStartedReason, "")}); len(hops) != 0 {
+		t.Errorf("expected no hops for a single hop upgrade, got %#v", hops)
+	}
+}
@@ -0,0 +1,98 @@
+package auditloganalyzer
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// GetKubeAuditLogSummaryFromArtifacts is the equivalent of GetKubeAuditLogSummary for the audit logs gathered
+// into the artifacts of a run: every audit*.log and audit*.log.gz file below a kube-apiserver directory of artifactDir.
+func GetKubeAuditLogSummaryFromArtifacts(ctx context.Context, artifactDir string, beginning, end *time.Time, auditLogHandlers []AuditEventHandler) error {
+	auditLogFilenames, err := findKubeAuditLogs(artifactDir)
+	if err != nil {
+		return err
+	}
+
+	var microBeginning, microEnd *metav1.MicroTime
+	if nil != beginning {
+		micro := metav1.NewMicroTime(*beginning)
+		microBeginning = &micro
+	}
+	if nil != end {
+		micro := metav1.NewMicroTime(*end)
+		microEnd = &micro
+	}
+
+	wg := sync.WaitGroup{}
+	errCh := make(chan error, len(auditLogFilenames))
+	for _, auditLogFilename := range auditLogFilenames {
+		wg.Add(1)
+		go func(ctx context.Context, auditLogFilename string) {
+			defer wg.Done()
+			if err := handleAuditLogFile(auditLogFilename, microBeginning, microEnd, auditLogHandlers); err != nil {
+				errCh <- err
+			}
+		}(ctx, auditLogFilename)
+	}
+	wg.Wait()
+	close(errCh)
+
+	errs := []error{}
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func findKubeAuditLogs(artifactDir string) ([]string, error) {
+	auditLogFilenames := []string{}
+	err := filepath.WalkDir(artifactDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Base(filepath.Dir(path)) != "kube-apiserver" {
+			return nil
+		}
+		if !strings.HasPrefix(d.Name(), "audit") {
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".log") && !strings.HasSuffix(d.Name(), ".log.gz") {
+			return nil
+		}
+		auditLogFilenames = append(auditLogFilenames, path)
+		return nil
+	})
+	return auditLogFilenames, err
+}
+
+func handleAuditLogFile(auditLogFilename string, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) error {
+	auditFile, err := os.Open(auditLogFilename)
+	if err != nil {
+		return err
+	}
+	defer auditFile.Close()
+
+	var auditStream io.Reader = auditFile
+	if strings.HasSuffix(auditLogFilename, ".gz") {
+		gzipReader, err := gzip.NewReader(auditFile)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		auditStream = gzipReader
+	}
+
+	handleAuditLogStream(auditLogFilename, auditStream, beginning, end, auditLogHandlers)
+	return nil
+}
@@ -147,6 +147,17 @@ func (w *auditLogAnalyzer) CollectData(ctx context.Context, storageDir string, b
 	return retIntervals, nil, err
 }
 
+// CollectDataFromArtifacts summarizes the gathered audit logs.  The request counts need the creation time of the
+// cluster and are not replayed, their intervals are already part of the stored intervals of the run.
+func (w *auditLogAnalyzer) CollectDataFromArtifacts(ctx context.Context, artifactDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	auditLogHandlers := []AuditEventHandler{
+		w.summarizer,
+		w.excessiveApplyChecker,
+		w.invalidRequestsChecker,
+	}
+	return nil, nil, GetKubeAuditLogSummaryFromArtifacts(ctx, artifactDir, &beginning, &end, auditLogHandlers)
+}
+
 func (*auditLogAnalyzer) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
 	return nil, nil
 }
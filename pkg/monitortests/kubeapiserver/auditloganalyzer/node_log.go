@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -108,27 +109,7 @@ func getAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeNa
 				return
 			}
 
-			scanner := bufio.NewScanner(auditStream)
-			line := 0
-			for scanner.Scan() {
-				line++
-				auditLine := scanner.Bytes()
-
-				if len(auditLine) == 0 {
-					continue
-				}
-
-				auditEvent := &auditv1.Event{}
-				if err := json.Unmarshal(auditLine, auditEvent); err != nil {
-					fmt.Printf("unable to decode %q line %d: %s to audit event: %v\n", auditLogFilename, line, string(auditLine), err)
-					continue
-				}
-
-				for _, auditLogHandler := range auditLogHandlers {
-					auditLogHandler.HandleAuditLogEvent(auditEvent, beginning, end)
-				}
-			}
-
+			handleAuditLogStream(auditLogFilename, auditStream, beginning, end, auditLogHandlers)
 		}(ctx, auditLogFilename)
 	}
 	wg.Wait()
@@ -142,6 +123,30 @@ func getAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeNa
 	return utilerrors.NewAggregate(errs)
 }
 
+// handleAuditLogStream decodes every audit event of an audit log and passes it to the handlers.
+func handleAuditLogStream(auditLogFilename string, auditStream io.Reader, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) {
+	scanner := bufio.NewScanner(auditStream)
+	line := 0
+	for scanner.Scan() {
+		line++
+		auditLine := scanner.Bytes()
+
+		if len(auditLine) == 0 {
+			continue
+		}
+
+		auditEvent := &auditv1.Event{}
+		if err := json.Unmarshal(auditLine, auditEvent); err != nil {
+			fmt.Printf("unable to decode %q line %d: %s to audit event: %v\n", auditLogFilename, line, string(auditLine), err)
+			continue
+		}
+
+		for _, auditLogHandler := range auditLogHandlers {
+			auditLogHandler.HandleAuditLogEvent(auditEvent, beginning, end)
+		}
+	}
+}
+
 func getAuditLogFilenames(ctx context.Context, client kubernetes.Interface, nodeName, apiserverName string) ([]string, error) {
 	allBytes, err := nodeaccess.GetNodeLogFile(ctx, client, nodeName, apiserverName)
 	if err != nil {
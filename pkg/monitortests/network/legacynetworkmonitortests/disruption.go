@@ -11,15 +11,15 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/sets"
 )
 
-func TestMultipleSingleSecondDisruptions(events monitorapi.Intervals) []*junitapi.JUnitTestCase {
+func TestMultipleSingleSecondDisruptions(events *monitorapi.IntervalSet) []*junitapi.JUnitTestCase {
 	// multipleFailuresTestPrefix is for tests that track a few single second disruptions
 	const multipleFailuresTestPrefix = "[sig-network] there should be nearly zero single second disruptions for "
 	// manyFailureTestPrefix is for tests that track a lot of single second disruptions (more severe than the above)
 	const manyFailureTestPrefix = "[sig-network] there should be reasonably few single second disruptions for "
 
 	allServers := sets.String{}
-	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
-	logrus.Infof("filtered %d intervals down to %d disruption intervals", len(events), len(allDisruptionEventsIntervals))
+	allDisruptionEventsIntervals := events.Query(monitorapi.QueryDisruptionEvent())
+	logrus.Infof("filtered %d intervals down to %d disruption intervals", events.Len(), len(allDisruptionEventsIntervals))
 	for _, eventInterval := range allDisruptionEventsIntervals {
 		backend := eventInterval.Locator.Keys[monitorapi.LocatorBackendDisruptionNameKey]
 		switch {
@@ -33,10 +33,10 @@ func TestMultipleSingleSecondDisruptions(events monitorapi.Intervals) []*junitap
 
 	ret := []*junitapi.JUnitTestCase{}
 	for _, backend := range allServers.List() {
-		allDisruptionEvents := events.Filter(
-			monitorapi.And(
-				monitorapi.IsForDisruptionBackend(backend),
-				monitorapi.IsErrorEvent,
+		allDisruptionEvents := events.Query(
+			monitorapi.QueryAnd(
+				monitorapi.QueryForDisruptionBackend(backend),
+				monitorapi.QueryFunc(monitorapi.IsErrorEvent),
 			),
 		)
 		logrus.Infof("found %d disruption events for backend %s", len(allDisruptionEvents), backend)
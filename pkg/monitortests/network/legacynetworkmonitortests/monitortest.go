@@ -35,6 +35,10 @@ func (*legacyMonitorTests) ConstructComputedIntervals(ctx context.Context, start
 }
 
 func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return w.EvaluateTestsFromIntervalSet(ctx, finalIntervals, monitorapi.NewIntervalSet(finalIntervals))
+}
+
+func (w *legacyMonitorTests) EvaluateTestsFromIntervalSet(ctx context.Context, finalIntervals monitorapi.Intervals, finalIntervalSet *monitorapi.IntervalSet) ([]*junitapi.JUnitTestCase, error) {
 	junits := []*junitapi.JUnitTestCase{}
 	junits = append(junits, testPodSandboxCreation(finalIntervals, w.adminRESTConfig)...)
 	junits = append(junits, testOvnNodeReadinessProbe(finalIntervals, w.adminRESTConfig)...)
@@ -42,7 +46,7 @@ func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.C
 	junits = append(junits, testNoOVSVswitchdUnreasonablyLongPollIntervals(finalIntervals)...)
 	junits = append(junits, testPodIPReuse(finalIntervals)...)
 	junits = append(junits, testErrorUpdatingEndpointSlices(finalIntervals)...)
-	junits = append(junits, TestMultipleSingleSecondDisruptions(finalIntervals)...)
+	junits = append(junits, TestMultipleSingleSecondDisruptions(finalIntervalSet)...)
 	junits = append(junits, testDNSOverlapDisruption(finalIntervals)...)
 	junits = append(junits, testNoTooManyNetlinkEventLogs(finalIntervals)...)
 
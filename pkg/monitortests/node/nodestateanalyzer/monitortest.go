@@ -11,6 +11,10 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+const (
+	MonitorName = "node-state-analyzer"
+)
+
 type nodeStateAnalyzer struct {
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/openshift/origin/pkg/monitortestframework"
 	"github.com/openshift/origin/pkg/monitortests/clusterversionoperator/operatorstateanalyzer"
+	"github.com/openshift/origin/pkg/monitortests/node/nodestateanalyzer"
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
@@ -21,6 +22,8 @@ type metricsEndpointDown struct {
 	adminRESTConfig *rest.Config
 }
 
+var _ monitortestframework.ComputedIntervalsDependent = &metricsEndpointDown{}
+
 func NewMetricsEndpointDown() monitortestframework.MonitorTest {
 	return &metricsEndpointDown{}
 }
@@ -35,26 +38,28 @@ func (w *metricsEndpointDown) CollectData(ctx context.Context, storageDir string
 	return intervals, nil, err
 }
 
-func (*metricsEndpointDown) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
-	return nil, nil
+// ComputedIntervalsDependencies returns node-state-analyzer, which computes the node updates the metrics endpoints
+// are expected to go down during.
+func (*metricsEndpointDown) ComputedIntervalsDependencies() []string {
+	return []string{nodestateanalyzer.MonitorName}
 }
 
-func (*metricsEndpointDown) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
-	failures := []string{}
+func (w *metricsEndpointDown) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
 	logger := logrus.WithField("MonitorTest", "MetricsEndpointDown")
-	metricsEndpointDownIntervals := finalIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
+	metricsEndpointDownIntervals := startingIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
 		return eventInterval.Source == monitorapi.SourceMetricsEndpointDown
 	})
 	logger.Infof("found %d metrics endpoint down intervals", len(metricsEndpointDownIntervals))
 
 	// We know these endpoints go down both during node update, and obviously during reboot, ignore overlap
 	// with either:
-	nodeUpdateIntervals := finalIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
+	nodeUpdateIntervals := startingIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
 		return (eventInterval.Source == monitorapi.SourceNodeState && eventInterval.Message.Annotations["phase"] == "Update") ||
 			(eventInterval.Source == monitorapi.SourceNodeState && eventInterval.Message.Annotations["phase"] == "Reboot")
 	})
 	logger.Infof("found %d node update intervals", len(nodeUpdateIntervals))
 
+	unexpectedDownIntervals := monitorapi.Intervals{}
 	for _, downInterval := range metricsEndpointDownIntervals {
 		logger.Infof("checking metrics down interval: %s", downInterval)
 		restartsForNodeIntervals := nodeUpdateIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
@@ -62,12 +67,30 @@ func (*metricsEndpointDown) EvaluateTestsFromConstructedIntervals(ctx context.Co
 		})
 		overlapIntervals := operatorstateanalyzer.FindOverlap(restartsForNodeIntervals, downInterval.From, downInterval.To)
 		if len(overlapIntervals) == 0 {
-			failures = append(failures, downInterval.String())
+			unexpectedDownIntervals = append(unexpectedDownIntervals,
+				monitorapi.NewInterval(monitorapi.SourceMetricsEndpointDown, monitorapi.Warning).
+					Locator(downInterval.Locator).
+					Message(monitorapi.NewMessage().
+						Reason(monitorapi.MetricsEndpointDownOutsideNodeUpdateReason).
+						Constructed(monitorapi.ConstructionOwnerMetricsEndpoint).
+						HumanMessage(downInterval.Message.HumanMessage)).
+					Build(downInterval.From, downInterval.To))
 			logger.Info("found no overlap with a node update")
 		} else {
 			logger.Infof("found overlap with a node update: %s", overlapIntervals[0])
 		}
 	}
+	return unexpectedDownIntervals, nil
+}
+
+func (w *metricsEndpointDown) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	failures := []string{}
+	for _, downInterval := range finalIntervals {
+		if downInterval.Source == monitorapi.SourceMetricsEndpointDown &&
+			downInterval.Message.Reason == monitorapi.MetricsEndpointDownOutsideNodeUpdateReason {
+			failures = append(failures, downInterval.String())
+		}
+	}
 	junits := []*junitapi.JUnitTestCase{}
 	if len(failures) > 0 {
 		testOutput := fmt.Sprintf("found prometheus reporting metrics endpoints down outside of a node update: \n  %s",
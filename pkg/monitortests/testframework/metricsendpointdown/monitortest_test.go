@@ -0,0 +1,53 @@
+package metricsendpointdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestUnexpectedDownIntervalsAreComputed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	down := func(node string) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceMetricsEndpointDown, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().NodeFromName(node)).
+			Message(monitorapi.NewMessage().HumanMessage("kubelet metrics endpoint down")).
+			Build(start.Add(time.Minute), start.Add(2*time.Minute))
+	}
+	nodeUpdate := monitorapi.NewInterval(monitorapi.SourceNodeState, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("updating")).
+		Message(monitorapi.NewMessage().WithAnnotation(monitorapi.AnnotationPhase, "Update").HumanMessage("node is updating")).
+		Build(start, start.Add(5*time.Minute))
+	rawIntervals := monitorapi.Intervals{down("updating"), down("steady"), nodeUpdate}
+
+	w := &metricsEndpointDown{}
+	computed, err := w.ConstructComputedIntervals(context.TODO(), rawIntervals, nil, start, start.Add(10*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(computed) != 1 {
+		t.Fatalf("expected one computed interval, got %d: %v", len(computed), computed)
+	}
+	if node := computed[0].Locator.Keys[monitorapi.LocatorNodeKey]; node != "steady" {
+		t.Errorf("expected the computed interval for node steady, got %q", node)
+	}
+
+	// the verdict comes from the final intervals only, so a fresh instance evaluates the same way
+	junits, err := (&metricsEndpointDown{}).EvaluateTestsFromConstructedIntervals(context.TODO(), append(rawIntervals, computed...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(junits) != 2 || junits[0].FailureOutput == nil || junits[1].FailureOutput != nil {
+		t.Fatalf("expected a failure and a success, got %v", junits)
+	}
+
+	junits, err = (&metricsEndpointDown{}).EvaluateTestsFromConstructedIntervals(context.TODO(), rawIntervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(junits) != 1 || junits[0].FailureOutput != nil {
+		t.Fatalf("expected only a success without computed intervals, got %v", junits)
+	}
+}
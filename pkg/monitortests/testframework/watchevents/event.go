@@ -26,6 +26,9 @@ import (
 
 var reMatchFirstQuote = regexp.MustCompile(`"([^"]+)"( in (\d+(\.\d+)?(s|ms)$))?`)
 
+// reMatchUpgradeHop matches the hop prefix of the notes of the events recorded by multi-hop upgrades.
+var reMatchUpgradeHop = regexp.MustCompile(`^hop/(\d+) `)
+
 func startEventMonitoring(ctx context.Context, m monitorapi.RecorderWriter, adminRESTConfig *rest.Config, client kubernetes.Interface) {
 
 	// filter out events written "now" but with significantly older start times (events
@@ -152,6 +155,13 @@ func recordAddOrUpdateEvent(
 		}
 	case "CABundleUpdateRequired", "SignerUpdateRequired", "TargetUpdateRequired", "CertificateUpdated", "CertificateRemoved", "CertificateUpdateFailed":
 		message = message.WithAnnotation(monitorapi.AnnotationInteresting, "true")
+	case string(monitorapi.UpgradeStartedReason), string(monitorapi.UpgradeVersionReason), string(monitorapi.UpgradeRollbackReason),
+		string(monitorapi.UpgradeFailedReason), string(monitorapi.UpgradeCompleteReason):
+		if obj.InvolvedObject.Kind == "ClusterVersion" {
+			if m := reMatchUpgradeHop.FindStringSubmatch(obj.Message); m != nil {
+				message = message.WithAnnotation(monitorapi.AnnotationUpgradeHop, m[1])
+			}
+		}
 	default:
 	}
 
@@ -138,6 +138,39 @@ func Test_recordAddOrUpdateEvent(t *testing.T) {
 				WithAnnotation("lastTimestamp", now.Format(time.RFC3339)).
 				Build(),
 		},
+		{
+			name: "multi-hop upgrade event",
+			args: args{
+				ctx: context.TODO(),
+				m:   monitor.NewRecorder(),
+				kubeEvent: &corev1.Event{
+					Count:  1,
+					Reason: "UpgradeStarted",
+					InvolvedObject: corev1.ObjectReference{
+						Kind:      "ClusterVersion",
+						Namespace: "openshift-cluster-version",
+						Name:      "cluster",
+					},
+					Message:        "hop/2 version/4.16.3 image/registry.example.com/ocp/release:4.16.3",
+					FirstTimestamp: metav1.NewTime(first),
+					LastTimestamp:  metav1.NewTime(now),
+				},
+			},
+			expectedLocator: monitorapi.Locator{
+				Type: monitorapi.LocatorTypeKind,
+				Keys: map[monitorapi.LocatorKey]string{
+					monitorapi.LocatorNamespaceKey:      "openshift-cluster-version",
+					monitorapi.LocatorClusterVersionKey: "cluster",
+					monitorapi.LocatorHmsgKey:           "2ca3fc10cc",
+				},
+			},
+			expectedMessage: monitorapi.NewMessage().Reason("UpgradeStarted").
+				HumanMessage("hop/2 version/4.16.3 image/registry.example.com/ocp/release:4.16.3").
+				WithAnnotation(monitorapi.AnnotationUpgradeHop, "2").
+				WithAnnotation("firstTimestamp", first.Format(time.RFC3339)).
+				WithAnnotation("lastTimestamp", now.Format(time.RFC3339)).
+				Build(),
+		},
 	}
 	for _, tt := range tests {
 		if tt.skip {
@@ -21,8 +21,6 @@ import (
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"k8s.io/client-go/discovery"
-	"k8s.io/client-go/rest"
 
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
 	"github.com/openshift/origin/pkg/defaultmonitortests"
@@ -51,6 +49,54 @@ type GinkgoRunSuiteOptions struct {
 	Timeout     time.Duration
 	JUnitDir    string
 
+	// Shard is INDEX/TOTAL or serial and restricts the run to the part of the suite assigned to this shard.
+	Shard string
+
+	// ChangedFiles and Since restrict the run to the tests affected by a change, given either as
+	// repository relative paths or as a git ref to diff the working tree against.
+	ChangedFiles []string
+	Since        string
+
+	// ExternalBinaries are NAME=SOURCE external test binaries used instead of the binaries of the
+	// release payload, see externalBinarySource.
+	ExternalBinaries []string
+
+	// AdaptiveParallelism scales the parallelism to the size of the cluster and to the load of its
+	// control plane during the run.
+	AdaptiveParallelism bool
+
+	// HealthGateTimeout is how long to wait between buckets for the cluster to be healthy, 0 to not wait.
+	HealthGateTimeout time.Duration
+
+	// ComponentMapping is a YAML file assigning owning components to tests by name, reported in the
+	// junit properties of the tests.
+	ComponentMapping string
+
+	// RebaseExclusions is the manifest of the tests excluded while a kube rebase is in progress,
+	// replacing the manifest built into the binary.
+	RebaseExclusions string
+
+	// DetectResourceLeaks reports the tests that leave cluster resources behind.
+	DetectResourceLeaks bool
+
+	// StatusListen is the address to serve the live status of the run on, if set.
+	StatusListen string
+
+	// IntervalStorageDir is the directory the monitor intervals are recorded in instead of memory, if set.
+	IntervalStorageDir string
+
+	// RetryMaxAttempts, RetryOverrides, RetryIsolated, RetryBackoff and RetryAllowFlakes override the
+	// RetryPolicy of the suite.
+	RetryMaxAttempts int
+	RetryOverrides   []string
+	RetryIsolated    bool
+	RetryBackoff     time.Duration
+	RetryAllowFlakes bool
+
+	// ResumeFrom is the --junit-dir of a previous, interrupted run. Tests that passed according
+	// to its run ledger are not run again and their results are merged into this run.
+	ResumeFrom string
+
 	// SyntheticEventTests allows the caller to translate events or outside
 	// context into a failure.
 	SyntheticEventTests JUnitsForEvents
@@ -59,6 +105,10 @@ type GinkgoRunSuiteOptions struct {
 
 	IncludeSuccessOutput bool
 
+	// TestDurationHistory is a JUnit file, directory of JUnit files, or JSON map of test name to
+	// seconds from previous runs, used to start the longest tests of each bucket first.
+	TestDurationHistory string
+
 	CommandEnv []string
 
 	DryRun        bool
@@ -85,11 +135,29 @@ func (o *GinkgoRunSuiteOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.BoolVar(&o.PrintCommands, "print-commands", o.PrintCommands, "Print the sub-commands that would be executed instead.")
 	flags.StringVar(&o.ClusterStabilityDuringTest, "cluster-stability", o.ClusterStabilityDuringTest, "cluster stability during test, usually dependent on the job: Stable or Disruptive. Empty default will be treated as Stable.")
 	flags.StringVar(&o.JUnitDir, "junit-dir", o.JUnitDir, "The directory to write test reports to.")
+	flags.IntVar(&o.RetryMaxAttempts, "retry-max-attempts", o.RetryMaxAttempts, "The number of times a failing test is run in total, including the first attempt. Setting any --retry-* flag retries failures regardless of the number the suite allows to flake, and flaky tests fail the run if there are more of them than the suite allows, which may be none, unless --retry-allow-flakes is set.")
+	flags.StringSliceVar(&o.RetryOverrides, "retry-attempts-for", o.RetryOverrides, "REGEX=ATTEMPTS overriding --retry-max-attempts for the tests whose names match. May be repeated, the first match wins.")
+	flags.BoolVar(&o.RetryIsolated, "retry-isolated", o.RetryIsolated, "Retry failures one at a time instead of in parallel.")
+	flags.DurationVar(&o.RetryBackoff, "retry-backoff", o.RetryBackoff, "The time to wait before retrying failures, doubled before every further attempt.")
+	flags.BoolVar(&o.RetryAllowFlakes, "retry-allow-flakes", o.RetryAllowFlakes, "Pass the run however many tests failed and then passed on retry, instead of failing it when more tests flaked than the suite allows.")
+	flags.StringVar(&o.Shard, "shard", o.Shard, "Run only the part of the suite assigned to this shard, in the form INDEX/TOTAL, e.g. 3/8, or serial. The parallel tests are assigned to the numbered shards by a stable hash of their name, and the [Serial] tests to the serial shard, which must run against the cluster after every numbered shard finished. Use merge-junit to combine the reports of all shards.")
+	flags.StringVar(&o.ResumeFrom, "resume-from", o.ResumeFrom, "The --junit-dir of a previous, interrupted run. Tests that already passed there are not run again and their results are merged into the reports of this run.")
 	flags.IntVar(&o.Count, "count", o.Count, "Run each test a specified number of times. Defaults to 1 or the suite's preferred value. -1 will run forever.")
 	flags.BoolVar(&o.FailFast, "fail-fast", o.FailFast, "If a test fails, exit immediately.")
 	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite by default, but will be 10 minutes otherwise.")
 	flags.BoolVar(&o.IncludeSuccessOutput, "include-success", o.IncludeSuccessOutput, "Print output from successful tests.")
+	flags.StringVar(&o.TestDurationHistory, "test-duration-history", o.TestDurationHistory, "A JUnit XML file, a directory of JUnit XML files, or a JSON object of test name to seconds from previous runs. When set, the tests expected to take longest are started first within each group of tests, falling back to [Timeout:] for tests without history.")
 	flags.IntVar(&o.Parallelism, "max-parallel-tests", o.Parallelism, "Maximum number of tests running in parallel. 0 defaults to test suite recommended value, which is different in each suite.")
+	flags.StringSliceVar(&o.ChangedFiles, "changed-files", o.ChangedFiles, "Run only the tests affected by these repository relative paths: tests defined in the same package as a changed go file under test/extended or pkg, tests sharing a [sig-*] or [Feature:*] label with them, and all [Early] and [Late] tests. If a changed package defines no tests, the whole suite is run.")
+	flags.StringVar(&o.Since, "since", o.Since, "Like --changed-files, with the files changed in the current git working tree since this ref.")
+	flags.StringArrayVar(&o.ExternalBinaries, "external-binary", o.ExternalBinaries, "NAME=PATH of an external test binary to use instead of the external binary of the release payload named NAME, e.g. k8s-tests, or in addition to them if the payload has none of that name. The release payload is not read when every required binary is given. PATH may be oci:DIR:PATH or oci-archive:FILE:PATH to use the binary at PATH in the image of an OCI layout directory or tarball; binaries extracted from images are cached by image digest. May be repeated.")
+	flags.BoolVar(&o.AdaptiveParallelism, "adaptive-parallelism", o.AdaptiveParallelism, "Scale the number of tests run at once to the number of nodes of the cluster, the parallelism of the suite or --parallelism being tuned for six nodes, and lower or raise it during the run as the apiserver latency and rejected requests rise or fall. Every change is recorded as an interval.")
+	flags.DurationVar(&o.HealthGateTimeout, "health-gate-timeout", o.HealthGateTimeout, "Wait up to this long after the early, kube, storage, openshift and must-gather tests for every cluster operator to be Available and not Degraded, every node to be Ready and every machine config pool to be updated before running the next tests. A cluster that stays unhealthy is reported as a failing synthetic test for the tests that ran before and an interval with the unhealthy conditions, and the run continues. 0 disables the gate.")
+	flags.StringVar(&o.ComponentMapping, "component-mapping", o.ComponentMapping, "A YAML file of components, each a regular expression matching test names and the component owning the matching tests, e.g. 'components: [{test: EgressIP, component: Networking}]'. Every junit test case has a component property: the component of its [Jira:] label, else of the first matching entry, else of its [sig-*] label.")
+	flags.StringVar(&o.RebaseExclusions, "rebase-exclusions", o.RebaseExclusions, "A YAML manifest of the tests excluded while a kube rebase is in progress, by test name regular expression and kube minor and cluster version ranges, to use instead of the manifest built into the binary.")
+	flags.BoolVar(&o.DetectResourceLeaks, "detect-resource-leaks", o.DetectResourceLeaks, "Watch namespaces, persistent volumes, CRDs, cluster roles and bindings, webhooks and cluster configuration, and report the cluster resources that were created or changed while a test ran and still exist at the end of the run as a flaky synthetic test per test.")
+	flags.StringVar(&o.StatusListen, "status-listen", o.StatusListen, "Serve the live status of the run on this address, e.g. :8080. /status returns running tests, completed counts by state, current failures and an ETA as JSON, and /events streams test completions and monitor intervals as server-sent events.")
+	flags.StringVar(&o.IntervalStorageDir, "interval-storage-dir", o.IntervalStorageDir, "Record the monitor intervals in segment files in this directory instead of memory, bounding the memory of long runs. Intervals recorded in the directory by an earlier run are removed, unless resuming it with --resume-from, which keeps and reports them with the intervals of this run, from the earliest one on.")
 	flags.StringSliceVar(&o.ExactMonitorTests, "monitor", o.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&o.DisableMonitorTests, "disable-monitor", o.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
@@ -101,9 +169,72 @@ func (o *GinkgoRunSuiteOptions) Validate() error {
 	default:
 		return fmt.Errorf("unknown --cluster-stability, %q, expected Stable or Disruptive", o.ClusterStabilityDuringTest)
 	}
+	if len(o.Shard) > 0 {
+		if _, err := parseTestShard(o.Shard); err != nil {
+			return fmt.Errorf("invalid --shard: %w", err)
+		}
+	}
+	if len(o.ChangedFiles) > 0 && len(o.Since) > 0 {
+		return fmt.Errorf("--changed-files and --since are mutually exclusive")
+	}
+	if _, err := o.retryPolicy(&TestSuite{}); err != nil {
+		return err
+	}
+	if _, err := parseExternalBinarySources(o.ExternalBinaries); err != nil {
+		return fmt.Errorf("invalid --external-binary: %w", err)
+	}
+	if o.HealthGateTimeout < 0 {
+		return fmt.Errorf("--health-gate-timeout must not be negative")
+	}
+	if _, err := loadRebaseExclusions(o.RebaseExclusions); err != nil {
+		return fmt.Errorf("invalid --rebase-exclusions: %w", err)
+	}
+	if _, err := loadComponentMapping(o.ComponentMapping); err != nil {
+		return fmt.Errorf("invalid --component-mapping: %w", err)
+	}
 	return nil
 }
 
+// retryPolicy returns the retry policy of the suite with the --retry-* flags applied.
+func (o *GinkgoRunSuiteOptions) retryPolicy(suite *TestSuite) (*RetryPolicy, error) {
+	if o.RetryMaxAttempts == 0 && len(o.RetryOverrides) == 0 && !o.RetryIsolated && o.RetryBackoff == 0 && !o.RetryAllowFlakes {
+		return suite.RetryPolicy, nil
+	}
+
+	policy := &RetryPolicy{}
+	if suite.RetryPolicy != nil {
+		*policy = *suite.RetryPolicy
+	}
+	if o.RetryMaxAttempts < 0 {
+		return nil, fmt.Errorf("--retry-max-attempts must not be negative")
+	}
+	if o.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = o.RetryMaxAttempts
+	}
+	if len(o.RetryOverrides) > 0 {
+		var overrides []RetryOverride
+		for _, value := range o.RetryOverrides {
+			override, err := ParseRetryOverride(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --retry-attempts-for: %w", err)
+			}
+			overrides = append(overrides, override)
+		}
+		// flag overrides take precedence over the ones of the suite
+		policy.Overrides = append(overrides, policy.Overrides...)
+	}
+	if o.RetryIsolated {
+		policy.Isolated = true
+	}
+	if o.RetryBackoff > 0 {
+		policy.Backoff = o.RetryBackoff
+	}
+	if o.RetryAllowFlakes {
+		policy.AllowAllFlakes = true
+	}
+	return policy, nil
+}
+
 func (o *GinkgoRunSuiteOptions) AsEnv() []string {
 	var args []string
 	args = append(args, fmt.Sprintf("TEST_SUITE_START_TIME=%d", o.StartTime.Unix()))
@@ -133,25 +264,17 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	fmt.Fprintf(o.Out, "found %d tests for suite\n", len(tests))
 
 	var fallbackSyntheticTestResult []*junitapi.JUnitTestCase
-	if len(os.Getenv("OPENSHIFT_SKIP_EXTERNAL_TESTS")) == 0 {
+	externalBinarySources, err := parseExternalBinarySources(o.ExternalBinaries)
+	if err != nil {
+		return err
+	}
+	if len(externalBinarySources) > 0 || len(os.Getenv("OPENSHIFT_SKIP_EXTERNAL_TESTS")) == 0 {
 		buf := &bytes.Buffer{}
-		fmt.Fprintf(buf, "Attempting to pull tests from external binary...\n")
-		externalTests, err := externalTestsForSuite(ctx)
+		fmt.Fprintf(buf, "Attempting to pull tests from external binaries...\n")
+		externalTests, err := externalTestsForSuite(ctx, externalBinarySources, buf)
 		if err == nil {
-			filteredTests := []*testCase{}
-			for _, test := range tests {
-				// tests contains all the tests "registered" in openshif-tests binary,
-				// this also includes vendored k8s tests, since this path assumes we're
-				// using external binary to run these tests we need to remove them
-				// from the final lists, which contains:
-				// 1. origin tests, only
-				// 2. k8s tests, coming from external binary
-				if !strings.Contains(test.name, "[Suite:k8s]") {
-					filteredTests = append(filteredTests, test)
-				}
-			}
-			tests = append(filteredTests, externalTests...)
-			fmt.Fprintf(buf, "Got %d tests from external binary\n", len(externalTests))
+			tests = withExternalTests(tests, externalTests)
+			fmt.Fprintf(buf, "Got %d tests from external binaries\n", len(externalTests))
 		} else {
 			fmt.Fprintf(buf, "Falling back to built-in suite, failed reading external test suites: %v\n", err)
 			// adding this test twice (one failure here, and success below) will
@@ -188,6 +311,44 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 
 	fmt.Fprintf(o.Out, "found %d filtered tests\n", len(tests))
 
+	if len(o.ChangedFiles) > 0 || len(o.Since) > 0 {
+		changedFiles := o.ChangedFiles
+		if len(o.Since) > 0 {
+			changedFiles, err = changedFilesSince(o.Since)
+			if err != nil {
+				return err
+			}
+		}
+		affected, unmapped := newTestImpact(changedFiles).Filter(tests)
+		if len(unmapped) > 0 {
+			fmt.Fprintf(o.Out, "running all %d tests, no tests are defined in changed packages: %s\n", len(tests), strings.Join(unmapped, ", "))
+		} else {
+			tests = affected
+			fmt.Fprintf(o.Out, "found %d tests affected by %d changed files\n", len(tests), len(changedFiles))
+		}
+	}
+
+	var shard *testShard
+	if len(o.Shard) > 0 {
+		shard, err = parseTestShard(o.Shard)
+		if err != nil {
+			return fmt.Errorf("invalid --shard: %w", err)
+		}
+		tests = shard.Filter(tests)
+		fmt.Fprintf(o.Out, "found %d tests in shard %s\n", len(tests), shard)
+	}
+
+	var resumedTests []*testCase
+	if len(o.ResumeFrom) > 0 {
+		entries, err := loadRunLedger(o.ResumeFrom)
+		if err != nil {
+			return fmt.Errorf("could not read run ledger from --resume-from: %w", err)
+		}
+		resumedTests = passedTestsFromLedger(entries)
+		tests = withoutResumedTests(tests, resumedTests)
+		fmt.Fprintf(o.Out, "resuming from %s, %d tests already passed, %d tests remaining\n", o.ResumeFrom, len(resumedTests), len(tests))
+	}
+
 	count := o.Count
 	if count == 0 {
 		count = suite.Count
@@ -206,13 +367,39 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		timeout = 15 * time.Minute
 	}
 
+	retryPolicy, err := o.retryPolicy(suite)
+	if err != nil {
+		return err
+	}
+
 	testRunnerContext := newCommandContext(o.AsEnv(), timeout)
 
+	var testDurations testDurationHistory
+	if len(o.TestDurationHistory) > 0 {
+		testDurations, err = loadTestDurationHistory(o.TestDurationHistory)
+		if err != nil {
+			return fmt.Errorf("could not read --test-duration-history: %w", err)
+		}
+		fmt.Fprintf(o.Out, "loaded duration history for %d tests\n", len(testDurations))
+	}
+
 	if o.PrintCommands {
-		newParallelTestQueue(testRunnerContext).OutputCommands(ctx, tests, o.Out)
+		newParallelTestQueue(testRunnerContext, testDurations).OutputCommands(ctx, tests, o.Out)
 		return nil
 	}
+	rebaseExclusions, err := loadRebaseExclusions(o.RebaseExclusions)
+	if err != nil {
+		return err
+	}
+	components, err := loadComponentMapping(o.ComponentMapping)
+	if err != nil {
+		return err
+	}
 	if o.DryRun {
+		// a dry run does not contact the cluster, so the tests the rebase exclusions may skip are
+		// listed with the versions they are skipped on, on the error output so that the output
+		// remains a list of test names.
+		printRebaseExclusions(rebaseExclusions, tests, o.ErrOut)
 		for _, test := range sortedTests(tests) {
 			fmt.Fprintf(o.Out, "%q\n", test.name)
 		}
@@ -225,10 +412,11 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	}
 
 	// skip tests due to newer k8s
-	tests, err = o.filterOutRebaseTests(restConfig, tests)
+	clusterVersion, err := DiscoverClusterVersion(ctx, restConfig)
 	if err != nil {
 		return err
 	}
+	tests = filterOutRebaseTests(rebaseExclusions, clusterVersion, tests, o.Out)
 
 	if len(o.JUnitDir) > 0 {
 		if _, err := os.Stat(o.JUnitDir); err != nil {
@@ -241,6 +429,25 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		}
 	}
 
+	var ledger *runLedger
+	if len(o.JUnitDir) > 0 {
+		ledger, err = newRunLedger(o.JUnitDir, len(o.ResumeFrom) > 0 && sameDir(o.ResumeFrom, o.JUnitDir))
+		if err != nil {
+			return err
+		}
+		defer ledger.Close()
+
+		// carry the resumed results forward so this run can be resumed as well, unless we are
+		// appending to the very ledger we resumed from.
+		if len(o.ResumeFrom) > 0 && !sameDir(o.ResumeFrom, o.JUnitDir) {
+			for _, test := range resumedTests {
+				if err := ledger.Record(test); err != nil {
+					return fmt.Errorf("could not carry resumed results forward: %w", err)
+				}
+			}
+		}
+	}
+
 	parallelism := o.Parallelism
 	if parallelism == 0 {
 		parallelism = suite.Parallelism
@@ -273,15 +480,64 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	}
 
 	monitorEventRecorder := monitor.NewRecorder()
-	m := monitor.NewMonitor(
+	// the intervals of the resumed tests are reported with those of this run
+	intervalsStart := resumedRunStart(start, resumedTests)
+	var resumedIntervals bool
+	if len(o.IntervalStorageDir) > 0 {
+		// the intervals of the interrupted run are only kept when resuming it
+		diskRecorder, err := monitor.NewDiskRecorder(o.IntervalStorageDir, len(o.ResumeFrom) > 0)
+		if err != nil {
+			return fmt.Errorf("unable to record intervals in %s: %w", o.IntervalStorageDir, err)
+		}
+		defer diskRecorder.Close()
+		monitorEventRecorder = diskRecorder
+		// the resumed intervals hold those of the resumed tests, and are reported from the earliest one on
+		if resumedFrom := diskRecorder.ResumedFrom(); !resumedFrom.IsZero() {
+			resumedIntervals = true
+			if resumedFrom.Before(intervalsStart) {
+				intervalsStart = resumedFrom
+			}
+		}
+	}
+	var runStatus *runStatusRecorder
+	if len(o.StatusListen) > 0 {
+		runStatus = newRunStatusRecorder(monitorEventRecorder, start)
+		if err := serveRunStatus(ctx, o.StatusListen, runStatus, o.ErrOut); err != nil {
+			return err
+		}
+		monitorEventRecorder = runStatus
+	}
+	m := monitor.NewResumedMonitor(
 		monitorEventRecorder,
 		restConfig,
 		o.JUnitDir,
 		monitorTests,
+		intervalsStart,
 	)
 	if err := m.Start(ctx); err != nil {
 		return err
 	}
+	if !resumedIntervals {
+		for _, test := range resumedTests {
+			recordResumedTestInMonitor(test, monitorEventRecorder)
+		}
+	}
+
+	var leaks *leakDetector
+	if o.DetectResourceLeaks {
+		leaks = newLeakDetector(monitorEventRecorder, time.Now())
+		if err := leaks.Start(ctx, restConfig); err != nil {
+			return fmt.Errorf("unable to start resource leak detection: %w", err)
+		}
+	}
+
+	var healthGate *clusterHealthGate
+	if o.HealthGateTimeout > 0 {
+		healthGate, err = newClusterHealthGate(restConfig, o.HealthGateTimeout, monitorEventRecorder, o.Out)
+		if err != nil {
+			return fmt.Errorf("unable to create the cluster health gate: %w", err)
+		}
+	}
 
 	pc, err := SetupNewPodCollector(ctx)
 	if err != nil {
@@ -297,27 +553,13 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		includeSuccess = true
 	}
 	testOutputLock := &sync.Mutex{}
-	testOutputConfig := newTestOutputConfig(testOutputLock, o.Out, monitorEventRecorder, includeSuccess)
+	testOutputConfig := newTestOutputConfig(testOutputLock, o.Out, monitorEventRecorder, ledger, leaks, includeSuccess)
 
-	early, notEarly := splitTests(tests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Early]")
-	})
-
-	late, primaryTests := splitTests(notEarly, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Late]")
-	})
-
-	kubeTests, openshiftTests := splitTests(primaryTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Suite:k8s]")
-	})
-
-	storageTests, kubeTests := splitTests(kubeTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[sig-storage]")
-	})
-
-	mustGatherTests, openshiftTests := splitTests(openshiftTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[sig-cli] oc adm must-gather")
-	})
+	early, remaining := splitTests(tests, inBucket(earlyBucket))
+	late, remaining := splitTests(remaining, inBucket(lateBucket))
+	kubeTests, remaining := splitTests(remaining, inBucket(kubeBucket))
+	storageTests, remaining := splitTests(remaining, inBucket(storageBucket))
+	mustGatherTests, openshiftTests := splitTests(remaining, inBucket(mustGatherBucket))
 
 	// If user specifies a count, duplicate the kube and openshift tests that many times.
 	expectedTestCount := len(early) + len(late)
@@ -335,6 +577,9 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		}
 	}
 	expectedTestCount += len(openshiftTests) + len(kubeTests) + len(storageTests) + len(mustGatherTests)
+	if count != -1 {
+		runStatus.setExpectedTests(expectedTestCount + len(resumedTests))
+	}
 
 	abortFn := neverAbort
 	testCtx := ctx
@@ -345,9 +590,18 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	tests = nil
 
 	// run our Early tests
-	q := newParallelTestQueue(testRunnerContext)
+	q := newParallelTestQueue(testRunnerContext, testDurations)
+	adaptiveCtx, cancelAdaptive := context.WithCancel(ctx)
+	defer cancelAdaptive()
+	if o.AdaptiveParallelism {
+		q.adaptive, err = startAdaptiveParallelism(adaptiveCtx, restConfig, parallelism, monitorEventRecorder, o.Out)
+		if err != nil {
+			return fmt.Errorf("unable to start adaptive parallelism: %w", err)
+		}
+	}
 	q.Execute(testCtx, early, parallelism, testOutputConfig, abortFn)
 	tests = append(tests, early...)
+	healthGate.After(testCtx, earlyBucket, early)
 
 	// TODO: will move to the monitor
 	pc.SetEvents([]string{upgradeEvent})
@@ -358,20 +612,24 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		kubeTestsCopy := copyTests(kubeTests)
 		q.Execute(testCtx, kubeTestsCopy, parallelism, testOutputConfig, abortFn)
 		tests = append(tests, kubeTestsCopy...)
+		healthGate.After(testCtx, kubeBucket, kubeTestsCopy)
 
 		// I thought about randomizing the order of the kube, storage, and openshift tests, but storage dominates our e2e runs, so it doesn't help much.
 		storageTestsCopy := copyTests(storageTests)
 		q.Execute(testCtx, storageTestsCopy, max(1, parallelism/2), testOutputConfig, abortFn) // storage tests only run at half the parallelism, so we can avoid cloud provider quota problems.
 		tests = append(tests, storageTestsCopy...)
+		healthGate.After(testCtx, storageBucket, storageTestsCopy)
 
 		openshiftTestsCopy := copyTests(openshiftTests)
 		q.Execute(testCtx, openshiftTestsCopy, parallelism, testOutputConfig, abortFn)
 		tests = append(tests, openshiftTestsCopy...)
+		healthGate.After(testCtx, openshiftBucket, openshiftTestsCopy)
 
 		// run the must-gather tests after parallel tests to reduce resource contention
 		mustGatherTestsCopy := copyTests(mustGatherTests)
 		q.Execute(testCtx, mustGatherTestsCopy, parallelism, testOutputConfig, abortFn)
 		tests = append(tests, mustGatherTestsCopy...)
+		healthGate.After(testCtx, mustGatherBucket, mustGatherTestsCopy)
 	}
 
 	// TODO: will move to the monitor
@@ -400,6 +658,13 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 
 	// calculate the effective test set we ran, excluding any incompletes
 	tests, _ = splitTests(tests, func(t *testCase) bool { return t.success || t.flake || t.failed || t.skipped })
+	// and merge in the tests that passed before we resumed
+	tests = append(tests, resumedTests...)
+
+	if quarantined := suite.applyQuarantine(tests); len(quarantined) > 0 {
+		names := sets.NewString(testNames(quarantined)...).List()
+		fmt.Fprintf(o.Out, "Failing quarantined tests, reported as flakes:\n\n%s\n\n", strings.Join(names, "\n"))
+	}
 
 	end := time.Now()
 	duration := end.Sub(start).Round(time.Second / 10)
@@ -410,7 +675,30 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	pass, fail, skip, failing := summarizeTests(tests)
 
 	// attempt to retry failures to do flake detection
-	if fail > 0 && fail <= suite.MaximumAllowedFlakes {
+	flakesAllowed := suite.MaximumAllowedFlakes > 0
+	switch {
+	case retryPolicy != nil && fail > 0:
+		retried := retryFailures(testCtx, retryPolicy, failing, parallelism, func(ctx context.Context, tests []*testCase, parallelism int) {
+			q.Execute(ctx, tests, parallelism, testOutputConfig, abortFn)
+		}, o.Out)
+		tests = append(tests, retried.retries...)
+		failing = retried.failing
+
+		flakesAllowed = retryPolicy.flakesAllowed(len(retried.flaky), suite.MaximumAllowedFlakes)
+		if len(retried.flaky) > 0 {
+			sort.Strings(retried.flaky)
+			fmt.Fprintf(o.Out, "Flaky tests:\n\n%s\n\n", strings.Join(retried.flaky, "\n"))
+		}
+		if len(retried.skipped) > 0 {
+			// If a retry test got skipped, it means we very likely failed a precondition in the earlier failures, so
+			// we need to remove the failure cases.
+			skipped := sets.NewString(retried.skipped...)
+			tests, _ = splitTests(tests, func(t *testCase) bool { return !(t.failed && skipped.Has(t.name)) })
+			sort.Strings(retried.skipped)
+			fmt.Fprintf(o.Out, "Skipped tests that failed a precondition:\n\n%s\n\n", strings.Join(retried.skipped, "\n"))
+		}
+
+	case fail > 0 && fail <= suite.MaximumAllowedFlakes:
 		var retries []*testCase
 
 		// Make a copy of the all failing tests (subject to the max allowed flakes) so we can have
@@ -426,7 +714,7 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		fmt.Fprintf(o.Out, "Retry count: %d\n", len(retries))
 
 		// Run the tests in the retries list.
-		q := newParallelTestQueue(testRunnerContext)
+		q := newParallelTestQueue(testRunnerContext, testDurations)
 		q.Execute(testCtx, retries, parallelism, testOutputConfig, abortFn)
 
 		var flaky, skipped []string
@@ -482,6 +770,10 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 
 	timeSuffix := fmt.Sprintf("_%s", start.UTC().Format("20060102-150405"))
 
+	// no more tests run, end the interval of the parallelism before the monitor collects the intervals
+	cancelAdaptive()
+	q.adaptive.Stop()
+
 	monitorTestResultState, err := m.Stop(ctx)
 	if err != nil {
 		fmt.Fprintf(o.ErrOut, "error: Failed to stop monitor test: %v\n", err)
@@ -493,9 +785,12 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 
 	// default is empty string as that is what entries prior to adding this will have
 	wasMasterNodeUpdated := ""
-	if events := monitorEventRecorder.Intervals(start, end); len(events) > 0 {
+	// the synthetic tests of the runner are reported whether or not intervals were recorded
+	syntheticTestResults = append(syntheticTestResults, leaks.Leaks()...)
+	syntheticTestResults = append(syntheticTestResults, healthGate.Results()...)
+	if events := monitorEventRecorder.Intervals(intervalsStart, end); len(events) > 0 || len(syntheticTestResults) > 0 {
 		buf := &bytes.Buffer{}
-		if !upgrade {
+		if len(events) > 0 && !upgrade {
 			// the current mechanism for external binaries does not support upgrade
 			// tests, so don't report information there at all
 			syntheticTestResults = append(syntheticTestResults, fallbackSyntheticTestResult...)
@@ -541,7 +836,9 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 			}
 		}
 
-		wasMasterNodeUpdated = clusterinfo.WasMasterNodeUpdated(events)
+		if len(events) > 0 {
+			wasMasterNodeUpdated = clusterinfo.WasMasterNodeUpdated(events)
+		}
 	}
 
 	// report the outcome of the test
@@ -551,8 +848,14 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	}
 
 	if len(o.JUnitDir) > 0 {
-		finalSuiteResults := generateJUnitTestSuiteResults(junitSuiteName, duration, tests, syntheticTestResults...)
-		if err := writeJUnitReport(finalSuiteResults, "junit_e2e", timeSuffix, o.JUnitDir, o.ErrOut); err != nil {
+		finalSuiteResults := generateJUnitTestSuiteResults(junitSuiteName, duration, tests, components, syntheticTestResults...)
+		if shard != nil {
+			finalSuiteResults.Properties = append(finalSuiteResults.Properties, &junitapi.TestSuiteProperty{
+				Name:  shardProperty,
+				Value: shard.String(),
+			})
+		}
+		if err := writeJUnitReport(finalSuiteResults, shard.junitFilePrefix("junit_e2e"), timeSuffix, o.JUnitDir, o.ErrOut); err != nil {
 			fmt.Fprintf(o.Out, "error: Unable to write e2e JUnit xml results: %v", err)
 		}
 
@@ -562,7 +865,7 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	}
 
 	if fail > 0 {
-		if len(failing) > 0 || suite.MaximumAllowedFlakes == 0 {
+		if len(failing) > 0 || !flakesAllowed {
 			return fmt.Errorf("%d fail, %d pass, %d skip (%s)", fail, pass, skip, duration)
 		}
 		fmt.Fprintf(o.Out, "%d flakes detected, suite allows passing with only flakes\n\n", fail)
@@ -578,36 +881,3 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	fmt.Fprintf(o.Out, "%d pass, %d skip (%s)\n", pass, skip, duration)
 	return ctx.Err()
 }
-
-func (o *GinkgoRunSuiteOptions) filterOutRebaseTests(restConfig *rest.Config, tests []*testCase) ([]*testCase, error) {
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
-	if err != nil {
-		return nil, err
-	}
-	serverVersion, err := discoveryClient.ServerVersion()
-	if err != nil {
-		return nil, err
-	}
-	// TODO: this version along with below exclusions lists needs to be updated
-	// for the rebase in-progress.
-	if !strings.HasPrefix(serverVersion.Minor, "32") {
-		return tests, nil
-	}
-
-	// Below list should only be filled in when we're trying to land k8s rebase.
-	// Don't pile them up!
-	exclusions := []string{}
-
-	matches := make([]*testCase, 0, len(tests))
-outerLoop:
-	for _, test := range tests {
-		for _, excl := range exclusions {
-			if strings.Contains(test.name, excl) {
-				fmt.Fprintf(o.Out, "Skipping %q due to rebase in-progress\n", test.name)
-				continue outerLoop
-			}
-		}
-		matches = append(matches, test)
-	}
-	return matches, nil
-}
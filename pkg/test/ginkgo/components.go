@@ -0,0 +1,134 @@
+package ginkgo
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+// unknownComponent is the component of the tests no label or mapping assigns an owner to.
+const unknownComponent = "Unknown"
+
+var (
+	sigToComponent = map[string]string{}
+
+	jiraRegex = regexp.MustCompile(`\[Jira:("[^"]*"|[^]]*)\]`)
+	sigRegex  = regexp.MustCompile(`\[(sig-[^]]*)\]`)
+)
+
+func init() {
+	utilruntime.Must(addSigMapping("sig-api-machinery", "kube-apiserver"))
+	utilruntime.Must(addSigMapping("sig-apimachinery", "kube-apiserver"))
+	utilruntime.Must(addSigMapping("sig-apps", "kube-controller-manager"))
+	utilruntime.Must(addSigMapping("sig-arch", "Test Infrastructure"))
+	utilruntime.Must(addSigMapping("sig-auth", "apiserver-auth"))
+	utilruntime.Must(addSigMapping("sig-builds", "Build"))
+	utilruntime.Must(addSigMapping("sig-ci", "Test Infrastructure"))
+	utilruntime.Must(addSigMapping("sig-cli", "oc"))
+	utilruntime.Must(addSigMapping("sig-cloud-provider", "Cloud Compute"))
+	utilruntime.Must(addSigMapping("sig-cluster-lifecycle", "Cloud Compute"))
+	utilruntime.Must(addSigMapping("sig-coreos", "RHCOS"))
+	utilruntime.Must(addSigMapping("sig-devex", "Build"))
+	utilruntime.Must(addSigMapping("sig-etcd", "Etcd"))
+	utilruntime.Must(addSigMapping("sig-imageregistry", "Image Registry"))
+	utilruntime.Must(addSigMapping("sig-installer", "Installer"))
+	utilruntime.Must(addSigMapping("sig-instrumentation", "Monitoring"))
+	utilruntime.Must(addSigMapping("sig-kube-apiserver", "kube-apiserver"))
+	utilruntime.Must(addSigMapping("sig-mco", "Machine Config Operator"))
+	utilruntime.Must(addSigMapping("sig-network", "Networking"))
+	utilruntime.Must(addSigMapping("sig-network-edge", "Routing"))
+	utilruntime.Must(addSigMapping("sig-node", "Node"))
+	utilruntime.Must(addSigMapping("sig-node-tuning", "Node Tuning Operator"))
+	utilruntime.Must(addSigMapping("sig-operator", "OLM"))
+	utilruntime.Must(addSigMapping("sig-scheduling", "kube-scheduler"))
+	utilruntime.Must(addSigMapping("sig-storage", "Storage"))
+}
+
+func addSigMapping(sig, component string) error {
+	if !platformidentification.ValidBugzillaComponents.Has(component) {
+		return fmt.Errorf("%q is not a valid bugzilla component", component)
+	}
+	sigToComponent[sig] = component
+	return nil
+}
+
+// ComponentMapping assigns owning components to tests more precisely than their [sig-*] label.
+type ComponentMapping struct {
+	Components []TestComponent `json:"components"`
+}
+
+// TestComponent assigns Component to the tests matching Test.
+type TestComponent struct {
+	// Test is a regular expression matching the names of the tests.
+	Test string `json:"test"`
+	// Component is the component owning the tests.
+	Component string `json:"component"`
+
+	test *regexp.Regexp
+}
+
+// loadComponentMapping reads the mapping at path, or returns nil if path is empty.
+func loadComponentMapping(path string) (*ComponentMapping, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := &ComponentMapping{}
+	if err := yaml.UnmarshalStrict(data, mapping); err != nil {
+		return nil, fmt.Errorf("unable to parse component mapping: %w", err)
+	}
+	var errs []error
+	for i := range mapping.Components {
+		component := &mapping.Components[i]
+		switch {
+		case len(component.Test) == 0:
+			errs = append(errs, fmt.Errorf("components[%d]: missing test", i))
+		case len(component.Component) == 0:
+			errs = append(errs, fmt.Errorf("components[%d]: missing component", i))
+		default:
+			if component.test, err = regexp.Compile(component.Test); err != nil {
+				errs = append(errs, fmt.Errorf("components[%d]: invalid test: %w", i, err))
+			}
+		}
+	}
+	if err := errors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// componentForTest returns the component owning a test: the component of its [Jira:] label, else
+// the first component of the mapping matching its name, else the component of its first known [sig-*]
+// label, else Unknown.
+func (m *ComponentMapping) componentForTest(name string) string {
+	if match := jiraRegex.FindStringSubmatch(name); match != nil {
+		if component, err := strconv.Unquote(match[1]); err == nil {
+			return component
+		}
+		return match[1]
+	}
+	if m != nil {
+		for _, component := range m.Components {
+			if component.test.MatchString(name) {
+				return component.Component
+			}
+		}
+	}
+	for _, match := range sigRegex.FindAllStringSubmatch(name, -1) {
+		if component, ok := sigToComponent[match[1]]; ok {
+			return component
+		}
+	}
+	return unknownComponent
+}
@@ -0,0 +1,65 @@
+package ginkgo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComponentForTest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "components.yaml")
+	if err := os.WriteFile(path, []byte(`components:
+- test: '\[Feature:EgressIP\]'
+  component: Networking / ovn-kubernetes
+- test: '\[sig-network\] Services'
+  component: Networking / kube-proxy
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mapping, err := loadComponentMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		test     string
+		mapping  *ComponentMapping
+		expected string
+	}{
+		{name: "quoted jira label", test: `[sig-arch][Late][Jira:"kube-apiserver"] tls artifacts are registered`, mapping: mapping, expected: "kube-apiserver"},
+		{name: "unquoted jira label", test: `[sig-network][Jira:Routing] routes work`, mapping: mapping, expected: "Routing"},
+		{name: "first matching entry", test: "[sig-network][Feature:EgressIP] Services egress IP works", mapping: mapping, expected: "Networking / ovn-kubernetes"},
+		{name: "second entry", test: "[sig-network] Services should serve", mapping: mapping, expected: "Networking / kube-proxy"},
+		{name: "sig", test: "[sig-network] pods can talk", mapping: mapping, expected: "Networking"},
+		{name: "sig without mapping", test: "[sig-network][Feature:EgressIP] egress IP works", expected: "Networking"},
+		{name: "first known sig", test: "[sig-sno][sig-etcd] etcd recovers", expected: "Etcd"},
+		{name: "unknown", test: "[sig-sno] single node works", expected: "Unknown"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.mapping.componentForTest(tc.test); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadComponentMapping_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing test":      "components:\n- component: Networking\n",
+		"missing component": "components:\n- test: EgressIP\n",
+		"invalid test":      "components:\n- test: '[sig-network'\n  component: Networking\n",
+		"unknown field":     "components:\n- test: EgressIP\n  owner: Networking\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "components.yaml")
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadComponentMapping(path); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
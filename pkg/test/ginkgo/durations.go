@@ -0,0 +1,119 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// testDurationHistory holds the expected duration of tests by name, as observed in previous runs.
+type testDurationHistory map[string]time.Duration
+
+// loadTestDurationHistory reads the expected test durations from path.  path may be a JUnit XML
+// file, a directory containing JUnit XML files, or a JSON object mapping test names to durations
+// in seconds.  When a test is reported more than once, the longest duration is used.
+func loadTestDurationHistory(path string) (testDurationHistory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	history := testDurationHistory{}
+	if !info.IsDir() {
+		if err := history.addFile(path); err != nil {
+			return nil, err
+		}
+		return history, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := history.addFile(file); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+func (h testDurationHistory) addFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		seconds := map[string]float64{}
+		if err := json.Unmarshal(trimmed, &seconds); err != nil {
+			return fmt.Errorf("unable to parse test durations from %s: %w", path, err)
+		}
+		for name, s := range seconds {
+			h.add(name, time.Duration(s*float64(time.Second)))
+		}
+		return nil
+	}
+
+	suites, err := ReadJUnitTestSuites(data)
+	if err != nil {
+		return fmt.Errorf("unable to parse test durations from %s: %w", path, err)
+	}
+	for _, suite := range suites {
+		h.addSuite(suite)
+	}
+	return nil
+}
+
+func (h testDurationHistory) addSuite(suite *junitapi.JUnitTestSuite) {
+	for _, testCase := range suite.TestCases {
+		if testCase.SkipMessage != nil {
+			continue
+		}
+		h.add(testCase.Name, time.Duration(testCase.Duration*float64(time.Second)))
+	}
+	for _, child := range suite.Children {
+		h.addSuite(child)
+	}
+}
+
+func (h testDurationHistory) add(name string, duration time.Duration) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || duration <= 0 {
+		return
+	}
+	if duration > h[name] {
+		h[name] = duration
+	}
+}
+
+// expectedDuration returns how long the test is expected to run.  Tests without history fall back
+// to their [Timeout:] annotation, and tests with neither are expected to be short.
+func (h testDurationHistory) expectedDuration(test *testCase) time.Duration {
+	if duration, ok := h[test.name]; ok {
+		return duration
+	}
+	return test.testTimeout
+}
+
+// longestFirst returns a copy of tests ordered by decreasing expected duration, so that long tests
+// are not started last and dominate the wall-clock time of the bucket.  Tests with the same expected
+// duration keep their relative order.  Without any history the order is left untouched.
+func (h testDurationHistory) longestFirst(tests []*testCase) []*testCase {
+	if len(h) == 0 {
+		return tests
+	}
+	sorted := make([]*testCase, len(tests))
+	copy(sorted, tests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return h.expectedDuration(sorted[i]) > h.expectedDuration(sorted[j])
+	})
+	return sorted
+}
@@ -0,0 +1,82 @@
+package ginkgo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_loadTestDurationHistory(t *testing.T) {
+	dir := t.TempDir()
+	junit := `<testsuite name="openshift-tests" tests="4" skipped="1" failures="1" time="100">
+    <testcase name="slow" time="300"></testcase>
+    <testcase name="flaky" time="20"><failure message="">boom</failure></testcase>
+    <testcase name="flaky" time="10"></testcase>
+    <testcase name="skipped" time="500"><skipped message="skip"></skipped></testcase>
+</testsuite>`
+	if err := os.WriteFile(filepath.Join(dir, "junit_e2e_1.xml"), []byte(junit), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wrapped := `<testsuites><testsuite name="other"><testcase name="slow" time="200"></testcase><testcase name="fast" time="1.5"></testcase></testsuite></testsuites>`
+	if err := os.WriteFile(filepath.Join(dir, "junit_e2e_2.xml"), []byte(wrapped), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := loadTestDurationHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := testDurationHistory{
+		"slow":  300 * time.Second,
+		"flaky": 20 * time.Second,
+		"fast":  1500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Errorf("expected %v, got %v", expected, history)
+	}
+
+	jsonFile := filepath.Join(dir, "durations.json")
+	if err := os.WriteFile(jsonFile, []byte(`{"slow": 42, "fast": 0.5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err = loadTestDurationHistory(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = testDurationHistory{
+		"slow": 42 * time.Second,
+		"fast": 500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Errorf("expected %v, got %v", expected, history)
+	}
+}
+
+func Test_longestFirst(t *testing.T) {
+	tests := []*testCase{
+		{name: "unknown-1"},
+		{name: "short"},
+		{name: "annotated", testTimeout: 20 * time.Minute},
+		{name: "unknown-2"},
+		{name: "long"},
+	}
+	history := testDurationHistory{
+		"short": time.Minute,
+		"long":  40 * time.Minute,
+	}
+
+	got := testNames(history.longestFirst(tests))
+	expected := []string{"long", "annotated", "short", "unknown-1", "unknown-2"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if testNames(tests)[0] != "unknown-1" {
+		t.Errorf("input was reordered")
+	}
+
+	if got := testNames(testDurationHistory(nil).longestFirst(tests)); !reflect.DeepEqual(got, testNames(tests)) {
+		t.Errorf("expected order to be unchanged without history, got %v", got)
+	}
+}
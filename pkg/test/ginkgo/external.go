@@ -13,33 +13,268 @@ import (
 	"strings"
 	"time"
 
+	"github.com/opencontainers/go-digest"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	imagev1 "github.com/openshift/api/image/v1"
 	"github.com/openshift/origin/test/extended/util"
 )
 
+// An external test binary provides tests that openshift-tests runs together with its own.  The
+// binaries are extracted from the images of the release payload under test, see externalBinaries.
+//
+// A binary implementing version 1 of the contract supports three commands:
+//
+//	info                          prints an ExternalBinaryInfo as JSON
+//	list                          prints the tests as a JSON array of ExternalTest
+//	run-test --output=json NAME   runs the named test and prints an ExternalTestResult as JSON on
+//	                              the last line of stdout
+//
+// A binary without the info command, like the k8s-tests binary of older payloads, is a legacy binary:
+// list prints the tests as lines of serialized tests, and run-test NAME reports the result of the test
+// through its exit code only.  Both versions exit run-test with 0 when the test passed, 1 when it
+// failed, 2 when it timed out and 3 when it was skipped.
+const externalBinaryAPIVersionV1 = "v1"
+
+// ExternalBinaryInfo is printed by the info command of an external binary.
+type ExternalBinaryInfo struct {
+	// APIVersion is the version of the contract the binary implements, v1.
+	APIVersion string `json:"apiVersion"`
+	// Name identifies the binary in the output of openshift-tests, e.g. machine-config-operator-tests.
+	Name string `json:"name"`
+	// Version is the version of the component the binary tests.
+	Version string `json:"version,omitempty"`
+}
+
+// ExternalTest is a test listed by an external binary.
+type ExternalTest struct {
+	Name string `json:"name"`
+	// Labels are appended to the name to select the test into suites, e.g. " [Suite:openshift/conformance/parallel]".
+	Labels string `json:"labels,omitempty"`
+	// Timeout overrides the timeout of the suite for the test, e.g. 30m.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ExternalTestResult is printed by run-test --output=json.
+type ExternalTestResult struct {
+	Name string `json:"name"`
+	// Result is passed, failed, skipped or timedout.
+	Result string `json:"result"`
+	// Error is the reason of a failure or a skip.
+	Error string `json:"error,omitempty"`
+}
+
+// serializedTest is the format listed by legacy binaries.
 type serializedTest struct {
 	Name   string
 	Labels string
 }
 
-// externalTestsForSuite reads tests from external binary, currently only
-// k8s-tests is supported
-func externalTestsForSuite(ctx context.Context) ([]*testCase, error) {
+// externalBinary is an external test binary in an image of the release payload.
+type externalBinary struct {
+	// imageTag is the tag of the image in the release payload, e.g. hyperkube.
+	imageTag string
+	// path is the path of the binary in the image.
+	path string
+	// required binaries fail the lookup of the external tests when they cannot be used, the others are skipped.
+	required bool
+	// source is the --external-binary used instead of the binary of the release payload.
+	source *externalBinarySource
+}
+
+func (b externalBinary) String() string {
+	if b.source != nil {
+		return b.source.String()
+	}
+	return fmt.Sprintf("%s from %s", b.path, b.imageTag)
+}
+
+// name is the name an --external-binary replaces the binary by, the base name of its path.
+func (b externalBinary) name() string {
+	return filepath.Base(b.path)
+}
+
+// defaultExternalBinaries are used whatever the release payload declares.
+var defaultExternalBinaries = []externalBinary{
+	{imageTag: "hyperkube", path: "/usr/bin/k8s-tests", required: true},
+}
+
+// externalBinaryAnnotation is set on the tags of the image-references of the release payload whose
+// image contains external test binaries.  The value is a comma separated list of paths in the image.
+const externalBinaryAnnotation = "testextension.openshift.io/binaries"
+
+// externalBinaries returns the default binaries followed by the ones the image references of the
+// release payload declare.
+func externalBinaries(references *imagev1.ImageStream) []externalBinary {
+	binaries := append([]externalBinary{}, defaultExternalBinaries...)
+	known := map[externalBinary]bool{}
+	for _, binary := range binaries {
+		known[externalBinary{imageTag: binary.imageTag, path: binary.path}] = true
+	}
+	for _, tag := range references.Spec.Tags {
+		for _, path := range strings.Split(tag.Annotations[externalBinaryAnnotation], ",") {
+			binary := externalBinary{imageTag: tag.Name, path: strings.TrimSpace(path)}
+			if len(binary.path) == 0 || known[binary] {
+				continue
+			}
+			known[binary] = true
+			binaries = append(binaries, binary)
+		}
+	}
+	return binaries
+}
+
+// withExternalBinarySources replaces the binaries by the sources of the same name and adds the other
+// sources, which are required.
+func withExternalBinarySources(binaries []externalBinary, sources []externalBinarySource) []externalBinary {
+	byName := map[string]externalBinarySource{}
+	for _, source := range sources {
+		byName[source.name] = source
+	}
+	var ret []externalBinary
+	for _, binary := range binaries {
+		if source, ok := byName[binary.name()]; ok {
+			binary.source = &source
+			delete(byName, source.name)
+		}
+		ret = append(ret, binary)
+	}
+	for _, source := range sources {
+		if _, ok := byName[source.name]; !ok {
+			continue
+		}
+		source := source
+		ret = append(ret, externalBinary{path: source.name, required: true, source: &source})
+	}
+	return ret
+}
+
+// requiresReleasePayload returns true if a required binary is not replaced by one of the sources.
+func requiresReleasePayload(sources []externalBinarySource) bool {
+	for _, binary := range withExternalBinarySources(defaultExternalBinaries, sources) {
+		if binary.required && binary.source == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// externalTestsForSuite reads tests from the external binaries of the release payload, using the
+// sources instead of the binaries of the same name.  When the sources replace every required binary,
+// the release payload is not read and only the sources are used.  A binary of the release payload that
+// is not required and cannot be used is reported to out and skipped.
+func externalTestsForSuite(ctx context.Context, sources []externalBinarySource, out io.Writer) ([]*testCase, error) {
+	cache := newExternalBinaryCache()
+
+	binaries := defaultExternalBinaries
+	var release *releasePayload
+	if requiresReleasePayload(sources) {
+		var err error
+		release, err = readReleasePayload(cache)
+		if err != nil {
+			return nil, err
+		}
+		binaries = externalBinaries(release.references)
+	} else {
+		fmt.Fprintf(out, "Every required external binary is given by --external-binary, not reading the release payload\n")
+	}
+
 	var tests []*testCase
+	for _, binary := range withExternalBinarySources(binaries, sources) {
+		binaryTests, err := binary.externalTests(ctx, release, cache)
+		if err != nil {
+			if binary.required || binary.source != nil {
+				return nil, fmt.Errorf("unable to use %s: %w", binary, err)
+			}
+			fmt.Fprintf(out, "Skipping the tests of %s: %v\n", binary, err)
+			continue
+		}
+		fmt.Fprintf(out, "Got %d tests from %s\n", len(binaryTests), binary)
+		tests = append(tests, binaryTests...)
+	}
+	return tests, nil
+}
 
-	// TODO: add support for binaries from other images
-	testBinary, err := extractBinaryFromReleaseImage("hyperkube", "/usr/bin/k8s-tests")
+// externalTests lists the tests of the binary, from its source if it has one and otherwise from the
+// release payload.
+func (b externalBinary) externalTests(ctx context.Context, release *releasePayload, cache *externalBinaryCache) ([]*testCase, error) {
+	if b.source != nil {
+		testBinary, err := b.source.resolve(cache)
+		if err != nil {
+			return nil, err
+		}
+		return listExternalTests(ctx, testBinary)
+	}
+	return release.externalTests(ctx, b)
+}
+
+// externalTests extracts the binary and lists its tests.
+func (r *releasePayload) externalTests(ctx context.Context, binary externalBinary) ([]*testCase, error) {
+	testBinary, err := r.extractBinary(binary.imageTag, binary.path)
 	if err != nil {
-		return nil, fmt.Errorf("unable to extract k8s-tests binary: %w", err)
+		return nil, err
 	}
+	return listExternalTests(ctx, testBinary)
+}
 
-	command := exec.Command(testBinary, "list")
-	testList, err := runWithTimeout(ctx, command, 1*time.Minute)
+// listExternalTests lists the tests of an external binary.
+func listExternalTests(ctx context.Context, testBinary string) ([]*testCase, error) {
+	apiVersion := ""
+	// legacy binaries fail on the unknown info command
+	if output, err := runWithTimeout(ctx, exec.Command(testBinary, "info"), 1*time.Minute); err == nil {
+		info, err := parseExternalBinaryInfo(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing '%s info': %w", testBinary, err)
+		}
+		apiVersion = info.APIVersion
+	}
+
+	testList, err := runWithTimeout(ctx, exec.Command(testBinary, "list"), 1*time.Minute)
 	if err != nil {
 		return nil, fmt.Errorf("failed running '%s list': %w", testBinary, err)
 	}
+	return parseExternalTests(testBinary, apiVersion, testList)
+}
+
+func parseExternalBinaryInfo(output []byte) (*ExternalBinaryInfo, error) {
+	info := &ExternalBinaryInfo{}
+	if err := json.Unmarshal(lastJSONLine(output), info); err != nil {
+		return nil, err
+	}
+	if info.APIVersion != externalBinaryAPIVersionV1 {
+		return nil, fmt.Errorf("unsupported apiVersion %q, expected %s", info.APIVersion, externalBinaryAPIVersionV1)
+	}
+	return info, nil
+}
+
+// parseExternalTests parses the output of the list command of a binary implementing apiVersion, or of
+// a legacy binary when apiVersion is empty.
+func parseExternalTests(testBinary, apiVersion string, testList []byte) ([]*testCase, error) {
+	var tests []*testCase
+	if apiVersion == externalBinaryAPIVersionV1 {
+		externalTests := []ExternalTest{}
+		if err := json.Unmarshal(testList, &externalTests); err != nil {
+			return nil, fmt.Errorf("failed parsing the tests of %s: %w", testBinary, err)
+		}
+		for _, test := range externalTests {
+			tc := &testCase{
+				name:             test.Name + test.Labels,
+				rawName:          test.Name,
+				binaryName:       testBinary,
+				binaryAPIVersion: apiVersion,
+			}
+			if len(test.Timeout) > 0 {
+				timeout, err := time.ParseDuration(test.Timeout)
+				if err != nil {
+					return nil, fmt.Errorf("invalid timeout of %q: %w", test.Name, err)
+				}
+				tc.testTimeout = timeout
+			}
+			tests = append(tests, tc)
+		}
+		return tests, nil
+	}
+
 	buf := bytes.NewBuffer(testList)
 	for {
 		line, err := buf.ReadString('\n')
@@ -65,50 +300,125 @@ func externalTestsForSuite(ctx context.Context) ([]*testCase, error) {
 	return tests, nil
 }
 
-// extractBinaryFromReleaseImage is responsible for resolving the tag from
-// release image and extracting binary, returns path to the binary or error
-func extractBinaryFromReleaseImage(tag, binary string) (string, error) {
+// externalTestState returns the state of the test reported by run-test --output=json, and the output
+// without the result.  ok is false when the output contains no result, e.g. because the binary
+// crashed, in which case the exit code of run-test decides.
+func externalTestState(output []byte) (state TestState, remaining []byte, ok bool) {
+	line := lastJSONLine(output)
+	if line == nil {
+		return TestUnknown, output, false
+	}
+	result := &ExternalTestResult{}
+	if err := json.Unmarshal(line, result); err != nil {
+		return TestUnknown, output, false
+	}
+	switch result.Result {
+	case "passed":
+		state = TestSucceeded
+	case "failed":
+		state = TestFailed
+	case "skipped":
+		state = TestSkipped
+	case "timedout":
+		state = TestFailedTimeout
+	default:
+		return TestUnknown, output, false
+	}
+
+	remaining = append([]byte{}, output[:bytes.LastIndex(output, line)]...)
+	if len(result.Error) > 0 {
+		remaining = append(remaining, []byte(result.Error+"\n")...)
+	}
+	return state, remaining, true
+}
+
+// lastJSONLine returns the last line of output that is a JSON object.
+func lastJSONLine(output []byte) []byte {
+	lines := bytes.Split(bytes.TrimRight(output, "\n"), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := bytes.TrimSpace(lines[i]); bytes.HasPrefix(line, []byte("{")) {
+			return line
+		}
+	}
+	return nil
+}
+
+// withExternalTests adds the tests of the external binaries.  When the external binaries provide the
+// k8s tests, the vendored k8s tests are replaced.
+func withExternalTests(tests, externalTests []*testCase) []*testCase {
+	externalK8sTests := false
+	for _, test := range externalTests {
+		if strings.Contains(test.name, "[Suite:k8s]") {
+			externalK8sTests = true
+			break
+		}
+	}
+	if !externalK8sTests {
+		return append(tests, externalTests...)
+	}
+
+	filteredTests := []*testCase{}
+	for _, test := range tests {
+		// tests contains all the tests "registered" in openshif-tests binary,
+		// this also includes vendored k8s tests, since this path assumes we're
+		// using external binary to run these tests we need to remove them
+		// from the final lists, which contains:
+		// 1. origin tests, only
+		// 2. k8s tests, coming from external binary
+		if !strings.Contains(test.name, "[Suite:k8s]") {
+			filteredTests = append(filteredTests, test)
+		}
+	}
+	return append(filteredTests, externalTests...)
+}
+
+// releasePayload is the release payload of the cluster under test, whose images contain the external
+// test binaries.
+type releasePayload struct {
+	tmpDir string
+	// references are the images of the payload by tag.
+	references *imagev1.ImageStream
+	// dockerConfigJsonPath is the pull secret of the cluster.
+	dockerConfigJsonPath string
+	// cache keeps the extracted binaries of images pulled by digest, may be nil.
+	cache *externalBinaryCache
+}
+
+// readReleasePayload reads the image references of the release payload of the cluster under test.
+func readReleasePayload(cache *externalBinaryCache) (*releasePayload, error) {
 	tmpDir, err := os.MkdirTemp("", "release")
 	if err != nil {
-		return "", fmt.Errorf("cannot create temporary directory for extracted binary: %w", err)
+		return nil, fmt.Errorf("cannot create temporary directory for extracted binary: %w", err)
 	}
 
 	oc := util.NewCLIWithoutNamespace("default")
 	cv, err := oc.AdminConfigClient().ConfigV1().ClusterVersions().Get(context.Background(), "version", metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed reading ClusterVersion/version: %w", err)
+		return nil, fmt.Errorf("failed reading ClusterVersion/version: %w", err)
 	}
 	releaseImage := cv.Status.Desired.Image
 	if len(releaseImage) == 0 {
-		return "", fmt.Errorf("cannot determine release image from ClusterVersion resource")
+		return nil, fmt.Errorf("cannot determine release image from ClusterVersion resource")
 	}
 
 	if err := runImageExtract(releaseImage, "/release-manifests/image-references", tmpDir, ""); err != nil {
-		return "", fmt.Errorf("failed extracting image-references: %w", err)
+		return nil, fmt.Errorf("failed extracting image-references: %w", err)
 	}
 	jsonFile, err := os.Open(filepath.Join(tmpDir, "image-references"))
 	if err != nil {
-		return "", fmt.Errorf("failed reading image-references: %w", err)
+		return nil, fmt.Errorf("failed reading image-references: %w", err)
 	}
 	defer jsonFile.Close()
 	data, err := ioutil.ReadAll(jsonFile)
 	if err != nil {
-		return "", fmt.Errorf("unable to load release image-references: %w", err)
+		return nil, fmt.Errorf("unable to load release image-references: %w", err)
 	}
 	is := &imagev1.ImageStream{}
 	if err := json.Unmarshal(data, &is); err != nil {
-		return "", fmt.Errorf("unable to load release image-references: %w", err)
+		return nil, fmt.Errorf("unable to load release image-references: %w", err)
 	}
 	if is.Kind != "ImageStream" || is.APIVersion != "image.openshift.io/v1" {
-		return "", fmt.Errorf("unrecognized image-references in release payload")
-	}
-
-	image := ""
-	for _, t := range is.Spec.Tags {
-		if t.Name == tag {
-			image = t.From.Name
-			break
-		}
+		return nil, fmt.Errorf("unrecognized image-references in release payload")
 	}
 
 	// The preceding runImageExtract was against a release payload that was created in the local
@@ -120,28 +430,63 @@ func extractBinaryFromReleaseImage(tag, binary string) (string, error) {
 	// from images referenced by the release payload.
 	clusterPullSecret, err := oc.AdminKubeClient().CoreV1().Secrets("openshift-config").Get(context.Background(), "pull-secret", metav1.GetOptions{})
 	if err != nil {
-		return "", fmt.Errorf("unable to read ephemeral cluster pull secret: %v", err)
+		return nil, fmt.Errorf("unable to read ephemeral cluster pull secret: %v", err)
 	}
 
 	clusterDockerConfig := clusterPullSecret.Data[".dockerconfigjson"]
 	dockerConfigJsonPath := filepath.Join(tmpDir, ".dockerconfigjson")
 	err = os.WriteFile(dockerConfigJsonPath, clusterDockerConfig, 0644)
 	if err != nil {
-		return "", fmt.Errorf("unable to serialize ephemeral cluster pull secret locally: %v", err)
+		return nil, fmt.Errorf("unable to serialize ephemeral cluster pull secret locally: %v", err)
 	}
 
+	return &releasePayload{
+		tmpDir:               tmpDir,
+		references:           is,
+		dockerConfigJsonPath: dockerConfigJsonPath,
+		cache:                cache,
+	}, nil
+}
+
+// extractBinary is responsible for resolving the tag from the release image and extracting binary,
+// returns path to the binary or error
+func (r *releasePayload) extractBinary(tag, binary string) (string, error) {
+	image := ""
+	for _, t := range r.references.Spec.Tags {
+		if t.Name == tag && t.From != nil {
+			image = t.From.Name
+			break
+		}
+	}
 	if len(image) == 0 {
 		return "", fmt.Errorf("%s not found", tag)
 	}
-	if err := runImageExtract(image, binary, tmpDir, dockerConfigJsonPath); err != nil {
-		return "", fmt.Errorf("failed extracting %q from %q: %w", binary, image, err)
+
+	// release payloads reference their images by digest, whose content never changes
+	imageDigest := digest.Digest("")
+	if _, d, ok := strings.Cut(image, "@"); ok {
+		imageDigest = digest.Digest(d)
+	}
+	if imageDigest.Validate() != nil {
+		// binaries of different images may share a name
+		dst := filepath.Join(r.tmpDir, tag)
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return "", err
+		}
+		return extractExecutable(dst, binary, func(dst string) error {
+			return r.extractFromImage(image, binary, dst)
+		})
 	}
+	return r.cache.get(imageDigest, binary, func(dst string) error {
+		return r.extractFromImage(image, binary, dst)
+	})
+}
 
-	extractedBinary := filepath.Join(tmpDir, filepath.Base(binary))
-	if err := os.Chmod(extractedBinary, 0755); err != nil {
-		return "", fmt.Errorf("failed making the extracted binary executable: %w", err)
+func (r *releasePayload) extractFromImage(image, binary, dst string) error {
+	if err := runImageExtract(image, binary, dst, r.dockerConfigJsonPath); err != nil {
+		return fmt.Errorf("failed extracting %q from %q: %w", binary, image, err)
 	}
-	return extractedBinary, nil
+	return nil
 }
 
 // runImageExtract extracts src from specified image to dst
@@ -0,0 +1,344 @@
+package ginkgo
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// externalBinarySource is an --external-binary, an external test binary that is used instead of the
+// binary of the release payload with the same base name, or in addition to them.  It is one of
+//
+//	NAME=PATH                        an executable on the local disk
+//	NAME=oci:DIR:PATH                PATH in the image of the OCI layout directory DIR
+//	NAME=oci-archive:FILE:PATH       PATH in the image of the OCI layout tarball FILE
+type externalBinarySource struct {
+	name string
+	// path is the local executable, or the path of the binary in the image of layout.
+	path string
+	// layout is the OCI layout directory or tarball, empty for local executables.
+	layout  string
+	archive bool
+}
+
+func parseExternalBinarySource(value string) (externalBinarySource, error) {
+	name, source, ok := strings.Cut(value, "=")
+	if !ok || len(name) == 0 || len(source) == 0 {
+		return externalBinarySource{}, fmt.Errorf("invalid external binary %q, expected NAME=PATH, NAME=oci:DIR:PATH or NAME=oci-archive:FILE:PATH", value)
+	}
+
+	s := externalBinarySource{name: name, path: source}
+	for prefix, archive := range map[string]bool{"oci:": false, "oci-archive:": true} {
+		if !strings.HasPrefix(source, prefix) {
+			continue
+		}
+		layout, binary, ok := strings.Cut(strings.TrimPrefix(source, prefix), ":")
+		if !ok || len(layout) == 0 || !strings.HasPrefix(binary, "/") {
+			return externalBinarySource{}, fmt.Errorf("invalid external binary %q, expected an absolute path to the binary in the image after %s%s:", value, prefix, layout)
+		}
+		s.layout, s.path, s.archive = layout, binary, archive
+	}
+	return s, nil
+}
+
+func parseExternalBinarySources(values []string) ([]externalBinarySource, error) {
+	var sources []externalBinarySource
+	names := map[string]bool{}
+	for _, value := range values {
+		source, err := parseExternalBinarySource(value)
+		if err != nil {
+			return nil, err
+		}
+		if names[source.name] {
+			return nil, fmt.Errorf("external binary %s is given more than once", source.name)
+		}
+		names[source.name] = true
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func (s externalBinarySource) String() string {
+	if len(s.layout) == 0 {
+		return fmt.Sprintf("%s (%s)", s.name, s.path)
+	}
+	return fmt.Sprintf("%s (%s from %s)", s.name, s.path, s.layout)
+}
+
+// resolve returns the path of the executable, extracting it from the image into the cache if needed.
+func (s externalBinarySource) resolve(cache *externalBinaryCache) (string, error) {
+	if len(s.layout) == 0 {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return "", err
+		}
+		if info.IsDir() || info.Mode()&0111 == 0 {
+			return "", fmt.Errorf("%s is not an executable", s.path)
+		}
+		return s.path, nil
+	}
+
+	var layout ociLayout = ociLayoutDir(s.layout)
+	if s.archive {
+		layout = ociLayoutArchive(s.layout)
+	}
+	manifestDigest, manifest, err := readOCIManifest(layout)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the image of %s: %w", s.layout, err)
+	}
+	return cache.get(manifestDigest, s.path, func(dst string) error {
+		return extractFromOCILayers(layout, manifest.Layers, s.path, dst)
+	})
+}
+
+// externalBinaryCache keeps the binaries extracted from images by the digest of the image, so that
+// repeated runs against the same images do not extract them again.
+type externalBinaryCache struct {
+	dir string
+}
+
+// newExternalBinaryCache returns the cache in the cache directory of the user, or nil if there is none.
+func newExternalBinaryCache() *externalBinaryCache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return &externalBinaryCache{dir: filepath.Join(dir, "openshift-tests", "external-binaries")}
+}
+
+// get returns the binary at path in the image with the given digest, calling extract to extract it into
+// a directory of the cache if it is not cached yet.  Without a cache the binary is extracted into a
+// temporary directory.
+func (c *externalBinaryCache) get(imageDigest digest.Digest, binary string, extract func(dst string) error) (string, error) {
+	if c == nil || imageDigest.Validate() != nil {
+		dst, err := os.MkdirTemp("", "external-binary")
+		if err != nil {
+			return "", err
+		}
+		return extractExecutable(dst, binary, extract)
+	}
+
+	// the path in the image is part of the key, as an image may contain several binaries
+	imageDir := filepath.Join(c.dir, imageDigest.Algorithm().String(), imageDigest.Encoded(), filepath.FromSlash(path.Clean("/"+binary)))
+	cached := filepath.Join(imageDir, path.Base(binary))
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(imageDir), 0755); err != nil {
+		return "", err
+	}
+	dst, err := os.MkdirTemp(filepath.Dir(imageDir), ".extract")
+	if err != nil {
+		return "", err
+	}
+	if _, err := extractExecutable(dst, binary, extract); err != nil {
+		os.RemoveAll(dst)
+		return "", err
+	}
+	// a concurrent run may have cached the same binary in the meantime, in which case its copy is used
+	if err := os.Rename(dst, imageDir); err != nil {
+		os.RemoveAll(dst)
+		if _, statErr := os.Stat(cached); statErr != nil {
+			return "", err
+		}
+	}
+	return cached, nil
+}
+
+func extractExecutable(dst, binary string, extract func(dst string) error) (string, error) {
+	if err := extract(dst); err != nil {
+		return "", err
+	}
+	extractedBinary := filepath.Join(dst, path.Base(binary))
+	if err := os.Chmod(extractedBinary, 0755); err != nil {
+		return "", fmt.Errorf("failed making the extracted binary executable: %w", err)
+	}
+	return extractedBinary, nil
+}
+
+// ociLayout opens the files of an OCI image layout.
+type ociLayout interface {
+	open(name string) (io.ReadCloser, error)
+}
+
+// ociLayoutDir is an OCI image layout directory.
+type ociLayoutDir string
+
+func (d ociLayoutDir) open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// ociLayoutArchive is a tarball of an OCI image layout, optionally gzipped.
+type ociLayoutArchive string
+
+func (a ociLayoutArchive) open(name string) (io.ReadCloser, error) {
+	f, err := os.Open(string(a))
+	if err != nil {
+		return nil, err
+	}
+	r, err := maybeGunzip(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			f.Close()
+			return nil, fmt.Errorf("%s not found in %s", name, string(a))
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if cleanTarPath(header.Name) == name {
+			return readCloser{Reader: tr, Closer: f}, nil
+		}
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// readOCIManifest returns the manifest of the image of the layout for this platform and its digest.
+func readOCIManifest(layout ociLayout) (digest.Digest, *imagespecv1.Manifest, error) {
+	index := &imagespecv1.Index{}
+	if err := readOCIJSON(layout, "index.json", index); err != nil {
+		return "", nil, err
+	}
+	for {
+		descriptor, err := platformManifest(index.Manifests)
+		if err != nil {
+			return "", nil, err
+		}
+		if descriptor.MediaType != imagespecv1.MediaTypeImageIndex {
+			manifest := &imagespecv1.Manifest{}
+			if err := readOCIJSON(layout, ociBlobPath(descriptor.Digest), manifest); err != nil {
+				return "", nil, err
+			}
+			return descriptor.Digest, manifest, nil
+		}
+		index = &imagespecv1.Index{}
+		if err := readOCIJSON(layout, ociBlobPath(descriptor.Digest), index); err != nil {
+			return "", nil, err
+		}
+	}
+}
+
+// platformManifest returns the only manifest, or the manifest for the platform of this process.
+func platformManifest(manifests []imagespecv1.Descriptor) (imagespecv1.Descriptor, error) {
+	switch len(manifests) {
+	case 0:
+		return imagespecv1.Descriptor{}, fmt.Errorf("the image index contains no manifest")
+	case 1:
+		return manifests[0], nil
+	}
+	for _, manifest := range manifests {
+		if manifest.Platform != nil && manifest.Platform.OS == runtime.GOOS && manifest.Platform.Architecture == runtime.GOARCH {
+			return manifest, nil
+		}
+	}
+	return imagespecv1.Descriptor{}, fmt.Errorf("the image index contains no manifest for %s/%s", runtime.GOOS, runtime.GOARCH)
+}
+
+func ociBlobPath(d digest.Digest) string {
+	return path.Join("blobs", d.Algorithm().String(), d.Encoded())
+}
+
+func readOCIJSON(layout ociLayout, name string, obj interface{}) error {
+	r, err := layout.open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(obj); err != nil {
+		return fmt.Errorf("failed parsing %s: %w", name, err)
+	}
+	return nil
+}
+
+// extractFromOCILayers writes the file at binary in the image made of layers to dst.  The layers are
+// searched from the topmost one, and a file deleted by a whiteout in an upper layer is not found.
+func extractFromOCILayers(layout ociLayout, layers []imagespecv1.Descriptor, binary, dst string) error {
+	name := cleanTarPath(binary)
+	whiteout := path.Join(path.Dir(name), ".wh."+path.Base(name))
+	for i := len(layers) - 1; i >= 0; i-- {
+		found, err := extractFromOCILayer(layout, layers[i], name, whiteout, filepath.Join(dst, path.Base(name)))
+		if err != nil {
+			return fmt.Errorf("failed reading layer %s: %w", layers[i].Digest, err)
+		}
+		if found {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s not found in the image", binary)
+}
+
+func extractFromOCILayer(layout ociLayout, layer imagespecv1.Descriptor, name, whiteout, dst string) (bool, error) {
+	blob, err := layout.open(ociBlobPath(layer.Digest))
+	if err != nil {
+		return false, err
+	}
+	defer blob.Close()
+	r, err := maybeGunzip(blob)
+	if err != nil {
+		return false, err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch cleanTarPath(header.Name) {
+		case whiteout:
+			return false, fmt.Errorf("%s was deleted from the image", "/"+name)
+		case name:
+			if header.Typeflag != tar.TypeReg {
+				return false, fmt.Errorf("%s is not a regular file in the image", "/"+name)
+			}
+			f, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+			if err != nil {
+				return false, err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return false, err
+			}
+			return true, f.Close()
+		}
+	}
+}
+
+// maybeGunzip decompresses r if it is gzipped, which layers and layout tarballs may or may not be.
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+func cleanTarPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
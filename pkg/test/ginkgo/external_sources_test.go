@@ -0,0 +1,289 @@
+package ginkgo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	imagespecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// fakeExternalBinary speaks version 1 of the external binary contract.
+const fakeExternalBinary = `#!/bin/sh
+case "$1" in
+info) echo '{"apiVersion":"v1","name":"fake-tests","version":"1.0"}' ;;
+list) echo '[{"name":"[sig-fake] passes","labels":" [Suite:openshift/conformance/parallel]"},{"name":"[sig-fake] fails","timeout":"5m"}]' ;;
+run-test)
+  [ "$2" = "--output=json" ] || exit 1
+  case "$3" in
+  *passes) echo "running $3"; echo '{"name":"[sig-fake] passes","result":"passed"}' ;;
+  *) echo "running $3"; echo '{"name":"[sig-fake] fails","result":"failed","error":"expected true"}'; exit 1 ;;
+  esac ;;
+*) exit 1 ;;
+esac
+`
+
+func Test_parseExternalBinarySource(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected externalBinarySource
+		wantErr  bool
+	}{
+		{value: "k8s-tests=/tmp/k8s-tests", expected: externalBinarySource{name: "k8s-tests", path: "/tmp/k8s-tests"}},
+		{value: "mco-tests=oci:/tmp/mco:/usr/bin/mco-tests", expected: externalBinarySource{name: "mco-tests", path: "/usr/bin/mco-tests", layout: "/tmp/mco"}},
+		{value: "mco-tests=oci-archive:/tmp/mco.tar:/usr/bin/mco-tests", expected: externalBinarySource{name: "mco-tests", path: "/usr/bin/mco-tests", layout: "/tmp/mco.tar", archive: true}},
+		{value: "/tmp/k8s-tests", wantErr: true},
+		{value: "mco-tests=oci:/tmp/mco", wantErr: true},
+		{value: "mco-tests=oci:/tmp/mco:usr/bin/mco-tests", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseExternalBinarySource(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_externalTestsOfSource(t *testing.T) {
+	dir := t.TempDir()
+	testBinary := filepath.Join(dir, "fake-tests")
+	if err := os.WriteFile(testBinary, []byte(fakeExternalBinary), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	binary := externalBinary{source: &externalBinarySource{name: "fake-tests", path: testBinary}}
+	tests, err := binary.externalTests(context.TODO(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 2 || tests[0].name != "[sig-fake] passes [Suite:openshift/conformance/parallel]" || tests[1].testTimeout != 5*time.Minute {
+		t.Fatalf("unexpected tests %#v", tests)
+	}
+
+	c := &commandContext{timeout: time.Minute}
+	result := c.RunTestInNewProcess(context.TODO(), tests[0])
+	if result.testState != TestSucceeded || string(result.testOutputBytes) != "running [sig-fake] passes\n" {
+		t.Errorf("expected the test to pass, got %s: %q", result.testState, string(result.testOutputBytes))
+	}
+	result = c.RunTestInNewProcess(context.TODO(), tests[1])
+	if result.testState != TestFailed || string(result.testOutputBytes) != "running [sig-fake] fails\nexpected true\n" {
+		t.Errorf("expected the test to fail, got %s: %q", result.testState, string(result.testOutputBytes))
+	}
+}
+
+func Test_withExternalBinarySources(t *testing.T) {
+	payload := []externalBinary{
+		{imageTag: "hyperkube", path: "/usr/bin/k8s-tests", required: true},
+		{imageTag: "tests", path: "/usr/bin/openshift-tests-ext"},
+		{imageTag: "cluster-storage-operator", path: "/usr/bin/storage-tests"},
+	}
+	sources := []externalBinarySource{
+		{name: "openshift-tests-ext", path: "/tmp/openshift-tests-ext"},
+		{name: "new-tests", layout: "/tmp/layout", path: "/usr/bin/new-tests"},
+	}
+	var got []string
+	for _, binary := range withExternalBinarySources(payload, sources) {
+		got = append(got, fmt.Sprintf("%s required=%v", binary, binary.required))
+	}
+	expected := []string{
+		"/usr/bin/k8s-tests from hyperkube required=true",
+		"openshift-tests-ext (/tmp/openshift-tests-ext) required=false",
+		"/usr/bin/storage-tests from cluster-storage-operator required=false",
+		"new-tests (/usr/bin/new-tests from /tmp/layout) required=true",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if !requiresReleasePayload(sources) {
+		t.Errorf("expected the release payload to be read for k8s-tests")
+	}
+	if requiresReleasePayload(append(sources, externalBinarySource{name: "k8s-tests", path: "/tmp/k8s-tests"})) {
+		t.Errorf("expected the release payload not to be read when every required binary is given")
+	}
+
+	if _, err := parseExternalBinarySources([]string{"k8s-tests=/a", "k8s-tests=/b"}); err == nil {
+		t.Errorf("expected a binary given twice to be rejected")
+	}
+}
+
+func Test_externalBinarySource_resolve(t *testing.T) {
+	testCases := []struct {
+		name    string
+		archive bool
+		layers  []map[string]string
+		wantErr bool
+	}{
+		{
+			name: "directory",
+			layers: []map[string]string{
+				{"usr/bin/fake-tests": "old"},
+				{"./usr/bin/fake-tests": fakeExternalBinary},
+				{"etc/motd": "hello"},
+			},
+		},
+		{
+			name:    "archive",
+			archive: true,
+			layers: []map[string]string{
+				{"usr/bin/fake-tests": fakeExternalBinary},
+			},
+		},
+		{
+			name: "deleted",
+			layers: []map[string]string{
+				{"usr/bin/fake-tests": fakeExternalBinary},
+				{"usr/bin/.wh.fake-tests": ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing",
+			layers: []map[string]string{
+				{"usr/bin/other-tests": fakeExternalBinary},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			layoutDir := filepath.Join(dir, "layout")
+			layerBlobs := writeOCILayout(t, layoutDir, tc.layers)
+			source := externalBinarySource{name: "fake-tests", path: "/usr/bin/fake-tests", layout: layoutDir}
+			if tc.archive {
+				source.layout, source.archive = filepath.Join(dir, "layout.tar"), true
+				writeTar(t, source.layout, layoutDir)
+			}
+			cache := &externalBinaryCache{dir: filepath.Join(dir, "cache")}
+
+			testBinary, err := source.resolve(cache)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if data, err := os.ReadFile(testBinary); err != nil || string(data) != fakeExternalBinary {
+				t.Fatalf("unexpected binary %q: %v", string(data), err)
+			}
+			if !strings.HasPrefix(testBinary, cache.dir) {
+				t.Errorf("expected the binary to be extracted into the cache, got %s", testBinary)
+			}
+
+			// the layers are not read again once the binary is cached
+			if !tc.archive {
+				for _, blob := range layerBlobs {
+					os.Remove(blob)
+				}
+			}
+			cached, err := source.resolve(cache)
+			if err != nil || cached != testBinary {
+				t.Errorf("expected the cached binary %s, got %s: %v", testBinary, cached, err)
+			}
+		})
+	}
+}
+
+// writeOCILayout writes an OCI layout of an image made of layers of file contents by path, and returns
+// the paths of the layer blobs.
+func writeOCILayout(t *testing.T, dir string, layers []map[string]string) []string {
+	writeBlob := func(data []byte) digest.Digest {
+		d := digest.FromBytes(data)
+		blob := filepath.Join(dir, "blobs", "sha256", d.Encoded())
+		if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(blob, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	marshal := func(obj interface{}) []byte {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	manifest := imagespecv1.Manifest{
+		Config: imagespecv1.Descriptor{MediaType: imagespecv1.MediaTypeImageConfig, Digest: writeBlob([]byte("{}"))},
+	}
+	var blobs []string
+	for i, files := range layers {
+		buf := &bytes.Buffer{}
+		var w io.Writer = buf
+		gz := gzip.NewWriter(buf)
+		// alternate compressed and uncompressed layers
+		if i%2 == 0 {
+			w = gz
+		}
+		tw := tar.NewWriter(w)
+		for name, content := range files {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(content))}); err != nil {
+				t.Fatal(err)
+			}
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+		if i%2 == 0 {
+			gz.Close()
+		}
+		d := writeBlob(buf.Bytes())
+		blobs = append(blobs, filepath.Join(dir, "blobs", "sha256", d.Encoded()))
+		manifest.Layers = append(manifest.Layers, imagespecv1.Descriptor{MediaType: imagespecv1.MediaTypeImageLayerGzip, Digest: d})
+	}
+	index := imagespecv1.Index{
+		Manifests: []imagespecv1.Descriptor{{MediaType: imagespecv1.MediaTypeImageManifest, Digest: writeBlob(marshal(manifest))}},
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), marshal(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return blobs
+}
+
+// writeTar writes the files of dir to a tarball.
+func writeTar(t *testing.T, tarball, dir string) {
+	f, err := os.Create(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	defer tw.Close()
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, _ := filepath.Rel(dir, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: filepath.ToSlash(name), Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}); err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
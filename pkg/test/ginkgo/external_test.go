@@ -0,0 +1,138 @@
+package ginkgo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func Test_externalBinaries(t *testing.T) {
+	references := &imagev1.ImageStream{
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{Name: "hyperkube", Annotations: map[string]string{externalBinaryAnnotation: "/usr/bin/k8s-tests"}},
+				{Name: "machine-config-operator", Annotations: map[string]string{externalBinaryAnnotation: "/usr/bin/mco-tests, /usr/bin/mcd-tests"}},
+				{Name: "cli"},
+			},
+		},
+	}
+	expected := []externalBinary{
+		{imageTag: "hyperkube", path: "/usr/bin/k8s-tests", required: true},
+		{imageTag: "machine-config-operator", path: "/usr/bin/mco-tests"},
+		{imageTag: "machine-config-operator", path: "/usr/bin/mcd-tests"},
+	}
+	if got := externalBinaries(references); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func Test_parseExternalTests(t *testing.T) {
+	testCases := []struct {
+		name       string
+		apiVersion string
+		output     string
+		expected   []*testCase
+		wantErr    bool
+	}{
+		{
+			name:   "legacy",
+			output: "I1018 12:00:00.000000 init\n" + `[{"Name":"[sig-node] pods run","Labels":" [Suite:k8s]"}]` + "\n",
+			expected: []*testCase{
+				{name: "[sig-node] pods run [Suite:k8s]", rawName: "[sig-node] pods run", binaryName: "/tmp/k8s-tests"},
+			},
+		},
+		{
+			name:       "v1",
+			apiVersion: "v1",
+			output:     `[{"name":"[sig-mco] nodes update","labels":" [Suite:openshift/conformance/serial]","timeout":"30m"},{"name":"[sig-mco] config renders"}]`,
+			expected: []*testCase{
+				{name: "[sig-mco] nodes update [Suite:openshift/conformance/serial]", rawName: "[sig-mco] nodes update", binaryName: "/tmp/k8s-tests", binaryAPIVersion: "v1", testTimeout: 30 * time.Minute},
+				{name: "[sig-mco] config renders", rawName: "[sig-mco] config renders", binaryName: "/tmp/k8s-tests", binaryAPIVersion: "v1"},
+			},
+		},
+		{
+			name:       "v1 with an invalid timeout",
+			apiVersion: "v1",
+			output:     `[{"name":"[sig-mco] nodes update","timeout":"soon"}]`,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseExternalTests("/tmp/k8s-tests", tc.apiVersion, []byte(tc.output))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_parseExternalBinaryInfo(t *testing.T) {
+	info, err := parseExternalBinaryInfo([]byte("starting\n" + `{"apiVersion":"v1","name":"mco-tests","version":"4.18.0"}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "mco-tests" || info.Version != "4.18.0" {
+		t.Errorf("unexpected info %#v", info)
+	}
+	if _, err := parseExternalBinaryInfo([]byte(`{"apiVersion":"v2","name":"mco-tests"}`)); err == nil {
+		t.Errorf("expected an unsupported apiVersion to fail")
+	}
+}
+
+func Test_externalTestState(t *testing.T) {
+	testCases := []struct {
+		name           string
+		output         string
+		expectedState  TestState
+		expectedOutput string
+		expectedOK     bool
+	}{
+		{
+			name:           "passed",
+			output:         "step 1\nstep 2\n" + `{"name":"test","result":"passed"}` + "\n",
+			expectedState:  TestSucceeded,
+			expectedOutput: "step 1\nstep 2\n",
+			expectedOK:     true,
+		},
+		{
+			name:           "failed with an error",
+			output:         "step 1\n" + `{"name":"test","result":"failed","error":"expected 1, got 2"}`,
+			expectedState:  TestFailed,
+			expectedOutput: "step 1\nexpected 1, got 2\n",
+			expectedOK:     true,
+		},
+		{
+			name:           "timed out",
+			output:         `{"name":"test","result":"timedout"}`,
+			expectedState:  TestFailedTimeout,
+			expectedOutput: "",
+			expectedOK:     true,
+		},
+		{
+			name:           "crashed",
+			output:         "panic: runtime error\n",
+			expectedState:  TestUnknown,
+			expectedOutput: "panic: runtime error\n",
+		},
+		{
+			name:           "unknown result",
+			output:         `{"name":"test","result":"maybe"}`,
+			expectedState:  TestUnknown,
+			expectedOutput: `{"name":"test","result":"maybe"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, output, ok := externalTestState([]byte(tc.output))
+			if state != tc.expectedState || string(output) != tc.expectedOutput || ok != tc.expectedOK {
+				t.Errorf("expected %s %q %t, got %s %q %t", tc.expectedState, tc.expectedOutput, tc.expectedOK, state, string(output), ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	mcclient "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// clusterHealthGateInterval is how often the health of the cluster is checked while waiting.
+const clusterHealthGateInterval = 10 * time.Second
+
+// clusterHealthGate waits between the buckets of a run for the cluster to be healthy, so that a test
+// that breaks the cluster is reported after its bucket instead of failing the tests of every bucket
+// that follows.  The cluster is healthy when every cluster operator is Available and not Degraded,
+// every node is Ready and every machine config pool is Updated, neither Updating nor Degraded.
+type clusterHealthGate struct {
+	timeout  time.Duration
+	interval time.Duration
+	recorder monitorapi.RecorderWriter
+	out      io.Writer
+	// unhealthy returns the unhealthy conditions of the cluster, none if it is healthy.
+	unhealthy func(ctx context.Context) ([]string, error)
+
+	results []*junitapi.JUnitTestCase
+}
+
+func newClusterHealthGate(restConfig *rest.Config, timeout time.Duration, recorder monitorapi.RecorderWriter, out io.Writer) (*clusterHealthGate, error) {
+	configClient, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	machineConfigClient, err := mcclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &clusterHealthGate{
+		timeout:  timeout,
+		interval: clusterHealthGateInterval,
+		recorder: recorder,
+		out:      out,
+		unhealthy: func(ctx context.Context) ([]string, error) {
+			return readUnhealthyConditions(ctx, configClient, kubeClient, machineConfigClient)
+		},
+	}, nil
+}
+
+// After waits for the cluster to be healthy after the tests of a bucket ran, and records a synthetic
+// test for the bucket.  If the cluster is still unhealthy after the timeout, the synthetic test fails
+// and the unhealthy conditions are recorded as an interval.  Nothing is checked for empty buckets or
+// once the run is aborted.
+func (g *clusterHealthGate) After(ctx context.Context, bucket testBucket, tests []*testCase) {
+	if g == nil || len(tests) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	start := time.Now()
+	var unhealthy []string
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, g.interval, g.timeout, true, func(ctx context.Context) (bool, error) {
+		unhealthy, lastErr = g.unhealthy(ctx)
+		// the cluster may not answer while it recovers, so errors are only reported on timeout
+		return lastErr == nil && len(unhealthy) == 0, nil
+	})
+	testName := fmt.Sprintf("[sig-arch] cluster should be healthy after the %s tests", bucket)
+	if err == nil {
+		g.results = append(g.results, &junitapi.JUnitTestCase{
+			Name:     testName,
+			Duration: time.Since(start).Seconds(),
+		})
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if lastErr != nil {
+		unhealthy = append(unhealthy, fmt.Sprintf("unable to read the health of the cluster: %v", lastErr))
+	}
+	end := time.Now()
+	output := fmt.Sprintf("The cluster was not healthy %s after the %s tests finished, the tests that follow may fail because of it:\n\n%s",
+		g.timeout, bucket, strings.Join(unhealthy, "\n"))
+	fmt.Fprintf(g.out, "%s\n\n", output)
+	g.recorder.AddIntervals(
+		monitorapi.NewInterval(monitorapi.SourceClusterHealthGate, monitorapi.Error).
+			Locator(monitorapi.NewLocator().TestRunner("health-gate")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.ClusterHealthGateFailedReason).
+				HumanMessage(fmt.Sprintf("cluster not healthy after the %s tests: %s", bucket, strings.Join(unhealthy, "; ")))).
+			Display().
+			Build(start, end),
+	)
+	g.results = append(g.results, &junitapi.JUnitTestCase{
+		Name:          testName,
+		Duration:      end.Sub(start).Seconds(),
+		SystemOut:     output,
+		FailureOutput: &junitapi.FailureOutput{Output: output},
+	})
+}
+
+// Results returns the synthetic tests of the buckets the gate checked.
+func (g *clusterHealthGate) Results() []*junitapi.JUnitTestCase {
+	if g == nil {
+		return nil
+	}
+	return g.results
+}
+
+// readUnhealthyConditions reads the cluster operators, nodes and machine config pools.  Clusters
+// without cluster operators or machine config pools, e.g. MicroShift or HyperShift, are only checked
+// for what they have.
+func readUnhealthyConditions(ctx context.Context, configClient configclient.Interface, kubeClient kubernetes.Interface, machineConfigClient mcclient.Interface) ([]string, error) {
+	var operators []configv1.ClusterOperator
+	operatorList, err := configClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		operators = operatorList.Items
+	}
+
+	nodeList, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var pools []mcv1.MachineConfigPool
+	poolList, err := machineConfigClient.MachineconfigurationV1().MachineConfigPools().List(ctx, metav1.ListOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		pools = poolList.Items
+	}
+
+	return unhealthyConditions(operators, nodeList.Items, pools), nil
+}
+
+// unhealthyConditions returns a sorted line for every condition that keeps the cluster from being healthy.
+func unhealthyConditions(operators []configv1.ClusterOperator, nodes []corev1.Node, pools []mcv1.MachineConfigPool) []string {
+	var unhealthy []string
+	for _, operator := range operators {
+		if condition := v1helpers.FindStatusCondition(operator.Status.Conditions, configv1.OperatorAvailable); condition == nil || condition.Status != configv1.ConditionTrue {
+			unhealthy = append(unhealthy, fmt.Sprintf("clusteroperator/%s is not Available%s", operator.Name, operatorConditionDetails(condition)))
+		}
+		if condition := v1helpers.FindStatusCondition(operator.Status.Conditions, configv1.OperatorDegraded); condition != nil && condition.Status == configv1.ConditionTrue {
+			unhealthy = append(unhealthy, fmt.Sprintf("clusteroperator/%s is Degraded%s", operator.Name, operatorConditionDetails(condition)))
+		}
+	}
+	for _, node := range nodes {
+		var ready *corev1.NodeCondition
+		for i := range node.Status.Conditions {
+			if node.Status.Conditions[i].Type == corev1.NodeReady {
+				ready = &node.Status.Conditions[i]
+			}
+		}
+		switch {
+		case ready == nil:
+			unhealthy = append(unhealthy, fmt.Sprintf("node/%s is not Ready", node.Name))
+		case ready.Status != corev1.ConditionTrue:
+			unhealthy = append(unhealthy, fmt.Sprintf("node/%s is not Ready: %s: %s", node.Name, ready.Reason, ready.Message))
+		}
+	}
+	for _, pool := range pools {
+		for _, condition := range pool.Status.Conditions {
+			switch {
+			case condition.Type == mcv1.MachineConfigPoolUpdated && condition.Status != corev1.ConditionTrue:
+				unhealthy = append(unhealthy, fmt.Sprintf("machineconfigpool/%s is not Updated: %d of %d machines updated", pool.Name, pool.Status.UpdatedMachineCount, pool.Status.MachineCount))
+			case condition.Type == mcv1.MachineConfigPoolUpdating && condition.Status == corev1.ConditionTrue:
+				unhealthy = append(unhealthy, fmt.Sprintf("machineconfigpool/%s is Updating: %s", pool.Name, condition.Message))
+			case condition.Type == mcv1.MachineConfigPoolDegraded && condition.Status == corev1.ConditionTrue:
+				unhealthy = append(unhealthy, fmt.Sprintf("machineconfigpool/%s is Degraded: %s", pool.Name, condition.Message))
+			}
+		}
+	}
+	sort.Strings(unhealthy)
+	return unhealthy
+}
+
+func operatorConditionDetails(condition *configv1.ClusterOperatorStatusCondition) string {
+	if condition == nil {
+		return ""
+	}
+	return fmt.Sprintf(": %s: %s", condition.Reason, condition.Message)
+}
@@ -0,0 +1,151 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestUnhealthyConditions(t *testing.T) {
+	operators := []configv1.ClusterOperator{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "etcd"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse, Reason: "NoRouters", Message: "0 of 2 routers available"},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Reason: "Unavailable", Message: "routers unavailable"},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "console"},
+		},
+	}
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-0"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-0"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Reason: "NodeStatusUnknown", Message: "Kubelet stopped posting node status."},
+			}},
+		},
+	}
+	pools := []mcv1.MachineConfigPool{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master"},
+			Status: mcv1.MachineConfigPoolStatus{Conditions: []mcv1.MachineConfigPoolCondition{
+				{Type: mcv1.MachineConfigPoolUpdated, Status: corev1.ConditionTrue},
+				{Type: mcv1.MachineConfigPoolUpdating, Status: corev1.ConditionFalse},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Status: mcv1.MachineConfigPoolStatus{
+				MachineCount:        3,
+				UpdatedMachineCount: 1,
+				Conditions: []mcv1.MachineConfigPoolCondition{
+					{Type: mcv1.MachineConfigPoolUpdated, Status: corev1.ConditionFalse},
+					{Type: mcv1.MachineConfigPoolUpdating, Status: corev1.ConditionTrue, Message: "All nodes are updating to rendered-worker-abc"},
+				},
+			},
+		},
+	}
+
+	expected := []string{
+		"clusteroperator/console is not Available",
+		"clusteroperator/ingress is Degraded: Unavailable: routers unavailable",
+		"clusteroperator/ingress is not Available: NoRouters: 0 of 2 routers available",
+		"machineconfigpool/worker is Updating: All nodes are updating to rendered-worker-abc",
+		"machineconfigpool/worker is not Updated: 1 of 3 machines updated",
+		"node/worker-0 is not Ready: NodeStatusUnknown: Kubelet stopped posting node status.",
+	}
+	if got := unhealthyConditions(operators, nodes, pools); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if got := unhealthyConditions(operators[:1], nodes[:1], pools[:1]); len(got) > 0 {
+		t.Errorf("expected a healthy cluster, got:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestClusterHealthGate(t *testing.T) {
+	recorder := monitor.NewRecorder()
+	checks := 0
+	gate := &clusterHealthGate{
+		timeout:  50 * time.Millisecond,
+		interval: time.Millisecond,
+		recorder: recorder,
+		out:      io.Discard,
+		unhealthy: func(ctx context.Context) ([]string, error) {
+			checks++
+			switch {
+			case checks == 1:
+				return nil, fmt.Errorf("connection refused")
+			case checks == 2:
+				return []string{"node/worker-0 is not Ready"}, nil
+			case checks == 3:
+				return nil, nil
+			default:
+				return []string{"clusteroperator/ingress is Degraded"}, nil
+			}
+		},
+	}
+	tests := []*testCase{{name: "test"}}
+
+	start := time.Now()
+	gate.After(context.Background(), earlyBucket, tests)
+	// empty buckets are not checked
+	gate.After(context.Background(), kubeBucket, nil)
+	gate.After(context.Background(), storageBucket, tests)
+
+	results := gate.Results()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Name != "[sig-arch] cluster should be healthy after the early tests" || results[0].FailureOutput != nil {
+		t.Errorf("expected the early tests to pass the gate after recovering, got %#v", results[0])
+	}
+	if results[1].Name != "[sig-arch] cluster should be healthy after the storage tests" || results[1].FailureOutput == nil ||
+		!strings.Contains(results[1].FailureOutput.Output, "clusteroperator/ingress is Degraded") {
+		t.Errorf("expected the storage tests to fail the gate, got %#v", results[1])
+	}
+
+	intervals := recorder.Intervals(start, time.Now())
+	if len(intervals) != 1 {
+		t.Fatalf("expected 1 interval, got %d", len(intervals))
+	}
+	if intervals[0].Source != monitorapi.SourceClusterHealthGate || intervals[0].Message.Reason != monitorapi.ClusterHealthGateFailedReason ||
+		!strings.Contains(intervals[0].Message.HumanMessage, "clusteroperator/ingress is Degraded") {
+		t.Errorf("unexpected interval %#v", intervals[0])
+	}
+
+	// nothing is checked once the run is aborted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	gate.After(ctx, lateBucket, tests)
+	if len(gate.Results()) != 2 {
+		t.Errorf("expected no result for an aborted run, got %d", len(gate.Results()))
+	}
+}
@@ -0,0 +1,123 @@
+package ginkgo
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// impactLabelRegexp matches the labels that identify the owner or feature of a test.  A test
+// sharing one of these labels with a test whose source changed is considered affected as well.
+var impactLabelRegexp = regexp.MustCompile(`\[(sig-[^\]]+|Feature:[^\]]+)\]`)
+
+// testImpact selects the tests affected by a set of changed files, so that a change to a single
+// area of the tests does not require running the whole suite to validate it.
+type testImpact struct {
+	// dirs are the repository relative directories of the changed go files under test/extended
+	// and pkg.  Tests are compiled per package, so a changed file affects every spec in its
+	// directory.
+	dirs []string
+}
+
+// newTestImpact maps changed repository relative paths to the package directories they belong to.
+// Changes outside of test/extended and pkg, and to anything but go files, do not affect any test.
+func newTestImpact(changedFiles []string) *testImpact {
+	dirs := map[string]struct{}{}
+	for _, file := range changedFiles {
+		file = path.Clean(strings.TrimPrefix(strings.TrimSpace(file), "./"))
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		if !strings.HasPrefix(file, "test/extended/") && !strings.HasPrefix(file, "pkg/") {
+			continue
+		}
+		dirs[path.Dir(file)] = struct{}{}
+	}
+	impact := &testImpact{}
+	for dir := range dirs {
+		impact.dirs = append(impact.dirs, dir)
+	}
+	sort.Strings(impact.dirs)
+	return impact
+}
+
+// changedFilesSince returns the files changed in the working tree of the current directory since
+// the given git ref, relative to the root of the repository.
+func changedFilesSince(ref string) ([]string, error) {
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command("git", "diff", "--name-only", ref)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not list the files changed since %s: %v: %s", ref, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// changedDirsOf returns the changed directories the spec or any of its containers is defined in.
+// Vendored tests are never matched, their paths would otherwise collide with pkg.
+func (i *testImpact) changedDirsOf(test *testCase) []string {
+	var dirs []string
+	for _, location := range test.locations {
+		if strings.Contains(location.FileName, "/vendor/") {
+			continue
+		}
+		dir := path.Dir(location.FileName)
+		for _, changed := range i.dirs {
+			if dir == changed || strings.HasSuffix(dir, "/"+changed) {
+				dirs = append(dirs, changed)
+			}
+		}
+	}
+	return dirs
+}
+
+// Filter returns the tests defined in the changed directories, the tests sharing a [sig-*] or
+// [Feature:*] label with them, and every [Early] and [Late] test, which check the cluster as a
+// whole.  The second return value lists the changed directories no test is defined in, which
+// usually hold helpers shared by many tests.
+func (i *testImpact) Filter(tests []*testCase) ([]*testCase, []string) {
+	labels := map[string]struct{}{}
+	located := map[*testCase]struct{}{}
+	matchedDirs := map[string]struct{}{}
+	for _, test := range tests {
+		dirs := i.changedDirsOf(test)
+		if len(dirs) == 0 {
+			continue
+		}
+		located[test] = struct{}{}
+		for _, dir := range dirs {
+			matchedDirs[dir] = struct{}{}
+		}
+		for _, label := range impactLabelRegexp.FindAllString(test.name, -1) {
+			labels[label] = struct{}{}
+		}
+	}
+
+	var unmapped []string
+	for _, dir := range i.dirs {
+		if _, ok := matchedDirs[dir]; !ok {
+			unmapped = append(unmapped, dir)
+		}
+	}
+
+	affected, _ := splitTests(tests, func(t *testCase) bool {
+		if _, ok := located[t]; ok {
+			return true
+		}
+		if strings.Contains(t.name, "[Early]") || strings.Contains(t.name, "[Late]") {
+			return true
+		}
+		for _, label := range impactLabelRegexp.FindAllString(t.name, -1) {
+			if _, ok := labels[label]; ok {
+				return true
+			}
+		}
+		return false
+	})
+	return affected, unmapped
+}
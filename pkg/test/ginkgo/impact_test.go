@@ -0,0 +1,90 @@
+package ginkgo
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func testCaseAt(name string, files ...string) *testCase {
+	test := &testCase{name: name}
+	for _, file := range files {
+		test.locations = append(test.locations, types.CodeLocation{FileName: file, LineNumber: 1})
+	}
+	return test
+}
+
+func Test_testImpact(t *testing.T) {
+	tests := []*testCase{
+		testCaseAt("[sig-network][Feature:Router] router works",
+			"/go/src/github.com/openshift/origin/test/extended/router/router.go"),
+		testCaseAt("[sig-network-edge] other router test",
+			"/go/src/github.com/openshift/origin/test/extended/router/other.go"),
+		testCaseAt("[sig-network][Feature:Router] router metrics",
+			"/go/src/github.com/openshift/origin/test/extended/prometheus/router.go"),
+		testCaseAt("[sig-network] services work",
+			"/go/src/github.com/openshift/origin/test/extended/networking/services.go"),
+		testCaseAt("[sig-apps] deployments work",
+			"/go/src/github.com/openshift/origin/test/extended/deployments/deployments.go"),
+		testCaseAt("[sig-arch][Early] cluster is healthy",
+			"/go/src/github.com/openshift/origin/test/extended/operators/operators.go"),
+		testCaseAt("[sig-arch][Late] no crashlooping pods",
+			"/go/src/github.com/openshift/origin/test/extended/operators/pods.go"),
+		testCaseAt("[sig-storage] vendored test in a pkg directory",
+			"/go/src/github.com/openshift/origin/vendor/k8s.io/kubernetes/test/extended/router/vendored.go"),
+		testCaseAt("[sig-auth] external test without locations"),
+	}
+
+	testCases := []struct {
+		name             string
+		changedFiles     []string
+		expectedTests    []string
+		expectedUnmapped []string
+	}{
+		{
+			name:         "changed test package selects its tests and the tests sharing their labels",
+			changedFiles: []string{"test/extended/router/router.go", "./test/extended/router/README.md"},
+			expectedTests: []string{
+				"[sig-arch][Early] cluster is healthy",
+				"[sig-arch][Late] no crashlooping pods",
+				"[sig-network-edge] other router test",
+				"[sig-network] services work",
+				"[sig-network][Feature:Router] router metrics",
+				"[sig-network][Feature:Router] router works",
+			},
+		},
+		{
+			name:         "changed package without tests is reported",
+			changedFiles: []string{"test/extended/deployments/deployments.go", "test/extended/util/client.go"},
+			expectedTests: []string{
+				"[sig-apps] deployments work",
+				"[sig-arch][Early] cluster is healthy",
+				"[sig-arch][Late] no crashlooping pods",
+			},
+			expectedUnmapped: []string{"test/extended/util"},
+		},
+		{
+			name:         "changes outside of tests only select early and late tests",
+			changedFiles: []string{"docs/README.md", "hack/verify.sh", "cmd/openshift-tests/openshift-tests.go"},
+			expectedTests: []string{
+				"[sig-arch][Early] cluster is healthy",
+				"[sig-arch][Late] no crashlooping pods",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			affected, unmapped := newTestImpact(tc.changedFiles).Filter(tests)
+			names := testNames(affected)
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expectedTests) {
+				t.Errorf("expected tests %v, got %v", tc.expectedTests, names)
+			}
+			if !reflect.DeepEqual(unmapped, tc.expectedUnmapped) {
+				t.Errorf("expected unmapped %v, got %v", tc.expectedUnmapped, unmapped)
+			}
+		})
+	}
+}
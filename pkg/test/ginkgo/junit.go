@@ -1,11 +1,14 @@
 package ginkgo
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +22,7 @@ func generateJUnitTestSuiteResults(
 	name string,
 	duration time.Duration,
 	tests []*testCase,
+	components *ComponentMapping,
 	syntheticTestResults ...*junitapi.JUnitTestCase) *junitapi.JUnitTestSuite {
 
 	s := &junitapi.JUnitTestSuite{
@@ -32,6 +36,7 @@ func generateJUnitTestSuiteResults(
 		},
 	}
 	for _, test := range tests {
+		first := len(s.TestCases)
 		switch {
 		case test.skipped:
 			s.NumTests++
@@ -80,6 +85,9 @@ func generateJUnitTestSuiteResults(
 				Duration: test.duration.Seconds(),
 			})
 		}
+		for _, testCase := range s.TestCases[first:] {
+			testCase.Properties = testCaseProperties(test, components)
+		}
 	}
 	for _, result := range syntheticTestResults {
 		switch {
@@ -88,12 +96,231 @@ func generateJUnitTestSuiteResults(
 		case result.FailureOutput != nil:
 			s.NumFailed++
 		}
+		if !hasTestCaseProperty(result, componentProperty) {
+			result.Properties = append(result.Properties, &junitapi.TestCaseProperty{
+				Name:  componentProperty,
+				Value: components.componentForTest(result.Name),
+			})
+		}
 		s.NumTests++
 		s.TestCases = append(s.TestCases, result)
 	}
 	return s
 }
 
+// The junit properties of test cases, used to route failures to the owners of the tests without
+// parsing their names.
+const (
+	// componentProperty is the component owning the test, see ComponentMapping.componentForTest.
+	componentProperty = "component"
+	// locationProperty is the file:line of the outermost container of the test, absent for external tests.
+	locationProperty = "location"
+	// attemptProperty is the number of the run of the test, starting at 1, greater for retries.
+	attemptProperty = "attempt"
+	// bucketProperty is the group of tests the test ran with, see testBucket.
+	bucketProperty = "bucket"
+)
+
+// testCaseProperties returns the junit properties describing the test and how it ran.
+func testCaseProperties(test *testCase, components *ComponentMapping) []*junitapi.TestCaseProperty {
+	properties := []*junitapi.TestCaseProperty{
+		{Name: componentProperty, Value: components.componentForTest(test.name)},
+	}
+	// the setup nodes of a spec may be listed on either side of it, only the outermost container is
+	// reliably first
+	if len(test.locations) > 0 {
+		properties = append(properties, &junitapi.TestCaseProperty{
+			Name:  locationProperty,
+			Value: test.locations[0].String(),
+		})
+	}
+	properties = append(properties,
+		&junitapi.TestCaseProperty{Name: attemptProperty, Value: strconv.Itoa(test.attempt())},
+		&junitapi.TestCaseProperty{Name: bucketProperty, Value: string(bucketForTest(test))},
+	)
+	if test.quarantine != nil {
+		properties = append(properties, &junitapi.TestCaseProperty{
+			Name:  "quarantined",
+			Value: test.quarantine.String(),
+		})
+	}
+	return properties
+}
+
+func hasTestCaseProperty(testCase *junitapi.JUnitTestCase, name string) bool {
+	for _, property := range testCase.Properties {
+		if property.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadJUnitTestSuites parses a JUnit XML document whose root is either a single testsuite or a
+// testsuites collection.
+func ReadJUnitTestSuites(data []byte) ([]*junitapi.JUnitTestSuite, error) {
+	data = bytes.TrimSpace(data)
+	suites := &junitapi.JUnitTestSuites{}
+	if err := xml.Unmarshal(data, suites); err == nil && len(suites.Suites) > 0 {
+		properties := &struct {
+			Suites []*writtenSuiteProperties `xml:"testsuite"`
+		}{}
+		if err := xml.Unmarshal(data, properties); err != nil {
+			return nil, err
+		}
+		for i, suite := range suites.Suites {
+			suite.Properties = properties.Suites[i].Properties
+		}
+		return suites.Suites, nil
+	}
+	suite := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(data, suite); err != nil {
+		return nil, err
+	}
+	properties := &writtenSuiteProperties{}
+	if err := xml.Unmarshal(data, properties); err != nil {
+		return nil, err
+	}
+	suite.Properties = properties.Properties
+	return []*junitapi.JUnitTestSuite{suite}, nil
+}
+
+// writtenSuiteProperties reads the properties of a suite back.  They are written as property elements of
+// the suite, which the properties field of the JUnitTestSuite does not match when reading.
+type writtenSuiteProperties struct {
+	Properties []*junitapi.TestSuiteProperty `xml:"property"`
+}
+
+// MergeJUnitTestSuites combines the results of several runs of the same suite, for instance the
+// shards of a sharded run, into a single suite.  Test cases are kept as reported, so a test that
+// failed and then passed on retry still appears as a flake, and the counts are recomputed from the
+// merged test cases.  The numbered shards ran side by side and the serial shard after them, so the
+// duration is the longest of the numbered shards plus the serial shard.
+//
+// Every shard runs its own monitor and reports the same synthetic and monitor tests, so a test
+// reported by more than one shard is only kept as reported by the shard with the worst result: a
+// synthetic test failing on one shard and passing on another is a failure, not a flake.
+func MergeJUnitTestSuites(name string, suites ...*junitapi.JUnitTestSuite) *junitapi.JUnitTestSuite {
+	merged := &junitapi.JUnitTestSuite{
+		Name: name,
+	}
+
+	// the shard every test case is kept from, by the shard property of the suites
+	shards := make([]string, len(suites))
+	results := map[string]map[string]junitResult{}
+	for i, suite := range suites {
+		shards[i] = fmt.Sprintf("suite %d", i)
+		for _, property := range suite.Properties {
+			if property.Name == shardProperty {
+				shards[i] = property.Value
+			}
+		}
+		for _, testCase := range suite.TestCases {
+			if results[testCase.Name] == nil {
+				results[testCase.Name] = map[string]junitResult{}
+			}
+			results[testCase.Name][shards[i]] = results[testCase.Name][shards[i]].add(testCase)
+		}
+	}
+	keptShard := map[string]string{}
+	for i := range suites {
+		for _, testCase := range suites[i].TestCases {
+			kept, ok := keptShard[testCase.Name]
+			if !ok || results[testCase.Name][shards[i]].outcome() > results[testCase.Name][kept].outcome() {
+				keptShard[testCase.Name] = shards[i]
+			}
+		}
+	}
+
+	seenProperties := map[string]bool{}
+	var serialDuration float64
+	for i, suite := range suites {
+		switch {
+		case shards[i] == serialShard:
+			serialDuration += suite.Duration
+		case suite.Duration > merged.Duration:
+			merged.Duration = suite.Duration
+		}
+		for _, property := range suite.Properties {
+			if property.Name == shardProperty || seenProperties[property.Name] {
+				continue
+			}
+			seenProperties[property.Name] = true
+			merged.Properties = append(merged.Properties, property)
+		}
+		for _, testCase := range suite.TestCases {
+			if keptShard[testCase.Name] != shards[i] {
+				continue
+			}
+			merged.NumTests++
+			switch {
+			case testCase.SkipMessage != nil:
+				merged.NumSkipped++
+			case testCase.FailureOutput != nil:
+				merged.NumFailed++
+			}
+			merged.TestCases = append(merged.TestCases, testCase)
+		}
+		merged.Children = append(merged.Children, suite.Children...)
+	}
+	merged.Duration += serialDuration
+	return merged
+}
+
+// junitResult is what the test cases of a test reported by a shard add up to.
+type junitResult struct {
+	failed, passed bool
+}
+
+func (r junitResult) add(testCase *junitapi.JUnitTestCase) junitResult {
+	switch {
+	case testCase.SkipMessage != nil:
+	case testCase.FailureOutput != nil:
+		r.failed = true
+	default:
+		r.passed = true
+	}
+	return r
+}
+
+// outcome orders the results from best to worst: skipped, passed, flaked and failed.
+func (r junitResult) outcome() int {
+	switch {
+	case r.failed && !r.passed:
+		return 3
+	case r.failed:
+		return 2
+	case r.passed:
+		return 1
+	}
+	return 0
+}
+
+// FlakyJUnitTestCaseNames returns the names of the tests in the suite that have both a failing and a
+// passing result, and the names of those which only failed.
+func FlakyJUnitTestCaseNames(suite *junitapi.JUnitTestSuite) (flaky, failing []string) {
+	failed, passed := map[string]bool{}, map[string]bool{}
+	for _, testCase := range suite.TestCases {
+		switch {
+		case testCase.SkipMessage != nil:
+		case testCase.FailureOutput != nil:
+			failed[testCase.Name] = true
+		default:
+			passed[testCase.Name] = true
+		}
+	}
+	for name := range failed {
+		if passed[name] {
+			flaky = append(flaky, name)
+		} else {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(flaky)
+	sort.Strings(failing)
+	return flaky, failing
+}
+
 func writeJUnitReport(s *junitapi.JUnitTestSuite, filePrefix, fileSuffix, dir string, errOut io.Writer) error {
 	out, err := xml.MarshalIndent(s, "", "    ")
 	if err != nil {
@@ -1,6 +1,17 @@
 package ginkgo
 
-import "testing"
+import (
+	"encoding/xml"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
 
 func Test_lastLines(t *testing.T) {
 	tests := []struct {
@@ -33,3 +44,169 @@ func Test_lastLines(t *testing.T) {
 		})
 	}
 }
+
+func Test_MergeJUnitTestSuites(t *testing.T) {
+	shard1 := &junitapi.JUnitTestSuite{
+		Name:     "openshift-tests",
+		Duration: 100,
+		Properties: []*junitapi.TestSuiteProperty{
+			{Name: "TestVersion", Value: "v1"},
+			{Name: shardProperty, Value: "1/2"},
+		},
+		TestCases: []*junitapi.JUnitTestCase{
+			{Name: "a", FailureOutput: &junitapi.FailureOutput{Output: "boom"}},
+			{Name: "a"},
+			{Name: "b"},
+			{Name: "synthetic"},
+			{Name: "monitor", FailureOutput: &junitapi.FailureOutput{Output: "boom"}},
+		},
+	}
+	shard2 := &junitapi.JUnitTestSuite{
+		Name:     "openshift-tests",
+		Duration: 200,
+		Properties: []*junitapi.TestSuiteProperty{
+			{Name: "TestVersion", Value: "v1"},
+			{Name: shardProperty, Value: "2/2"},
+		},
+		TestCases: []*junitapi.JUnitTestCase{
+			{Name: "c", FailureOutput: &junitapi.FailureOutput{Output: "boom"}},
+			{Name: "d", SkipMessage: &junitapi.SkipMessage{Message: "skip"}},
+			{Name: "synthetic"},
+			{Name: "monitor", FailureOutput: &junitapi.FailureOutput{Output: "boom"}},
+			{Name: "monitor"},
+		},
+	}
+	serial := &junitapi.JUnitTestSuite{
+		Name:     "openshift-tests",
+		Duration: 50,
+		Properties: []*junitapi.TestSuiteProperty{
+			{Name: "TestVersion", Value: "v1"},
+			{Name: shardProperty, Value: serialShard},
+		},
+		TestCases: []*junitapi.JUnitTestCase{
+			{Name: "e [Serial]"},
+			{Name: "synthetic"},
+			{Name: "monitor"},
+		},
+	}
+
+	// reports are read back the way they were written
+	var suites []*junitapi.JUnitTestSuite
+	for _, suite := range []*junitapi.JUnitTestSuite{shard1, shard2, serial} {
+		data, err := xml.MarshalIndent(suite, "", "    ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadJUnitTestSuites(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suites = append(suites, read...)
+	}
+	wrapped, err := xml.Marshal(&junitapi.JUnitTestSuites{Suites: []*junitapi.JUnitTestSuite{shard1, shard2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read, err := ReadJUnitTestSuites(wrapped); err != nil || len(read) != 2 {
+		t.Fatalf("expected two suites, got %d: %v", len(read), err)
+	} else if len(read[1].Properties) != 2 || read[1].Properties[1].Value != "2/2" {
+		t.Errorf("expected the properties to be read back, got %#v", read[1].Properties)
+	}
+
+	merged := MergeJUnitTestSuites("openshift-tests", suites...)
+	// every shard reports the synthetic and monitor tests, which are kept once with their worst result
+	// the serial shard runs after the numbered shards
+	if merged.NumTests != 8 || merged.NumFailed != 3 || merged.NumSkipped != 1 || merged.Duration != 250 {
+		t.Errorf("unexpected counts: tests=%d failed=%d skipped=%d duration=%v", merged.NumTests, merged.NumFailed, merged.NumSkipped, merged.Duration)
+	}
+
+	flaky, failing := FlakyJUnitTestCaseNames(merged)
+	if !reflect.DeepEqual(flaky, []string{"a"}) || !reflect.DeepEqual(failing, []string{"c", "monitor"}) {
+		t.Errorf("unexpected flaky=%v failing=%v", flaky, failing)
+	}
+
+	merged = MergeJUnitTestSuites("openshift-tests", shard1, shard2)
+	if len(merged.Properties) != 1 || merged.Properties[0].Name != "TestVersion" {
+		t.Errorf("unexpected properties: %#v", merged.Properties)
+	}
+}
+
+func Test_quarantinedTestsInJUnit(t *testing.T) {
+	suite := &TestSuite{
+		Quarantined: []QuarantinedTest{
+			{Pattern: regexp.MustCompile(`^quarantined`), Component: "Networking", Bug: "https://bugs/1", Expires: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	tests := []*testCase{
+		{name: "quarantined-failure", failed: true, testOutputBytes: []byte("fail [boom]")},
+		{name: "quarantined-success", success: true},
+		{name: "failure", failed: true},
+	}
+
+	forgiven := suite.applyQuarantine(tests)
+	if got := testNames(forgiven); !reflect.DeepEqual(got, []string{"quarantined-failure"}) {
+		t.Fatalf("unexpected forgiven tests: %v", got)
+	}
+	if _, fail, _, _ := summarizeTests(tests); fail != 1 {
+		t.Errorf("expected only the unquarantined failure to count, got %d", fail)
+	}
+
+	junit := generateJUnitTestSuiteResults("suite", time.Second, tests, nil)
+	if junit.NumTests != 4 || junit.NumFailed != 2 {
+		t.Errorf("unexpected counts: tests=%d failed=%d", junit.NumTests, junit.NumFailed)
+	}
+	for _, testCase := range junit.TestCases {
+		last := testCase.Properties[len(testCase.Properties)-1]
+		quarantined := last.Name == "quarantined" && last.Value == "component Networking, bug https://bugs/1, expires 2024-07-01"
+		if quarantined != strings.HasPrefix(testCase.Name, "quarantined") {
+			t.Errorf("unexpected properties for %s: %#v", testCase.Name, testCase.Properties)
+		}
+	}
+}
+
+func Test_testCasePropertiesInJUnit(t *testing.T) {
+	components := &ComponentMapping{
+		Components: []TestComponent{
+			{Test: `\[Feature:EgressIP\]`, Component: "Networking / ovn-kubernetes"},
+		},
+	}
+	for i := range components.Components {
+		components.Components[i].test = regexp.MustCompile(components.Components[i].Test)
+	}
+
+	storage := &testCase{
+		name:      "[sig-storage] volumes work [Suite:k8s]",
+		failed:    true,
+		locations: []types.CodeLocation{{FileName: "/go/src/k8s.io/kubernetes/test/e2e/storage/volumes.go", LineNumber: 40}, {FileName: "/go/src/k8s.io/kubernetes/test/e2e/storage/volumes.go", LineNumber: 52}},
+	}
+	retry := storage.Retry()
+	retry.success = true
+	tests := []*testCase{
+		storage,
+		retry,
+		{name: "[sig-network][Feature:EgressIP] egress IP works", success: true},
+		{name: `[sig-arch][Late][Jira:"kube-apiserver"] tls artifacts are registered`, success: true, binaryName: "k8s-tests-ext"},
+	}
+	synthetic := &junitapi.JUnitTestCase{Name: `[Jira:"Networking"] monitor test pod-network-availability setup`}
+
+	junit := generateJUnitTestSuiteResults("suite", time.Second, tests, components, synthetic)
+	expected := [][]string{
+		{"component=Storage", "location=/go/src/k8s.io/kubernetes/test/e2e/storage/volumes.go:40", "attempt=1", "bucket=storage"},
+		{"component=Storage", "location=/go/src/k8s.io/kubernetes/test/e2e/storage/volumes.go:40", "attempt=2", "bucket=storage"},
+		{"component=Networking / ovn-kubernetes", "attempt=1", "bucket=openshift"},
+		{"component=kube-apiserver", "attempt=1", "bucket=late"},
+		{"component=Networking"},
+	}
+	if len(junit.TestCases) != len(expected) {
+		t.Fatalf("expected %d test cases, got %d", len(expected), len(junit.TestCases))
+	}
+	for i, testCase := range junit.TestCases {
+		var properties []string
+		for _, property := range testCase.Properties {
+			properties = append(properties, property.Name+"="+property.Value)
+		}
+		if !reflect.DeepEqual(expected[i], properties) {
+			t.Errorf("%s: expected properties %v, got %v", testCase.Name, expected[i], properties)
+		}
+	}
+}
@@ -77,6 +77,17 @@ type JUnitTestCase struct {
 
 	// SystemErr is output written to stderr during the execution of this test case
 	SystemErr string `xml:"system-err,omitempty"`
+
+	// Properties holds other properties of the test case as a mapping of name to value
+	Properties []*TestCaseProperty `xml:"properties>property,omitempty"`
+}
+
+// TestCaseProperty contains a mapping of a property name to a value
+type TestCaseProperty struct {
+	XMLName xml.Name `xml:"property"`
+
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
 }
 
 // SkipMessage holds a message explaining why a test was skipped
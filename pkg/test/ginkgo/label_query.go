@@ -0,0 +1,285 @@
+package ginkgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelQuery is a boolean expression over the bracketed labels of a test name, e.g.
+//
+//	sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel) && apigroup:route.openshift.io
+//
+// A term matches a test that has the label, without the brackets.  A term ending in * matches every
+// label starting with the part before it, e.g. Suite:openshift/conformance/*.  Terms containing
+// spaces or operators are bracketed, e.g. [Skipped:Network (broken)].  ! binds tighter than &&,
+// which binds tighter than ||.
+type LabelQuery struct {
+	query string
+	root  labelQueryNode
+}
+
+// ParseLabelQuery parses a label query.
+func ParseLabelQuery(query string) (*LabelQuery, error) {
+	p := &labelQueryParser{query: query}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("invalid label query %q: %w", query, err)
+	}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid label query %q: %w", query, err)
+	}
+	return &LabelQuery{query: query, root: root}, nil
+}
+
+func (q *LabelQuery) String() string {
+	return q.query
+}
+
+// Matches returns true if the labels of the named test satisfy the query.
+func (q *LabelQuery) Matches(name string) bool {
+	return q.root.matches(testLabelsOf(name))
+}
+
+// Explain describes how the query evaluates for the named test: whether the test is included, the
+// terms that decided it, and the value of every part of the query.
+func (q *LabelQuery) Explain(name string) string {
+	labels := testLabelsOf(name)
+	buf := &strings.Builder{}
+	if q.root.matches(labels) {
+		fmt.Fprintf(buf, "included by %s\n", strings.Join(q.root.deciding(labels), ", "))
+	} else {
+		fmt.Fprintf(buf, "excluded by %s\n", strings.Join(q.root.deciding(labels), ", "))
+	}
+	explainLabelQueryNode(buf, q.root, labels, 1)
+	return buf.String()
+}
+
+func explainLabelQueryNode(buf *strings.Builder, node labelQueryNode, labels []string, depth int) {
+	fmt.Fprintf(buf, "%s%-5t %s\n", strings.Repeat("  ", depth), node.matches(labels), node)
+	for _, child := range node.children() {
+		explainLabelQueryNode(buf, child, labels, depth+1)
+	}
+}
+
+// testLabelsOf returns the bracketed labels of a test name without the brackets.
+func testLabelsOf(name string) []string {
+	var labels []string
+	for _, match := range testLabelRegexp.FindAllStringSubmatch(name, -1) {
+		labels = append(labels, match[1])
+	}
+	return labels
+}
+
+type labelQueryNode interface {
+	matches(labels []string) bool
+	// deciding returns the terms that decide the value of the node for the labels.
+	deciding(labels []string) []string
+	children() []labelQueryNode
+	String() string
+}
+
+type labelTerm string
+
+func (t labelTerm) matches(labels []string) bool {
+	prefix, wildcard := strings.CutSuffix(string(t), "*")
+	for _, label := range labels {
+		if label == string(t) || (wildcard && strings.HasPrefix(label, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t labelTerm) deciding([]string) []string { return []string{t.String()} }
+func (t labelTerm) children() []labelQueryNode { return nil }
+
+func (t labelTerm) String() string {
+	if strings.ContainsAny(string(t), " \t&|!()") {
+		return "[" + string(t) + "]"
+	}
+	return string(t)
+}
+
+type labelNot struct {
+	operand labelQueryNode
+}
+
+func (n labelNot) matches(labels []string) bool { return !n.operand.matches(labels) }
+func (n labelNot) children() []labelQueryNode   { return []labelQueryNode{n.operand} }
+
+func (n labelNot) deciding(labels []string) []string {
+	if term, ok := n.operand.(labelTerm); ok {
+		return []string{"!" + term.String()}
+	}
+	return n.operand.deciding(labels)
+}
+
+func (n labelNot) String() string {
+	if _, ok := n.operand.(labelTerm); ok {
+		return "!" + n.operand.String()
+	}
+	return "!(" + n.operand.String() + ")"
+}
+
+// labelOperation is a && or || of its operands.
+type labelOperation struct {
+	and      bool
+	operands []labelQueryNode
+}
+
+func (n labelOperation) matches(labels []string) bool {
+	for _, operand := range n.operands {
+		if operand.matches(labels) != n.and {
+			return !n.and
+		}
+	}
+	return n.and
+}
+
+func (n labelOperation) children() []labelQueryNode { return n.operands }
+
+// deciding returns the first operand that decides the value of the operation on its own, or all of
+// the operands if they decide it together.
+func (n labelOperation) deciding(labels []string) []string {
+	var deciding []string
+	for _, operand := range n.operands {
+		if operand.matches(labels) != n.and {
+			return operand.deciding(labels)
+		}
+		deciding = append(deciding, operand.deciding(labels)...)
+	}
+	return deciding
+}
+
+func (n labelOperation) String() string {
+	operator := " || "
+	if n.and {
+		operator = " && "
+	}
+	var operands []string
+	for _, operand := range n.operands {
+		if operation, ok := operand.(labelOperation); ok && operation.and != n.and {
+			operands = append(operands, "("+operand.String()+")")
+			continue
+		}
+		operands = append(operands, operand.String())
+	}
+	return strings.Join(operands, operator)
+}
+
+type labelQueryToken struct {
+	// value is the term, or one of the operators &&, ||, !, ( and )
+	value string
+	term  bool
+}
+
+type labelQueryParser struct {
+	query  string
+	tokens []labelQueryToken
+	pos    int
+}
+
+func (p *labelQueryParser) tokenize() error {
+	query := p.query
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.HasPrefix(query[i:], "&&"), strings.HasPrefix(query[i:], "||"):
+			p.tokens = append(p.tokens, labelQueryToken{value: query[i : i+2]})
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			p.tokens = append(p.tokens, labelQueryToken{value: string(c)})
+			i++
+		case c == '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 2 {
+				return fmt.Errorf("unterminated or empty label at %d", i)
+			}
+			p.tokens = append(p.tokens, labelQueryToken{value: query[i+1 : i+end], term: true})
+			i += end + 1
+		case c == '&' || c == '|' || c == ']':
+			return fmt.Errorf("unexpected %q at %d", c, i)
+		default:
+			end := i
+			for end < len(query) && !strings.ContainsRune(" \t\n&|!()[]", rune(query[end])) {
+				end++
+			}
+			p.tokens = append(p.tokens, labelQueryToken{value: query[i:end], term: true})
+			i = end
+		}
+	}
+	if len(p.tokens) == 0 {
+		return fmt.Errorf("empty query")
+	}
+	return nil
+}
+
+func (p *labelQueryParser) peek(value string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].term && p.tokens[p.pos].value == value
+}
+
+func (p *labelQueryParser) parseOr() (labelQueryNode, error) {
+	return p.parseOperation(false, p.parseAnd)
+}
+
+func (p *labelQueryParser) parseAnd() (labelQueryNode, error) {
+	return p.parseOperation(true, p.parseUnary)
+}
+
+func (p *labelQueryParser) parseOperation(and bool, parseOperand func() (labelQueryNode, error)) (labelQueryNode, error) {
+	operator := "||"
+	if and {
+		operator = "&&"
+	}
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []labelQueryNode{operand}
+	for p.peek(operator) {
+		p.pos++
+		operand, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return labelOperation{and: and, operands: operands}, nil
+}
+
+func (p *labelQueryParser) parseUnary() (labelQueryNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case token.term:
+		return labelTerm(token.value), nil
+	case token.value == "!":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return labelNot{operand: operand}, nil
+	case token.value == "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token.value)
+	}
+}
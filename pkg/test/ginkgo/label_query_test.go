@@ -0,0 +1,138 @@
+package ginkgo
+
+import (
+	"testing"
+)
+
+func TestLabelQuery(t *testing.T) {
+	const query = "sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel) && apigroup:route.openshift.io"
+
+	testCases := []struct {
+		name     string
+		query    string
+		test     string
+		expected bool
+	}{
+		{
+			name:     "all terms match",
+			query:    query,
+			test:     "[sig-network][Feature:IPv6][apigroup:route.openshift.io] routes work [Suite:openshift/conformance/serial]",
+			expected: true,
+		},
+		{
+			name:     "second alternative",
+			query:    query,
+			test:     "[sig-network][apigroup:route.openshift.io] routes work [Suite:openshift/conformance/parallel]",
+			expected: true,
+		},
+		{
+			name:  "negated term",
+			query: query,
+			test:  "[sig-network][Feature:IPv6][apigroup:route.openshift.io] routes work [Serial]",
+		},
+		{
+			name:  "labels are not substrings",
+			query: query,
+			test:  "[sig-network-edge][Feature:IPv6][apigroup:route.openshift.io] routes work",
+		},
+		{
+			name:     "wildcard",
+			query:    "Suite:openshift/conformance/*",
+			test:     "[sig-cli] oc works [Suite:openshift/conformance/parallel/minimal]",
+			expected: true,
+		},
+		{
+			name:     "bracketed term",
+			query:    "[Skipped:Network (broken)] || Slow",
+			test:     "[sig-cli] oc works [Skipped:Network (broken)]",
+			expected: true,
+		},
+		{
+			name:     "precedence",
+			query:    "Slow || Serial && Disruptive",
+			test:     "[sig-etcd] recovers [Slow]",
+			expected: true,
+		},
+		{
+			name:     "double negation",
+			query:    "!!sig-cli",
+			test:     "[sig-cli] oc works",
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := ParseLabelQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.Matches(tc.test); got != tc.expected {
+				t.Errorf("expected %t, got %t:\n%s", tc.expected, got, q.Explain(tc.test))
+			}
+		})
+	}
+}
+
+func TestParseLabelQuery_Invalid(t *testing.T) {
+	for _, query := range []string{"", "sig-network &&", "(sig-network", "sig-network)", "sig-network & Serial", "[]", "[sig-network", "sig-network Serial", "!"} {
+		if _, err := ParseLabelQuery(query); err == nil {
+			t.Errorf("expected %q to be invalid", query)
+		}
+	}
+}
+
+func TestLabelQuery_Explain(t *testing.T) {
+	q, err := ParseLabelQuery("sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		test     string
+		expected string
+	}{
+		{
+			test: "[sig-network] routes work [Serial]",
+			expected: `excluded by !Serial
+  false sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel)
+    true  sig-network
+    false !Serial
+      true  Serial
+    false Feature:IPv6 || Suite:openshift/conformance/parallel
+      false Feature:IPv6
+      false Suite:openshift/conformance/parallel
+`,
+		},
+		{
+			test: "[sig-network] routes work [Suite:openshift/conformance/parallel]",
+			expected: `included by sig-network, !Serial, Suite:openshift/conformance/parallel
+  true  sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel)
+    true  sig-network
+    true  !Serial
+      false Serial
+    true  Feature:IPv6 || Suite:openshift/conformance/parallel
+      false Feature:IPv6
+      true  Suite:openshift/conformance/parallel
+`,
+		},
+		{
+			test: "[sig-network] routes work",
+			expected: `excluded by Feature:IPv6, Suite:openshift/conformance/parallel
+  false sig-network && !Serial && (Feature:IPv6 || Suite:openshift/conformance/parallel)
+    true  sig-network
+    true  !Serial
+      false Serial
+    false Feature:IPv6 || Suite:openshift/conformance/parallel
+      false Feature:IPv6
+      false Suite:openshift/conformance/parallel
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			if got := q.Explain(tc.test); got != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package ginkgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// leakTrackedResources are the cluster scoped resources a test is expected to delete again if it
+// creates them.  Everything namespaced is covered by the namespace it was created in.
+var leakTrackedResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "namespaces"},
+	{Version: "v1", Resource: "persistentvolumes"},
+	{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+	{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations"},
+	{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "mutatingwebhookconfigurations"},
+}
+
+// leakTrackedConfigResources are the cluster configuration resources read by the cluster operators.
+// They exist before the run starts, so a test leaks by changing their spec without restoring it.
+var leakTrackedConfigResources = []schema.GroupVersionResource{
+	{Group: "config.openshift.io", Version: "v1", Resource: "apiservers"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "authentications"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "builds"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "consoles"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "dnses"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "featuregates"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "images"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "ingresses"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "networks"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "oauths"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "projects"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "proxies"},
+	{Group: "config.openshift.io", Version: "v1", Resource: "schedulers"},
+}
+
+// leakRecordedResourcePrefix keeps the resources recorded for leak detection apart from the resources
+// other monitor tests record under the same name.  It is part of the name of the file the resources
+// are serialized to, so it must not contain a path separator.
+const leakRecordedResourcePrefix = "leak-candidate-"
+
+// e2eFrameworkLabel is set by the e2e framework on the namespaces it creates for a test.
+const e2eFrameworkLabel = "e2e-framework"
+
+// leakedResource is a resource that was created, or for configuration changed, while tests were
+// running and has not been deleted, or restored, since.
+type leakedResource struct {
+	resourceType string
+	namespace    string
+	name         string
+	uid          types.UID
+	// since is when the resource was created, or when the configuration first diverged from its
+	// state at the start of the run.
+	since time.Time
+	// baseName is the e2e framework base name of a test namespace.
+	baseName string
+	// changed is set for configuration that was modified rather than created.
+	changed bool
+}
+
+func (r *leakedResource) String() string {
+	name := r.name
+	if len(r.namespace) > 0 {
+		name = r.namespace + "/" + r.name
+	}
+	if r.changed {
+		return fmt.Sprintf("%s/%s spec changed at %s", r.resourceType, name, r.since.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s/%s created at %s", r.resourceType, name, r.since.UTC().Format(time.RFC3339))
+}
+
+// leakDetector watches the cluster for resources the tests create and do not clean up.  After every
+// test, the resources created while it ran and that still exist are recorded as suspects for that
+// test.  A suspect that still exists once the run is complete is reported as leaked, which gives
+// asynchronous cleanup such as namespace deletion the rest of the run to complete.
+//
+// The leak candidates, the resources created from the start of the run on and the configuration, are
+// recorded with RecordResource, so their final state is serialized with the other recorded resources.
+// The resources that existed before the run are not recorded, so that the recorder does not hold a
+// copy of every object the detector watches.
+type leakDetector struct {
+	recorder monitorapi.RecorderWriter
+	start    time.Time
+
+	lock sync.Mutex
+	// alive holds the leak candidates that currently exist by UID.
+	alive map[types.UID]*leakedResource
+	// configSpecs holds the spec of every configuration resource at the start of the run.
+	configSpecs map[types.UID]interface{}
+	// suspects holds the tests that were running when a resource was created.
+	suspects map[types.UID][]*testCase
+}
+
+func newLeakDetector(recorder monitorapi.RecorderWriter, start time.Time) *leakDetector {
+	return &leakDetector{
+		recorder:    recorder,
+		start:       start,
+		alive:       map[types.UID]*leakedResource{},
+		configSpecs: map[types.UID]interface{}{},
+		suspects:    map[types.UID][]*testCase{},
+	}
+}
+
+// Start watches the tracked resources the cluster serves until the context is done.  It returns
+// once the current state of the cluster has been observed.
+func (d *leakDetector) Start(ctx context.Context, restConfig *rest.Config) error {
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	watch := func(gvr schema.GroupVersionResource, config bool) error {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+		if err != nil {
+			// not every cluster serves the openshift configuration
+			return nil
+		}
+		served := false
+		for _, resource := range resources.APIResources {
+			served = served || resource.Name == gvr.Resource
+		}
+		if !served {
+			return nil
+		}
+		_, err = factory.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { d.observe(gvr.GroupResource().String(), obj, config, time.Now()) },
+			UpdateFunc: func(_, obj interface{}) { d.observe(gvr.GroupResource().String(), obj, config, time.Now()) },
+			DeleteFunc: func(obj interface{}) { d.forget(obj) },
+		})
+		return err
+	}
+	for _, gvr := range leakTrackedResources {
+		if err := watch(gvr, false); err != nil {
+			return err
+		}
+	}
+	for _, gvr := range leakTrackedConfigResources {
+		if err := watch(gvr, true); err != nil {
+			return err
+		}
+	}
+
+	factory.Start(ctx.Done())
+	for gvr, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("unable to observe %s", gvr)
+		}
+	}
+	return nil
+}
+
+func (d *leakDetector) observe(resourceType string, obj interface{}, config bool, now time.Time) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	metadata, err := meta.Accessor(u)
+	if err != nil {
+		return
+	}
+	uid := metadata.GetUID()
+	// creation timestamps are truncated to the second
+	createdBeforeRun := metadata.GetCreationTimestamp().Time.Before(d.start.Truncate(time.Second))
+	if config || !createdBeforeRun {
+		d.recorder.RecordResource(leakRecordedResourcePrefix+resourceType, u)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// a resource that is being deleted has been cleaned up, even if finalizers still hold it
+	if metadata.GetDeletionTimestamp() != nil {
+		delete(d.alive, uid)
+		return
+	}
+
+	resource := &leakedResource{
+		resourceType: resourceType,
+		namespace:    metadata.GetNamespace(),
+		name:         metadata.GetName(),
+		uid:          uid,
+		since:        metadata.GetCreationTimestamp().Time,
+		baseName:     metadata.GetLabels()[e2eFrameworkLabel],
+	}
+	if !config {
+		if createdBeforeRun {
+			return
+		}
+		if _, ok := d.alive[uid]; !ok {
+			d.alive[uid] = resource
+		}
+		return
+	}
+
+	spec := u.Object["spec"]
+	initial, ok := d.configSpecs[uid]
+	if !ok {
+		d.configSpecs[uid] = spec
+		return
+	}
+	if equality.Semantic.DeepEqual(initial, spec) {
+		delete(d.alive, uid)
+		return
+	}
+	if _, ok := d.alive[uid]; !ok {
+		resource.since = now
+		resource.changed = true
+		d.alive[uid] = resource
+	}
+}
+
+func (d *leakDetector) forget(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.alive, metadata.GetUID())
+}
+
+// TestFinished records the test as a suspect for every resource that was created while it ran and
+// still exists.
+func (d *leakDetector) TestFinished(test *testCase) {
+	if d == nil || test.start.IsZero() {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for uid, resource := range d.alive {
+		if resource.since.Before(test.start.Truncate(time.Second)) || resource.since.After(test.end) {
+			continue
+		}
+		d.suspects[uid] = append(d.suspects[uid], test)
+	}
+}
+
+// Leaks returns a synthetic test for every test that leaked resources.  When several tests were
+// running as a leaked resource was created, the ones whose output names the resource, or the
+// e2e framework base name of a test namespace, are blamed.  If none do, all of them are.
+func (d *leakDetector) Leaks() []*junitapi.JUnitTestCase {
+	if d == nil {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	leaksByTest := map[string][]*leakedResource{}
+	for uid, suspects := range d.suspects {
+		resource, ok := d.alive[uid]
+		if !ok {
+			continue
+		}
+		var named []*testCase
+		for _, test := range suspects {
+			if bytes.Contains(test.testOutputBytes, []byte(resource.name)) ||
+				(len(resource.baseName) > 0 && bytes.Contains(test.testOutputBytes, []byte("basename "+resource.baseName))) {
+				named = append(named, test)
+			}
+		}
+		if len(named) > 0 {
+			suspects = named
+		}
+		for _, test := range suspects {
+			leaksByTest[test.name] = append(leaksByTest[test.name], resource)
+		}
+	}
+
+	var names []string
+	for name := range leaksByTest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ret []*junitapi.JUnitTestCase
+	for _, name := range names {
+		var leaks []string
+		for _, resource := range leaksByTest[name] {
+			leaks = append(leaks, resource.String())
+		}
+		sort.Strings(leaks)
+		testName := fmt.Sprintf("[sig-arch] test should clean up the cluster resources it creates: %s", name)
+		output := fmt.Sprintf("%d resources created while the test ran still exist at the end of the run:\n\n%s", len(leaks), strings.Join(leaks, "\n"))
+		// leaks are attributed by time, so they are reported as flakes rather than failing the run
+		ret = append(ret,
+			&junitapi.JUnitTestCase{
+				Name:          testName,
+				SystemOut:     output,
+				FailureOutput: &junitapi.FailureOutput{Output: output},
+			},
+			&junitapi.JUnitTestCase{
+				Name: testName,
+			},
+		)
+	}
+	return ret
+}
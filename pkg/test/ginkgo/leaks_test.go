@@ -0,0 +1,122 @@
+package ginkgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/origin/pkg/monitor"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+func leakTestObject(name string, created time.Time, labels map[string]string, spec map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetName(name)
+	u.SetUID(types.UID(name))
+	u.SetCreationTimestamp(metav1.NewTime(created))
+	u.SetLabels(labels)
+	if spec != nil {
+		u.Object["spec"] = spec
+	}
+	return u
+}
+
+func Test_leakDetector(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	recorder := monitor.NewRecorder()
+	d := newLeakDetector(recorder, start)
+
+	// existing state of the cluster
+	d.observe("namespaces", leakTestObject("openshift-etcd", start.Add(-time.Hour), nil, nil), false, start)
+	d.observe("proxies.config.openshift.io", leakTestObject("cluster", start.Add(-time.Hour), nil, map[string]interface{}{"noProxy": "a"}), true, start)
+	d.observe("images.config.openshift.io", leakTestObject("images", start.Add(-time.Hour), nil, map[string]interface{}{}), true, start)
+
+	router := &testCase{name: "router test", start: start.Add(time.Minute), end: start.Add(5 * time.Minute),
+		testOutputBytes: []byte(`Creating project "e2e-test-router-abcde"`)}
+	builds := &testCase{name: "builds test", start: start.Add(2 * time.Minute), end: start.Add(6 * time.Minute),
+		testOutputBytes: []byte(`STEP: Building a namespace api object, basename builds`)}
+	proxy := &testCase{name: "proxy test", start: start.Add(7 * time.Minute), end: start.Add(8 * time.Minute)}
+
+	d.observe("namespaces", leakTestObject("e2e-test-router-abcde", start.Add(3*time.Minute), nil, nil), false, start.Add(3*time.Minute))
+	d.observe("namespaces", leakTestObject("e2e-builds-fghij", start.Add(3*time.Minute), map[string]string{e2eFrameworkLabel: "builds"}, nil), false, start.Add(3*time.Minute))
+	d.observe("clusterroles.rbac.authorization.k8s.io", leakTestObject("unnamed-role", start.Add(4*time.Minute), nil, nil), false, start.Add(4*time.Minute))
+	cleanedUp := leakTestObject("e2e-test-cleaned", start.Add(4*time.Minute), nil, nil)
+	d.observe("namespaces", cleanedUp, false, start.Add(4*time.Minute))
+	d.TestFinished(router)
+	d.TestFinished(builds)
+
+	// deletion of the namespace only starts after the tests finished
+	now := metav1.NewTime(start.Add(9 * time.Minute))
+	cleanedUp.SetDeletionTimestamp(&now)
+	d.observe("namespaces", cleanedUp, false, now.Time)
+
+	// the proxy test changes the configuration without restoring it, the images configuration is restored
+	d.observe("proxies.config.openshift.io", leakTestObject("cluster", start.Add(-time.Hour), nil, map[string]interface{}{"noProxy": "b"}), true, start.Add(7*time.Minute))
+	d.observe("images.config.openshift.io", leakTestObject("images", start.Add(-time.Hour), nil, map[string]interface{}{"x": "y"}), true, start.Add(7*time.Minute))
+	d.TestFinished(proxy)
+	d.observe("images.config.openshift.io", leakTestObject("images", start.Add(-time.Hour), nil, map[string]interface{}{}), true, start.Add(9*time.Minute))
+
+	leaks := d.Leaks()
+	failures := map[string]string{}
+	for _, leak := range leaks {
+		if leak.FailureOutput != nil {
+			failures[strings.TrimPrefix(leak.Name, "[sig-arch] test should clean up the cluster resources it creates: ")] = leak.FailureOutput.Output
+		}
+	}
+	if len(leaks) != 2*len(failures) {
+		t.Errorf("expected every leak to be reported as a flake, got %d results for %d failures", len(leaks), len(failures))
+	}
+
+	expected := map[string][]string{
+		"router test": {"clusterroles.rbac.authorization.k8s.io/unnamed-role", "namespaces/e2e-test-router-abcde"},
+		"builds test": {"clusterroles.rbac.authorization.k8s.io/unnamed-role", "namespaces/e2e-builds-fghij"},
+		"proxy test":  {"proxies.config.openshift.io/cluster spec changed"},
+	}
+	if len(failures) != len(expected) {
+		t.Errorf("unexpected leaking tests: %v", reflect.ValueOf(failures).MapKeys())
+	}
+	for name, resources := range expected {
+		output, ok := failures[name]
+		if !ok {
+			t.Errorf("expected %q to leak resources", name)
+			continue
+		}
+		for _, resource := range resources {
+			if !strings.Contains(output, resource) {
+				t.Errorf("expected %q to leak %s, got:\n%s", name, resource, output)
+			}
+		}
+		for _, unexpected := range []string{"openshift-etcd", "e2e-test-cleaned", "images"} {
+			if strings.Contains(output, unexpected) {
+				t.Errorf("did not expect %q to leak %s, got:\n%s", name, unexpected, output)
+			}
+		}
+	}
+
+	// the leak candidates are recorded, and written where the recorded resources are serialized to
+	recorded := recorder.CurrentResourceState()
+	namespaces := recorded[leakRecordedResourcePrefix+"namespaces"]
+	recordedNames := map[string]bool{}
+	for key := range namespaces {
+		recordedNames[key.Name] = true
+	}
+	if len(recordedNames) != 3 || !recordedNames["e2e-test-router-abcde"] || !recordedNames["e2e-test-cleaned"] || recordedNames["openshift-etcd"] {
+		t.Errorf("expected the namespaces created during the run to be recorded, got %v", recordedNames)
+	}
+	if len(recorded[leakRecordedResourcePrefix+"proxies.config.openshift.io"]) != 1 {
+		t.Errorf("expected the configuration to be recorded, got %v", recorded)
+	}
+	for resourceType, instances := range recorded {
+		filename := filepath.Join(t.TempDir(), fmt.Sprintf("resource-%s_suffix.zip", resourceType))
+		if err := monitorserialization.InstanceMapToFile(filename, resourceType, instances); err != nil {
+			t.Errorf("unable to serialize the recorded %s: %v", resourceType, err)
+		}
+	}
+}
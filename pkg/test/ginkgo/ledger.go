@@ -0,0 +1,224 @@
+package ginkgo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// runLedgerFilename is the name of the ledger written into --junit-dir.
+const runLedgerFilename = "openshift-tests-run-ledger.jsonl"
+
+// runLedgerEntry is the outcome of a single completed test as recorded in the ledger.
+type runLedgerEntry struct {
+	Name     string        `json:"name"`
+	State    TestState     `json:"state"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output,omitempty"`
+}
+
+// runLedger durably records the outcome of every test as soon as it completes, one JSON
+// object per line, so that an interrupted run can be picked up again with --resume-from.
+// A nil *runLedger is valid and records nothing.
+type runLedger struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+// newRunLedger opens the ledger in dir.  The ledger of a previous run in dir is only appended to when
+// that run is being resumed, otherwise it is truncated so that a later --resume-from of dir does not
+// skip tests that passed in an unrelated run.
+func newRunLedger(dir string, resuming bool) (*runLedger, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resuming {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(filepath.Join(dir, runLedgerFilename), flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open run ledger: %w", err)
+	}
+	return &runLedger{
+		file: file,
+	}, nil
+}
+
+// Record appends the current state of the test to the ledger and syncs it to disk.  Tests
+// that have not completed are ignored.
+func (l *runLedger) Record(test *testCase) error {
+	if l == nil {
+		return nil
+	}
+	state, ok := testStateFor(test)
+	if !ok {
+		return nil
+	}
+
+	line, err := json.Marshal(runLedgerEntry{
+		Name:     test.name,
+		State:    state,
+		Start:    test.start,
+		End:      test.end,
+		Duration: test.duration,
+		Output:   string(test.testOutputBytes),
+	})
+	if err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+func (l *runLedger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.Close()
+}
+
+// testStateFor maps the result fields of a completed testCase back to a TestState.
+func testStateFor(test *testCase) (TestState, bool) {
+	switch {
+	case test.flake:
+		return TestFlaked, true
+	case test.success:
+		return TestSucceeded, true
+	case test.skipped:
+		return TestSkipped, true
+	case test.timedOut:
+		return TestFailedTimeout, true
+	case test.failed:
+		return TestFailed, true
+	}
+	return "", false
+}
+
+// loadRunLedger reads all entries of the ledger stored in dir.  A trailing partial line, as
+// left behind when the previous run was killed mid-write, is ignored.
+func loadRunLedger(dir string) ([]runLedgerEntry, error) {
+	file, err := os.Open(filepath.Join(dir, runLedgerFilename))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte{}, scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var entries []runLedgerEntry
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		entry := runLedgerEntry{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// passedTestsFromLedger returns one completed testCase for every test name that passed (or
+// flaked) in the ledger.  When a test passed multiple times, the last result wins.
+func passedTestsFromLedger(entries []runLedgerEntry) []*testCase {
+	var passed []*testCase
+	byName := map[string]*testCase{}
+	for _, entry := range entries {
+		if entry.State != TestSucceeded && entry.State != TestFlaked {
+			continue
+		}
+		test, ok := byName[entry.Name]
+		if !ok {
+			test = &testCase{name: entry.Name}
+			byName[entry.Name] = test
+			passed = append(passed, test)
+		}
+		test.start = entry.Start
+		test.end = entry.End
+		test.duration = entry.Duration
+		test.testOutputBytes = []byte(entry.Output)
+		test.success = entry.State == TestSucceeded
+		test.flake = entry.State == TestFlaked
+	}
+	return passed
+}
+
+// withoutResumedTests removes every test that already passed in a previous run.
+func withoutResumedTests(tests, resumed []*testCase) []*testCase {
+	names := map[string]bool{}
+	for _, test := range resumed {
+		names[test.name] = true
+	}
+	remaining, _ := splitTests(tests, func(t *testCase) bool { return !names[t.name] })
+	return remaining
+}
+
+// sameDir returns true if both paths refer to the same directory.
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+// resumedRunStart returns the start of a run resuming the tests, which is the start of the earliest resumed test if
+// it started before start.  The intervals of the resumed tests are only reported from it on.
+func resumedRunStart(start time.Time, resumed []*testCase) time.Time {
+	for _, test := range resumed {
+		if !test.start.IsZero() && test.start.Before(start) {
+			start = test.start
+		}
+	}
+	return start
+}
+
+// recordResumedTestInMonitor adds the start and finish intervals of a test that ran in a
+// previous invocation, at the times it originally ran.  They are reported from resumedRunStart on.  The intervals
+// resumed by a disk recorder already hold them.
+func recordResumedTestInMonitor(test *testCase, monitorRecorder monitorapi.Recorder) {
+	level := monitorapi.Info
+	status := "Passed"
+	if test.flake {
+		level = monitorapi.Error
+		status = "Flaked"
+	}
+
+	monitorRecorder.AddIntervals(
+		monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+			Locator(monitorapi.NewLocator().E2ETest(test.name)).
+			Message(monitorapi.NewMessage().HumanMessage("started").Reason(monitorapi.E2ETestStarted)).
+			Build(test.start, test.start),
+		monitorapi.NewInterval(monitorapi.SourceE2ETest, level).
+			Locator(monitorapi.NewLocator().E2ETest(test.name)).
+			Message(monitorapi.NewMessage().HumanMessage("finished").Reason(monitorapi.E2ETestFinished).
+				WithAnnotation(monitorapi.AnnotationStatus, status)).
+			Build(test.end, test.end),
+	)
+}
@@ -0,0 +1,145 @@
+package ginkgo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func Test_runLedger(t *testing.T) {
+	dir := t.TempDir()
+	ledger, err := newRunLedger(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []*testCase{
+		{name: "passed", success: true, start: start, end: start.Add(time.Second), duration: time.Second},
+		{name: "failed", failed: true, testOutputBytes: []byte("fail [boom]")},
+		{name: "flaked", flake: true, testOutputBytes: []byte("flake: once")},
+		{name: "skipped", skipped: true},
+		{name: "incomplete"},
+		{name: "retried", failed: true},
+		{name: "retried", success: true},
+	}
+	for _, test := range tests {
+		if err := ledger.Record(test); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ledger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a runner that was killed in the middle of writing a line
+	f, err := os.OpenFile(filepath.Join(dir, runLedgerFilename), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"name":"partial","sta`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	entries, err := loadRunLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("expected 6 entries, got %d: %#v", len(entries), entries)
+	}
+
+	passed := passedTestsFromLedger(entries)
+	if got := testNames(passed); len(got) != 3 || got[0] != "passed" || got[1] != "flaked" || got[2] != "retried" {
+		t.Fatalf("unexpected passed tests: %v", got)
+	}
+	if !passed[0].start.Equal(start) || passed[0].duration != time.Second || !passed[0].success {
+		t.Errorf("unexpected resumed test: %#v", passed[0])
+	}
+	if !passed[1].flake || string(passed[1].testOutputBytes) != "flake: once" {
+		t.Errorf("unexpected resumed flake: %#v", passed[1])
+	}
+
+	remaining := withoutResumedTests([]*testCase{{name: "passed"}, {name: "failed"}, {name: "new"}}, passed)
+	if got := testNames(remaining); len(got) != 2 || got[0] != "failed" || got[1] != "new" {
+		t.Errorf("unexpected remaining tests: %v", got)
+	}
+}
+
+func Test_runLedgerReusedDir(t *testing.T) {
+	dir := t.TempDir()
+	record := func(resuming bool, name string) {
+		t.Helper()
+		ledger, err := newRunLedger(dir, resuming)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ledger.Record(&testCase{name: name, success: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ledger.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(false, "earlier run")
+	// a fresh run into the same directory does not keep the results of the earlier run
+	record(false, "interrupted run")
+	// resuming in place appends to the ledger of the interrupted run
+	record(true, "resumed run")
+
+	entries, err := loadRunLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testNames(passedTestsFromLedger(entries)); len(got) != 2 || got[0] != "interrupted run" || got[1] != "resumed run" {
+		t.Errorf("unexpected passed tests: %v", got)
+	}
+}
+
+func Test_recordResumedTestInMonitor(t *testing.T) {
+	interrupted := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resumed := []*testCase{
+		{name: "passed", success: true, start: interrupted, end: interrupted.Add(time.Minute)},
+		{name: "flaked", flake: true, start: interrupted.Add(time.Minute), end: interrupted.Add(2 * time.Minute)},
+	}
+	start := interrupted.Add(time.Hour)
+	end := start.Add(time.Hour)
+
+	recorder := monitor.NewRecorder()
+	for _, test := range resumed {
+		recordResumedTestInMonitor(test, recorder)
+	}
+	recorder.AddIntervals(monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+		Locator(monitorapi.NewLocator().E2ETest("new")).
+		Message(monitorapi.NewMessage().HumanMessage("started").Reason(monitorapi.E2ETestStarted)).
+		Build(start.Add(time.Minute), start.Add(time.Minute)))
+
+	if got := recorder.Intervals(start, end); len(got) != 1 {
+		t.Fatalf("expected only the interval of this run from its start, got %v", got)
+	}
+	intervalsStart := resumedRunStart(start, resumed)
+	if !intervalsStart.Equal(interrupted) {
+		t.Errorf("expected the run to start with the earliest resumed test, got %s", intervalsStart)
+	}
+	got := recorder.Intervals(intervalsStart, end)
+	if len(got) != 5 {
+		t.Fatalf("expected the intervals of the resumed tests and this run, got %v", got)
+	}
+	for i, name := range []string{"passed", "passed", "flaked", "flaked", "new"} {
+		if test := got[i].Locator.Keys[monitorapi.LocatorE2ETestKey]; test != name {
+			t.Errorf("expected interval %d of %q, got %q", i, name, test)
+		}
+	}
+	if status := got[3].Message.Annotations[monitorapi.AnnotationStatus]; status != "Flaked" {
+		t.Errorf("expected the resumed flake to be reported, got %q", status)
+	}
+
+	if got := resumedRunStart(start, nil); !got.Equal(start) {
+		t.Errorf("expected a run resuming nothing to start at its start, got %s", got)
+	}
+}
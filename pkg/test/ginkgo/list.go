@@ -0,0 +1,144 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TestMetadata describes a test for tooling outside of the binary.
+type TestMetadata struct {
+	Name   string     `json:"name"`
+	Labels TestLabels `json:"labels"`
+	// Locations are the file:line of the spec and its containers.
+	Locations []string `json:"locations,omitempty"`
+	// Timeout is the time the test may run before it is interrupted.
+	Timeout string `json:"timeout"`
+	// Bucket is the group of tests the test runs with: early, kube, storage, openshift, must-gather or late.
+	Bucket string `json:"bucket"`
+	// Binary is the external binary providing the test, empty for the tests of this binary.
+	Binary   string `json:"binary,omitempty"`
+	External bool   `json:"external"`
+	// Included is true if the suite runs the test.
+	Included bool `json:"included"`
+	// ExcludedBy names the filters excluding the test from the suite, e.g. featuregate, apigroup or rebase.
+	ExcludedBy []string `json:"excludedBy,omitempty"`
+	// RebaseExclusionReason is the reason of the rebase exclusion excluding the test.
+	RebaseExclusionReason string `json:"rebaseExclusionReason,omitempty"`
+}
+
+// TestLabels are the bracketed labels of a test name.
+type TestLabels struct {
+	Sigs         []string `json:"sigs,omitempty"`
+	Suites       []string `json:"suites,omitempty"`
+	Features     []string `json:"features,omitempty"`
+	FeatureGates []string `json:"featureGates,omitempty"`
+	APIGroups    []string `json:"apiGroups,omitempty"`
+	Skipped      []string `json:"skipped,omitempty"`
+	Serial       bool     `json:"serial,omitempty"`
+	Timeout      string   `json:"timeout,omitempty"`
+	// Other holds every label not listed above, e.g. Conformance, Slow or Disruptive.
+	Other []string `json:"other,omitempty"`
+}
+
+var testLabelRegexp = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+// ParseTestLabels parses the bracketed labels of a test name.
+func ParseTestLabels(name string) TestLabels {
+	labels := TestLabels{}
+	for _, match := range testLabelRegexp.FindAllStringSubmatch(name, -1) {
+		label := match[1]
+		key, value, _ := strings.Cut(label, ":")
+		switch {
+		case strings.HasPrefix(label, "sig-"):
+			labels.Sigs = append(labels.Sigs, label)
+		case key == "Suite":
+			labels.Suites = append(labels.Suites, value)
+		case key == "Feature":
+			labels.Features = append(labels.Features, value)
+		case key == "FeatureGate", key == "OCPFeatureGate":
+			labels.FeatureGates = append(labels.FeatureGates, value)
+		case key == "apigroup":
+			labels.APIGroups = append(labels.APIGroups, value)
+		case key == "Skipped":
+			labels.Skipped = append(labels.Skipped, value)
+		case key == "Timeout":
+			labels.Timeout = value
+		case label == "Serial":
+			labels.Serial = true
+		default:
+			labels.Other = append(labels.Other, label)
+		}
+	}
+	return labels
+}
+
+// ListTests returns the metadata of every test of this binary and of the external binaries,
+// including the tests the suite excludes.  When clusterVersion is nil, the exclusions of a rebase in
+// progress are not evaluated, otherwise they are read from the rebaseExclusions manifest, or from the
+// manifest built into the binary if empty.  externalBinaries are the --external-binary sources
+// replacing the binaries of the release payload.
+func ListTests(ctx context.Context, suite *TestSuite, clusterVersion *ClusterVersion, rebaseExclusionsPath string, externalBinaries []string, errOut io.Writer) ([]TestMetadata, error) {
+	externalBinarySources, err := parseExternalBinarySources(externalBinaries)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := loadRebaseExclusions(rebaseExclusionsPath)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := testsForSuite()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading origin test suites: %w", err)
+	}
+	if len(externalBinarySources) > 0 || len(os.Getenv("OPENSHIFT_SKIP_EXTERNAL_TESTS")) == 0 {
+		externalTests, err := externalTestsForSuite(ctx, externalBinarySources, errOut)
+		if err != nil {
+			fmt.Fprintf(errOut, "Listing built-in tests only, failed reading external test suites: %v\n", err)
+		} else {
+			tests = withExternalTests(tests, externalTests)
+		}
+	}
+
+	exclusions := rebaseExclusions(manifest, clusterVersion)
+	metadata := make([]TestMetadata, 0, len(tests))
+	for _, test := range tests {
+		metadata = append(metadata, testMetadataFor(test, suite, exclusions))
+	}
+	sort.Slice(metadata, func(i, j int) bool { return metadata[i].Name < metadata[j].Name })
+	return metadata, nil
+}
+
+func testMetadataFor(test *testCase, suite *TestSuite, rebaseExclusions []*RebaseExclusion) TestMetadata {
+	timeout := test.testTimeout
+	if timeout == 0 {
+		timeout = suite.TestTimeout
+	}
+	if timeout == 0 {
+		timeout = 15 * time.Minute
+	}
+
+	metadata := TestMetadata{
+		Name:       test.name,
+		Labels:     ParseTestLabels(test.name),
+		Timeout:    timeout.String(),
+		Bucket:     string(bucketForTest(test)),
+		Binary:     test.binaryName,
+		External:   len(test.binaryName) > 0,
+		ExcludedBy: suite.ExcludedBy(test.name),
+	}
+	for _, location := range test.locations {
+		metadata.Locations = append(metadata.Locations, location.String())
+	}
+	if exclusion := excludedByRebase(rebaseExclusions, test.name); exclusion != nil {
+		metadata.ExcludedBy = append(metadata.ExcludedBy, "rebase")
+		metadata.RebaseExclusionReason = exclusion.Reason
+	}
+	metadata.Included = len(metadata.ExcludedBy) == 0
+	return metadata
+}
@@ -0,0 +1,110 @@
+package ginkgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestParseTestLabels(t *testing.T) {
+	name := "[sig-network][Feature:Router][OCPFeatureGate:GatewayAPI][apigroup:route.openshift.io] router works [Serial][Slow][Timeout:30m][Skipped:Disconnected] [Suite:openshift/conformance/serial]"
+	expected := TestLabels{
+		Sigs:         []string{"sig-network"},
+		Suites:       []string{"openshift/conformance/serial"},
+		Features:     []string{"Router"},
+		FeatureGates: []string{"GatewayAPI"},
+		APIGroups:    []string{"route.openshift.io"},
+		Skipped:      []string{"Disconnected"},
+		Serial:       true,
+		Timeout:      "30m",
+		Other:        []string{"Slow"},
+	}
+	if got := ParseTestLabels(name); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+}
+
+func Test_testMetadataFor(t *testing.T) {
+	suite := &TestSuite{
+		Matches: func(name string) bool {
+			return strings.Contains(name, "[Suite:openshift/conformance/")
+		},
+		TestTimeout: 20 * time.Minute,
+	}
+	suite.AddNamedRequiredMatchFunc("apigroup", func(name string) bool {
+		return !strings.Contains(name, "[apigroup:missing.openshift.io]")
+	})
+	exclusion := &RebaseExclusion{Test: `broken by rebase`, Reason: "https://issues.redhat.com/browse/OCPBUGS-1"}
+	if err := exclusion.compile(); err != nil {
+		t.Fatal(err)
+	}
+	exclusions := []*RebaseExclusion{exclusion}
+
+	testCases := []struct {
+		test       *testCase
+		bucket     testBucket
+		timeout    string
+		excludedBy []string
+	}{
+		{
+			test:    &testCase{name: "[sig-arch][Early] cluster is healthy [Suite:openshift/conformance/parallel]"},
+			bucket:  earlyBucket,
+			timeout: "20m0s",
+		},
+		{
+			test:    &testCase{name: "[sig-storage] volumes work [Suite:openshift/conformance/parallel] [Suite:k8s]", binaryName: "/tmp/k8s-tests", testTimeout: time.Hour},
+			bucket:  storageBucket,
+			timeout: "1h0m0s",
+		},
+		{
+			test:    &testCase{name: "[sig-cli] oc adm must-gather runs [Suite:openshift/conformance/parallel]"},
+			bucket:  mustGatherBucket,
+			timeout: "20m0s",
+		},
+		{
+			test:       &testCase{name: "[sig-apps][apigroup:missing.openshift.io] works [Suite:openshift/conformance/parallel]"},
+			bucket:     openshiftBucket,
+			timeout:    "20m0s",
+			excludedBy: []string{"apigroup"},
+		},
+		{
+			test:       &testCase{name: "[sig-apps][apigroup:missing.openshift.io] broken by rebase [Suite:openshift/disruptive]"},
+			bucket:     openshiftBucket,
+			timeout:    "20m0s",
+			excludedBy: []string{"apigroup", "rebase"},
+		},
+		{
+			test:       &testCase{name: "[sig-node][Late] no crashlooping pods [Suite:openshift/disruptive]"},
+			bucket:     lateBucket,
+			timeout:    "20m0s",
+			excludedBy: []string{"suite"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.test.name, func(t *testing.T) {
+			tc.test.locations = []types.CodeLocation{{FileName: "/go/src/github.com/openshift/origin/test/extended/foo.go", LineNumber: 12}}
+			metadata := testMetadataFor(tc.test, suite, exclusions)
+			if metadata.Bucket != string(tc.bucket) {
+				t.Errorf("expected bucket %s, got %s", tc.bucket, metadata.Bucket)
+			}
+			if metadata.Timeout != tc.timeout {
+				t.Errorf("expected timeout %s, got %s", tc.timeout, metadata.Timeout)
+			}
+			if !reflect.DeepEqual(metadata.ExcludedBy, tc.excludedBy) || metadata.Included != (len(tc.excludedBy) == 0) {
+				t.Errorf("expected to be excluded by %v, got %v (included %t)", tc.excludedBy, metadata.ExcludedBy, metadata.Included)
+			}
+			if excludedByRebase := strings.Contains(tc.test.name, "broken by rebase"); excludedByRebase != (metadata.RebaseExclusionReason == exclusion.Reason) {
+				t.Errorf("unexpected rebase exclusion reason %q", metadata.RebaseExclusionReason)
+			}
+			if metadata.External != (len(tc.test.binaryName) > 0) || metadata.Binary != tc.test.binaryName {
+				t.Errorf("unexpected external binary %q (external %t)", metadata.Binary, metadata.External)
+			}
+			if !reflect.DeepEqual(metadata.Locations, []string{"/go/src/github.com/openshift/origin/test/extended/foo.go:12"}) {
+				t.Errorf("unexpected locations %v", metadata.Locations)
+			}
+		})
+	}
+}
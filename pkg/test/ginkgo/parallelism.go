@@ -0,0 +1,306 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	utilmetrics "github.com/openshift/library-go/test/library/metrics"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const (
+	// referenceClusterNodes is the size of the clusters the parallelism of the suites is tuned for,
+	// three control plane nodes and three workers.
+	referenceClusterNodes = 6
+	// maximumParallelismScale bounds how far above the parallelism of the suite large clusters go.
+	maximumParallelismScale = 3
+	// testsPerAllocatableCore bounds the parallelism to the compute the cluster has for test pods.
+	testsPerAllocatableCore = 2
+
+	// saturatedRequestLatency is the 99th percentile of the apiserver request latency above which
+	// fewer tests are run, and idleRequestLatency the one below which more tests are run.
+	saturatedRequestLatency = time.Second
+	idleRequestLatency      = 300 * time.Millisecond
+
+	// controlPlaneLoadInterval is how often the load of the control plane is measured.
+	controlPlaneLoadInterval = 30 * time.Second
+
+	requestLatencyQuery   = `histogram_quantile(0.99, sum(rate(apiserver_request_duration_seconds_bucket{verb!~"WATCH|CONNECT"}[2m])) by (le))`
+	rejectedRequestsQuery = `sum(rate(apiserver_flowcontrol_rejected_requests_total[2m]))`
+)
+
+// clusterSize is the compute available to the tests.
+type clusterSize struct {
+	// nodes is the number of schedulable nodes.
+	nodes int
+	// allocatableCPU is the allocatable CPU of the schedulable nodes, in cores.
+	allocatableCPU int64
+}
+
+func readClusterSize(ctx context.Context, kubeClient kubernetes.Interface) (clusterSize, error) {
+	nodes, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return clusterSize{}, err
+	}
+	size := clusterSize{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		size.nodes++
+		size.allocatableCPU += node.Status.Allocatable.Cpu().Value()
+	}
+	return size, nil
+}
+
+// ceilingForClusterSize returns the highest parallelism the cluster allows, proportional to its
+// nodes relative to the clusters the parallelism of the suite is tuned for.
+func ceilingForClusterSize(base int, size clusterSize) int {
+	ceiling := base * size.nodes / referenceClusterNodes
+	ceiling = min(ceiling, base*maximumParallelismScale)
+	if size.allocatableCPU > 0 {
+		ceiling = min(ceiling, int(size.allocatableCPU)*testsPerAllocatableCore)
+	}
+	return max(1, ceiling)
+}
+
+// controlPlaneLoad is a measurement of how busy the apiservers are.
+type controlPlaneLoad struct {
+	// requestLatency is the 99th percentile of the latency of the apiserver requests, without watches.
+	requestLatency time.Duration
+	// rejectedRequests is the rate of requests rejected by API priority and fairness, per second.
+	rejectedRequests float64
+}
+
+func (l controlPlaneLoad) String() string {
+	return fmt.Sprintf("apiserver p99 latency %s, %.2f rejected requests/s", l.requestLatency.Round(time.Millisecond), l.rejectedRequests)
+}
+
+func queryControlPlaneLoad(ctx context.Context, client prometheusv1.API) (controlPlaneLoad, error) {
+	latency, err := queryScalar(ctx, client, requestLatencyQuery)
+	if err != nil {
+		return controlPlaneLoad{}, err
+	}
+	rejected, err := queryScalar(ctx, client, rejectedRequestsQuery)
+	if err != nil {
+		return controlPlaneLoad{}, err
+	}
+	return controlPlaneLoad{
+		requestLatency:   time.Duration(latency * float64(time.Second)),
+		rejectedRequests: rejected,
+	}, nil
+}
+
+// queryScalar returns the value of the first sample of an instant query, 0 if there is none.
+func queryScalar(ctx context.Context, client prometheusv1.API, query string) (float64, error) {
+	result, _, err := client.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("unable to query %s: %w", query, err)
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, fmt.Errorf("unexpected result type %s for %s", result.Type(), query)
+	}
+	if len(vector) == 0 || math.IsNaN(float64(vector[0].Value)) {
+		return 0, nil
+	}
+	return float64(vector[0].Value), nil
+}
+
+// adaptiveParallelism scales the number of tests running at once to the size of the cluster and to
+// the load of its control plane.  It starts at the parallelism of the suite, bounded by the size of the
+// cluster, lowers the parallelism by a quarter while the apiservers are saturated, and raises it by a
+// tenth of the ceiling while they are idle.  Every parallelism is recorded as an interval.
+//
+// The parallelism of a bucket is relative to the parallelism of the suite, so that storage tests
+// still run at half the parallelism of the other tests.
+type adaptiveParallelism struct {
+	// base is the parallelism of the suite.
+	base int
+	// ceiling is the highest parallelism the size of the cluster allows.
+	ceiling  int
+	recorder monitorapi.RecorderWriter
+	out      io.Writer
+
+	lock    sync.Mutex
+	changed *sync.Cond
+	limit   int
+	running int
+	// interval is the started interval of the current limit.
+	interval int
+	// stopped is set once the parallelism no longer changes.
+	stopped bool
+}
+
+func newAdaptiveParallelism(base int, size clusterSize, recorder monitorapi.RecorderWriter, out io.Writer, start time.Time) *adaptiveParallelism {
+	a := &adaptiveParallelism{
+		base:     base,
+		ceiling:  ceilingForClusterSize(base, size),
+		recorder: recorder,
+		out:      out,
+		interval: -1,
+	}
+	a.changed = sync.NewCond(&a.lock)
+	a.setLimit(min(base, a.ceiling), fmt.Sprintf("%d schedulable nodes with %d cores", size.nodes, size.allocatableCPU), start)
+	return a
+}
+
+// startAdaptiveParallelism measures the cluster and adjusts the parallelism to the load of its
+// control plane until the context is done.  Without cluster monitoring, only the size of the cluster
+// is taken into account.
+func startAdaptiveParallelism(ctx context.Context, restConfig *rest.Config, base int, recorder monitorapi.RecorderWriter, out io.Writer) (*adaptiveParallelism, error) {
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	size, err := readClusterSize(ctx, kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the size of the cluster: %w", err)
+	}
+	a := newAdaptiveParallelism(base, size, recorder, out, time.Now())
+
+	routeClient, err := routeclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	prometheusClient, err := utilmetrics.NewPrometheusClient(ctx, kubeClient, routeClient)
+	if err != nil {
+		fmt.Fprintf(out, "Unable to measure the load of the control plane, parallelism only depends on the size of the cluster: %v\n", err)
+		return a, nil
+	}
+	go a.run(ctx, func(ctx context.Context) (controlPlaneLoad, error) {
+		return queryControlPlaneLoad(ctx, prometheusClient)
+	})
+	return a, nil
+}
+
+func (a *adaptiveParallelism) run(ctx context.Context, measure func(context.Context) (controlPlaneLoad, error)) {
+	ticker := time.NewTicker(controlPlaneLoadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		load, err := measure(ctx)
+		if err != nil {
+			fmt.Fprintf(a.out, "Unable to measure the load of the control plane: %v\n", err)
+			continue
+		}
+		a.adjust(load, time.Now())
+	}
+}
+
+// adjust changes the parallelism according to the load of the control plane.
+func (a *adaptiveParallelism) adjust(load controlPlaneLoad, now time.Time) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.stopped {
+		return
+	}
+
+	limit := a.limit
+	switch {
+	case load.rejectedRequests > 0 || load.requestLatency > saturatedRequestLatency:
+		limit = max(1, limit*3/4)
+	case load.requestLatency < idleRequestLatency:
+		limit = min(a.ceiling, limit+max(1, a.ceiling/10))
+	}
+	if limit != a.limit {
+		a.setLimit(limit, load.String(), now)
+	}
+}
+
+// setLimit must be called with the lock held.
+func (a *adaptiveParallelism) setLimit(limit int, reason string, now time.Time) {
+	fmt.Fprintf(a.out, "Setting parallelism to %d of at most %d: %s\n", limit, a.ceiling, reason)
+	if a.interval >= 0 {
+		a.recorder.EndInterval(a.interval, now)
+	}
+	a.interval = a.recorder.StartInterval(
+		monitorapi.NewInterval(monitorapi.SourceTestParallelism, monitorapi.Info).
+			Locator(monitorapi.NewLocator().TestRunner("parallelism")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.ParallelismChangedReason).
+				HumanMessage(fmt.Sprintf("running up to %d tests at once: %s", limit, reason)).
+				WithAnnotation(monitorapi.AnnotationParallelism, fmt.Sprintf("%d", limit))).
+			Display().
+			Build(now, time.Time{}),
+	)
+	a.limit = limit
+	a.changed.Broadcast()
+}
+
+// Stop ends the interval of the current parallelism, which no longer changes afterwards.
+func (a *adaptiveParallelism) Stop() {
+	if a == nil {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.stopped = true
+	if a.interval >= 0 {
+		a.recorder.EndInterval(a.interval, time.Now())
+		a.interval = -1
+	}
+}
+
+// workers returns the number of workers a bucket of the given parallelism needs to reach the ceiling.
+func (a *adaptiveParallelism) workers(parallelism int) int {
+	return max(1, parallelism*a.ceiling/a.base)
+}
+
+// acquire waits until a test of a bucket of the given parallelism may run, and returns false if the
+// context is done first.  Every successful acquire must be followed by a release.
+func (a *adaptiveParallelism) acquire(ctx context.Context, parallelism int) bool {
+	stop := context.AfterFunc(ctx, func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+		a.changed.Broadcast()
+	})
+	defer stop()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for ctx.Err() == nil && a.running >= max(1, parallelism*a.limit/a.base) {
+		a.changed.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	a.running++
+	return true
+}
+
+func (a *adaptiveParallelism) release() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.running--
+	a.changed.Broadcast()
+}
+
+// adaptiveTestSuiteRunner runs a test once the adaptive parallelism allows it.
+type adaptiveTestSuiteRunner struct {
+	testSuiteRunner
+	adaptive    *adaptiveParallelism
+	parallelism int
+}
+
+func (r *adaptiveTestSuiteRunner) RunOneTest(ctx context.Context, test *testCase) {
+	if !r.adaptive.acquire(ctx, r.parallelism) {
+		return
+	}
+	defer r.adaptive.release()
+	r.testSuiteRunner.RunOneTest(ctx, test)
+}
@@ -0,0 +1,126 @@
+package ginkgo
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestCeilingForClusterSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		size     clusterSize
+		expected int
+	}{
+		{name: "single node", size: clusterSize{nodes: 1, allocatableCPU: 8}, expected: 5},
+		{name: "reference cluster", size: clusterSize{nodes: 6, allocatableCPU: 24}, expected: 30},
+		{name: "large cluster", size: clusterSize{nodes: 60, allocatableCPU: 960}, expected: 90},
+		{name: "few cores", size: clusterSize{nodes: 12, allocatableCPU: 20}, expected: 40},
+		{name: "unknown cores", size: clusterSize{nodes: 3}, expected: 15},
+		{name: "no schedulable nodes", size: clusterSize{}, expected: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ceilingForClusterSize(30, tc.size); got != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestAdaptiveParallelism_adjust(t *testing.T) {
+	recorder := monitor.NewRecorder()
+	start := time.Now().Add(-time.Hour)
+	a := newAdaptiveParallelism(30, clusterSize{nodes: 12, allocatableCPU: 96}, recorder, io.Discard, start)
+	if a.limit != 30 || a.ceiling != 60 {
+		t.Fatalf("expected to start at 30 of at most 60, got %d of %d", a.limit, a.ceiling)
+	}
+
+	for i, step := range []struct {
+		load     controlPlaneLoad
+		expected int
+	}{
+		{load: controlPlaneLoad{requestLatency: 100 * time.Millisecond}, expected: 36},
+		{load: controlPlaneLoad{requestLatency: 500 * time.Millisecond}, expected: 36},
+		{load: controlPlaneLoad{requestLatency: 2 * time.Second}, expected: 27},
+		{load: controlPlaneLoad{requestLatency: 500 * time.Millisecond, rejectedRequests: 0.5}, expected: 20},
+		{load: controlPlaneLoad{requestLatency: 100 * time.Millisecond}, expected: 26},
+	} {
+		a.adjust(step.load, start.Add(time.Duration(i+1)*time.Minute))
+		if a.limit != step.expected {
+			t.Errorf("step %d: expected %d, got %d", i, step.expected, a.limit)
+		}
+	}
+	a.Stop()
+	// a measurement taken while stopping no longer changes the parallelism
+	a.adjust(controlPlaneLoad{requestLatency: 2 * time.Second}, start.Add(10*time.Minute))
+
+	intervals := recorder.Intervals(time.Time{}, time.Time{}).Filter(func(i monitorapi.Interval) bool {
+		return i.Source == monitorapi.SourceTestParallelism
+	})
+	var limits []string
+	for _, interval := range intervals {
+		limits = append(limits, interval.Message.Annotations[monitorapi.AnnotationParallelism])
+		if interval.To.IsZero() {
+			t.Errorf("expected every interval to end: %v", interval)
+		}
+	}
+	if expected := []string{"30", "36", "27", "20", "26"}; !reflect.DeepEqual(limits, expected) {
+		t.Errorf("expected an interval for every parallelism %v, got %v", expected, limits)
+	}
+}
+
+func TestAdaptiveParallelism_limitsRunningTests(t *testing.T) {
+	a := newAdaptiveParallelism(10, clusterSize{nodes: 6}, monitor.NewRecorder(), io.Discard, time.Now())
+	a.lock.Lock()
+	a.setLimit(4, "test", time.Now())
+	a.lock.Unlock()
+
+	runner := &concurrencyRecordingRunner{}
+	var tests []*testCase
+	for i := 0; i < 40; i++ {
+		tests = append(tests, &testCase{name: "test"})
+	}
+
+	// half of the parallelism of the suite, like storage tests
+	execute(context.TODO(), &adaptiveTestSuiteRunner{testSuiteRunner: runner, adaptive: a, parallelism: 5}, tests, a.workers(5))
+	if runner.calls != 40 {
+		t.Errorf("expected every test to run, got %d", runner.calls)
+	}
+	if runner.maximum != 2 {
+		t.Errorf("expected at most 2 tests at once, got %d", runner.maximum)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.running = 2
+	go cancel()
+	if a.acquire(ctx, 5) {
+		t.Errorf("expected acquire to give up once the context is done")
+	}
+}
+
+type concurrencyRecordingRunner struct {
+	lock             sync.Mutex
+	calls            int
+	running, maximum int
+}
+
+func (r *concurrencyRecordingRunner) RunOneTest(ctx context.Context, test *testCase) {
+	r.lock.Lock()
+	r.calls++
+	r.running++
+	r.maximum = max(r.maximum, r.running)
+	r.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	r.lock.Lock()
+	r.running--
+	r.lock.Unlock()
+}
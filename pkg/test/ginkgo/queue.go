@@ -11,16 +11,21 @@ import (
 // parallelByFileTestQueue runs tests in parallel unless they have
 // the `[Serial]` tag on their name or if another test with the
 // testExclusion field is currently running. Serial tests are
-// defered until all other tests are completed.
+// defered until all other tests are completed. When a duration history
+// is available, the longest expected tests are started first.
 type parallelByFileTestQueue struct {
 	commandContext *commandContext
+	testDurations  testDurationHistory
+	// adaptive, if set, scales the parallelism of every Execute to the cluster.
+	adaptive *adaptiveParallelism
 }
 
 type TestFunc func(ctx context.Context, test *testCase)
 
-func newParallelTestQueue(commandContext *commandContext) *parallelByFileTestQueue {
+func newParallelTestQueue(commandContext *commandContext, testDurations testDurationHistory) *parallelByFileTestQueue {
 	return &parallelByFileTestQueue{
 		commandContext: commandContext,
+		testDurations:  testDurations,
 	}
 }
 
@@ -89,14 +94,19 @@ func runTestsUntilChannelEmpty(ctx context.Context, remainingParallelTests chan
 // tests are currently being mutated during the run process.
 func (q *parallelByFileTestQueue) Execute(ctx context.Context, tests []*testCase, parallelism int, testOutput testOutputConfig, maybeAbortOnFailureFn testAbortFunc) {
 	testSuiteProgress := newTestSuiteProgress(len(tests))
-	testSuiteRunner := &testSuiteRunnerImpl{
+	var testSuiteRunner testSuiteRunner = &testSuiteRunnerImpl{
 		commandContext:        q.commandContext,
 		testOutput:            testOutput,
 		testSuiteProgress:     testSuiteProgress,
 		maybeAbortOnFailureFn: maybeAbortOnFailureFn,
 	}
 
-	execute(ctx, testSuiteRunner, tests, parallelism)
+	workers := parallelism
+	if q.adaptive != nil {
+		testSuiteRunner = &adaptiveTestSuiteRunner{testSuiteRunner: testSuiteRunner, adaptive: q.adaptive, parallelism: parallelism}
+		workers = q.adaptive.workers(parallelism)
+	}
+	execute(ctx, testSuiteRunner, q.testDurations.longestFirst(tests), workers)
 }
 
 // execute is a convenience for unit testing
@@ -136,6 +146,43 @@ func isSerialTest(test *testCase) bool {
 	return false
 }
 
+// testBucket is one of the groups of tests a suite runs one after another.
+type testBucket string
+
+const (
+	earlyBucket      testBucket = "early"
+	kubeBucket       testBucket = "kube"
+	storageBucket    testBucket = "storage"
+	openshiftBucket  testBucket = "openshift"
+	mustGatherBucket testBucket = "must-gather"
+	lateBucket       testBucket = "late"
+)
+
+// bucketForTest returns the bucket the test runs in.
+func bucketForTest(test *testCase) testBucket {
+	switch {
+	case strings.Contains(test.name, "[Early]"):
+		return earlyBucket
+	case strings.Contains(test.name, "[Late]"):
+		return lateBucket
+	case strings.Contains(test.name, "[Suite:k8s]") && strings.Contains(test.name, "[sig-storage]"):
+		return storageBucket
+	case strings.Contains(test.name, "[Suite:k8s]"):
+		return kubeBucket
+	case strings.Contains(test.name, "[sig-cli] oc adm must-gather"):
+		return mustGatherBucket
+	default:
+		return openshiftBucket
+	}
+}
+
+// inBucket returns a function to split the tests of the bucket from the other tests.
+func inBucket(bucket testBucket) func(*testCase) bool {
+	return func(t *testCase) bool {
+		return bucketForTest(t) == bucket
+	}
+}
+
 func copyTests(tests []*testCase) []*testCase {
 	copied := make([]*testCase, 0, len(tests))
 	for _, t := range tests {
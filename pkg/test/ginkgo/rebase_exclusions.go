@@ -0,0 +1,257 @@
+package ginkgo
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultRebaseExclusions is the manifest of the tests excluded while a kube rebase is in progress,
+// maintained by the rebase owners.
+//
+//go:embed rebase_exclusions.yaml
+var defaultRebaseExclusions []byte
+
+// RebaseExclusionManifest lists the tests that cannot pass while a kube rebase is in progress.
+type RebaseExclusionManifest struct {
+	Exclusions []RebaseExclusion `json:"exclusions"`
+}
+
+// RebaseExclusion excludes the tests matching Test from runs against the clusters in its version
+// ranges.
+type RebaseExclusion struct {
+	// Test is a regular expression matching the names of the excluded tests.
+	Test string `json:"test"`
+	// KubeMinorVersions is the inclusive range of minor versions of the kube-apiserver the tests are
+	// excluded on, e.g. "32" or "31-32". Empty matches every version.
+	KubeMinorVersions string `json:"kubeMinorVersions,omitempty"`
+	// ClusterVersions is the inclusive range of OpenShift versions the tests are excluded on, e.g.
+	// "4.19" or "4.18-4.19". Empty matches every version, including clusters without one.
+	ClusterVersions string `json:"clusterVersions,omitempty"`
+	// Reason explains why the tests cannot pass, usually with a link to the bug tracking it.
+	Reason string `json:"reason"`
+
+	test           *regexp.Regexp
+	kubeMinor      [2]int
+	clusterVersion [2]*utilversion.Version
+}
+
+// ClusterVersion are the versions of a cluster that decide which rebase exclusions apply.
+type ClusterVersion struct {
+	// Kube is the version of the kube-apiserver.
+	Kube *version.Info
+	// OpenShift is the desired version of the cluster, empty when it has no ClusterVersion.
+	OpenShift string
+}
+
+// DiscoverClusterVersion reads the versions of the cluster the rebase exclusions are evaluated against.
+// The OpenShift version is left empty with a warning when the ClusterVersion cannot be read, e.g.
+// when the user may not get it, so that only the exclusions without clusterVersions can apply.
+func DiscoverClusterVersion(ctx context.Context, restConfig *rest.Config) (*ClusterVersion, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	clusterVersion := &ClusterVersion{Kube: serverVersion}
+
+	configClient, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	cv, err := configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		logrus.Warningf("Unable to get the ClusterVersion, the OpenShift version is unknown: %v", err)
+	default:
+		clusterVersion.OpenShift = cv.Status.Desired.Version
+	}
+	return clusterVersion, nil
+}
+
+// loadRebaseExclusions reads the manifest at path, or the default manifest if path is empty.
+func loadRebaseExclusions(path string) (*RebaseExclusionManifest, error) {
+	data := defaultRebaseExclusions
+	if len(path) > 0 {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &RebaseExclusionManifest{}
+	if err := yaml.UnmarshalStrict(data, manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse rebase exclusions: %w", err)
+	}
+	var errs []error
+	for i := range manifest.Exclusions {
+		if err := manifest.Exclusions[i].compile(); err != nil {
+			errs = append(errs, fmt.Errorf("exclusions[%d]: %w", i, err))
+		}
+	}
+	if err := errors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (e *RebaseExclusion) compile() error {
+	if len(e.Test) == 0 {
+		return fmt.Errorf("missing test")
+	}
+	if len(e.Reason) == 0 {
+		return fmt.Errorf("missing reason")
+	}
+	var err error
+	if e.test, err = regexp.Compile(e.Test); err != nil {
+		return fmt.Errorf("invalid test: %w", err)
+	}
+
+	e.kubeMinor = [2]int{0, -1}
+	if len(e.KubeMinorVersions) > 0 {
+		for i, value := range versionRange(e.KubeMinorVersions) {
+			if e.kubeMinor[i], err = strconv.Atoi(value); err != nil || e.kubeMinor[i] < 0 {
+				return fmt.Errorf("kubeMinorVersions must be a minor version or a range of them, e.g. 31-32, not %q", e.KubeMinorVersions)
+			}
+		}
+		if e.kubeMinor[0] > e.kubeMinor[1] {
+			return fmt.Errorf("kubeMinorVersions %q is empty", e.KubeMinorVersions)
+		}
+	}
+
+	if len(e.ClusterVersions) > 0 {
+		for i, value := range versionRange(e.ClusterVersions) {
+			v, err := utilversion.ParseGeneric(value)
+			if err != nil || len(v.Components()) != 2 {
+				return fmt.Errorf("clusterVersions must be a MAJOR.MINOR version or a range of them, e.g. 4.18-4.19, not %q", e.ClusterVersions)
+			}
+			e.clusterVersion[i] = v
+		}
+		if e.clusterVersion[1].LessThan(e.clusterVersion[0]) {
+			return fmt.Errorf("clusterVersions %q is empty", e.ClusterVersions)
+		}
+	}
+	return nil
+}
+
+// versionRange splits a FROM-TO or VERSION range into its inclusive bounds.
+func versionRange(value string) [2]string {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		to = from
+	}
+	return [2]string{strings.TrimSpace(from), strings.TrimSpace(to)}
+}
+
+// appliesTo returns true if the exclusion applies to a cluster of the given versions.
+func (e *RebaseExclusion) appliesTo(clusterVersion *ClusterVersion) bool {
+	if len(e.KubeMinorVersions) > 0 {
+		minor, err := strconv.Atoi(strings.TrimSuffix(clusterVersion.Kube.Minor, "+"))
+		if err != nil || minor < e.kubeMinor[0] || minor > e.kubeMinor[1] {
+			return false
+		}
+	}
+	if len(e.ClusterVersions) > 0 {
+		v, err := utilversion.ParseGeneric(clusterVersion.OpenShift)
+		if err != nil {
+			return false
+		}
+		v = utilversion.MajorMinor(v.Major(), v.Minor())
+		if v.LessThan(e.clusterVersion[0]) || e.clusterVersion[1].LessThan(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// rebaseExclusions returns the exclusions of the manifest that apply to a cluster of the given
+// versions, none if the versions are unknown.
+func rebaseExclusions(manifest *RebaseExclusionManifest, clusterVersion *ClusterVersion) []*RebaseExclusion {
+	if clusterVersion == nil || clusterVersion.Kube == nil {
+		return nil
+	}
+	var exclusions []*RebaseExclusion
+	for i := range manifest.Exclusions {
+		if manifest.Exclusions[i].appliesTo(clusterVersion) {
+			exclusions = append(exclusions, &manifest.Exclusions[i])
+		}
+	}
+	return exclusions
+}
+
+// excludedByRebase returns the first exclusion excluding the test, or nil.
+func excludedByRebase(exclusions []*RebaseExclusion, name string) *RebaseExclusion {
+	for _, exclusion := range exclusions {
+		if exclusion.test.MatchString(name) {
+			return exclusion
+		}
+	}
+	return nil
+}
+
+// filterOutRebaseTests removes the tests that cannot pass against the cluster while a kube rebase
+// is in progress, printing each of them with the reason to out.
+func filterOutRebaseTests(manifest *RebaseExclusionManifest, clusterVersion *ClusterVersion, tests []*testCase, out io.Writer) []*testCase {
+	exclusions := rebaseExclusions(manifest, clusterVersion)
+	if len(exclusions) == 0 {
+		return tests
+	}
+	matches := make([]*testCase, 0, len(tests))
+	for _, test := range tests {
+		if exclusion := excludedByRebase(exclusions, test.name); exclusion != nil {
+			fmt.Fprintf(out, "Skipping %q due to rebase in-progress: %s\n", test.name, exclusion.Reason)
+			continue
+		}
+		matches = append(matches, test)
+	}
+	return matches
+}
+
+// printRebaseExclusions prints the tests any exclusion of the manifest matches to out, with the
+// versions of the clusters they are skipped on, without evaluating the exclusions against a cluster.
+func printRebaseExclusions(manifest *RebaseExclusionManifest, tests []*testCase, out io.Writer) {
+	exclusions := make([]*RebaseExclusion, 0, len(manifest.Exclusions))
+	for i := range manifest.Exclusions {
+		exclusions = append(exclusions, &manifest.Exclusions[i])
+	}
+	for _, test := range sortedTests(tests) {
+		if exclusion := excludedByRebase(exclusions, test.name); exclusion != nil {
+			fmt.Fprintf(out, "Skipping %q on %s due to rebase in-progress: %s\n", test.name, exclusion.versions(), exclusion.Reason)
+		}
+	}
+}
+
+// versions describes the clusters the exclusion applies to.
+func (e *RebaseExclusion) versions() string {
+	var versions []string
+	if len(e.KubeMinorVersions) > 0 {
+		versions = append(versions, fmt.Sprintf("kube minor versions %s", e.KubeMinorVersions))
+	}
+	if len(e.ClusterVersions) > 0 {
+		versions = append(versions, fmt.Sprintf("cluster versions %s", e.ClusterVersions))
+	}
+	if len(versions) == 0 {
+		return "every cluster"
+	}
+	return strings.Join(versions, " and ")
+}
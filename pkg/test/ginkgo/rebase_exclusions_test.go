@@ -0,0 +1,238 @@
+package ginkgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/rest"
+)
+
+func TestLoadRebaseExclusions(t *testing.T) {
+	if _, err := loadRebaseExclusions(""); err != nil {
+		t.Fatalf("the built in manifest is invalid: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		expectErr string
+	}{
+		{
+			name: "valid",
+			content: `exclusions:
+- test: '\[sig-storage\] CSI mock volume .* should expand volume'
+  kubeMinorVersions: "31-32"
+  clusterVersions: "4.19"
+  reason: https://issues.redhat.com/browse/OCPBUGS-1
+`,
+		},
+		{
+			name:      "unknown field",
+			content:   "exclusions:\n- test: foo\n  reason: bar\n  kubeVersions: \"32\"\n",
+			expectErr: "unable to parse rebase exclusions",
+		},
+		{
+			name: "invalid exclusions",
+			content: `exclusions:
+- reason: no test
+- test: '('
+  reason: invalid regexp
+- test: foo
+  kubeMinorVersions: "1.32"
+  reason: not a minor version
+- test: foo
+  kubeMinorVersions: "33-32"
+  reason: empty range
+- test: foo
+  clusterVersions: "4.19.1"
+  reason: not a minor version
+- test: foo
+`,
+			expectErr: "exclusions[0]: missing test",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rebase.yaml")
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadRebaseExclusions(path)
+			if len(test.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", test.expectErr, err)
+				}
+				if test.name == "invalid exclusions" && !strings.Contains(err.Error(), "exclusions[5]: missing reason") {
+					t.Errorf("expected every invalid exclusion to be reported, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFilterOutRebaseTests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rebase.yaml")
+	content := `exclusions:
+- test: '\[sig-storage\] volumes expand'
+  kubeMinorVersions: "31-32"
+  reason: storage rebase bug
+- test: '\[sig-apps\] deployments roll'
+  clusterVersions: "4.18-4.19"
+  reason: apps rebase bug
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := loadRebaseExclusions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []*testCase{
+		{name: "[sig-storage] volumes expand [Suite:openshift/conformance/parallel]"},
+		{name: "[sig-apps] deployments roll [Suite:openshift/conformance/parallel]"},
+		{name: "[sig-cli] oc works [Suite:openshift/conformance/parallel]"},
+	}
+
+	testCases := []struct {
+		name           string
+		clusterVersion *ClusterVersion
+		expected       []string
+	}{
+		{
+			name:     "unknown versions",
+			expected: []string{"storage", "apps", "cli"},
+		},
+		{
+			name:           "both exclusions",
+			clusterVersion: &ClusterVersion{Kube: &version.Info{Minor: "32+"}, OpenShift: "4.19.0-0.nightly-2024-10-01-000000"},
+			expected:       []string{"cli"},
+		},
+		{
+			name:           "kube version out of range",
+			clusterVersion: &ClusterVersion{Kube: &version.Info{Minor: "33"}, OpenShift: "4.18.3"},
+			expected:       []string{"storage", "cli"},
+		},
+		{
+			name:           "without cluster version",
+			clusterVersion: &ClusterVersion{Kube: &version.Info{Minor: "31"}},
+			expected:       []string{"apps", "cli"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			filtered := filterOutRebaseTests(manifest, tc.clusterVersion, tests, out)
+			var got []string
+			for _, test := range filtered {
+				got = append(got, strings.TrimSuffix(strings.TrimPrefix(strings.Fields(test.name)[0], "[sig-"), "]"))
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+			if skipped := len(tests) - len(filtered); strings.Count(out.String(), "due to rebase in-progress: ") != skipped {
+				t.Errorf("expected %d skipped tests with their reason, got:\n%s", skipped, out.String())
+			}
+		})
+	}
+}
+
+func TestDiscoverClusterVersion(t *testing.T) {
+	testCases := []struct {
+		name              string
+		clusterVersion    int
+		expectedOpenShift string
+	}{
+		{
+			name:              "cluster version",
+			clusterVersion:    http.StatusOK,
+			expectedOpenShift: "4.19.0",
+		},
+		{
+			name:           "no cluster version",
+			clusterVersion: http.StatusNotFound,
+		},
+		{
+			name:           "forbidden cluster version",
+			clusterVersion: http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/version":
+					json.NewEncoder(w).Encode(version.Info{Major: "1", Minor: "32"})
+				case "/apis/config.openshift.io/v1/clusterversions/version":
+					w.WriteHeader(tc.clusterVersion)
+					if tc.clusterVersion == http.StatusOK {
+						json.NewEncoder(w).Encode(map[string]interface{}{
+							"apiVersion": "config.openshift.io/v1",
+							"kind":       "ClusterVersion",
+							"status":     map[string]interface{}{"desired": map[string]interface{}{"version": "4.19.0"}},
+						})
+					} else {
+						json.NewEncoder(w).Encode(map[string]interface{}{"kind": "Status", "apiVersion": "v1", "code": tc.clusterVersion})
+					}
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			clusterVersion, err := DiscoverClusterVersion(context.Background(), &rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if clusterVersion.Kube.Minor != "32" || clusterVersion.OpenShift != tc.expectedOpenShift {
+				t.Errorf("expected kube minor 32 and OpenShift %q, got %#v", tc.expectedOpenShift, clusterVersion)
+			}
+		})
+	}
+}
+
+func TestPrintRebaseExclusions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rebase.yaml")
+	content := `exclusions:
+- test: '\[sig-storage\] volumes expand'
+  kubeMinorVersions: "31-32"
+  clusterVersions: "4.19"
+  reason: storage rebase bug
+- test: '\[sig-apps\] deployments roll'
+  reason: apps rebase bug
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := loadRebaseExclusions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []*testCase{
+		{name: "[sig-storage] volumes expand"},
+		{name: "[sig-cli] oc works"},
+		{name: "[sig-apps] deployments roll"},
+	}
+
+	out := &bytes.Buffer{}
+	printRebaseExclusions(manifest, tests, out)
+	expected := `Skipping "[sig-apps] deployments roll" on every cluster due to rebase in-progress: apps rebase bug
+Skipping "[sig-storage] volumes expand" on kube minor versions 31-32 and cluster versions 4.19 due to rebase in-progress: storage rebase bug
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
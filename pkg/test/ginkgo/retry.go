@@ -0,0 +1,158 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failing tests are retried to tell flakes from real failures.  A suite
+// without a policy retries every failure once, and only when no more tests failed than the suite
+// allows to flake.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a failing test is run in total, including the first attempt.
+	// Zero means two attempts, one disables retries.
+	MaxAttempts int
+	// Overrides replace MaxAttempts for tests whose name matches.  The first match wins.
+	Overrides []RetryOverride
+	// Isolated runs retries one at a time instead of at the parallelism of the suite, for tests
+	// that only fail while the cluster is loaded by other tests.
+	Isolated bool
+	// Backoff is the time to wait before the first retry.  It is doubled before every further attempt.
+	Backoff time.Duration
+	// AllowAllFlakes passes the run however many tests flaked.  Otherwise the run fails if more tests
+	// flaked than the MaximumAllowedFlakes of the suite, so a suite that allows none fails on any flake.
+	AllowAllFlakes bool
+}
+
+// RetryOverride sets the number of attempts for the tests matching Pattern.
+type RetryOverride struct {
+	Pattern     *regexp.Regexp
+	MaxAttempts int
+}
+
+// ParseRetryOverride parses an override in the form REGEX=ATTEMPTS.
+func ParseRetryOverride(value string) (RetryOverride, error) {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 {
+		return RetryOverride{}, fmt.Errorf("expected REGEX=ATTEMPTS, got %q", value)
+	}
+	pattern, err := regexp.Compile(value[:i])
+	if err != nil {
+		return RetryOverride{}, fmt.Errorf("invalid pattern in %q: %w", value, err)
+	}
+	attempts, err := strconv.Atoi(value[i+1:])
+	if err != nil || attempts < 1 {
+		return RetryOverride{}, fmt.Errorf("invalid number of attempts in %q, must be at least 1", value)
+	}
+	return RetryOverride{Pattern: pattern, MaxAttempts: attempts}, nil
+}
+
+// attemptsFor returns the total number of attempts allowed for the named test.
+func (p *RetryPolicy) attemptsFor(name string) int {
+	maxAttempts := p.MaxAttempts
+	for _, override := range p.Overrides {
+		if override.Pattern.MatchString(name) {
+			maxAttempts = override.MaxAttempts
+			break
+		}
+	}
+	if maxAttempts == 0 {
+		return 2
+	}
+	return maxAttempts
+}
+
+// flakesAllowed returns true if the run passes with the given number of flaky tests.
+func (p *RetryPolicy) flakesAllowed(flaky, maximumAllowedFlakes int) bool {
+	return p.AllowAllFlakes || flaky <= maximumAllowedFlakes
+}
+
+// backoffBefore returns the time to wait before the given attempt, where the first attempt is 1.
+func (p *RetryPolicy) backoffBefore(attempt int) time.Duration {
+	if p.Backoff <= 0 || attempt < 2 {
+		return 0
+	}
+	return p.Backoff << (attempt - 2)
+}
+
+// executeTestsFunc runs the tests at the given parallelism, mutating them with their results.
+type executeTestsFunc func(ctx context.Context, tests []*testCase, parallelism int)
+
+// retryResult is the outcome of retrying failures under a RetryPolicy.
+type retryResult struct {
+	// retries holds every retry attempt that completed, to be reported next to the original failures.
+	retries []*testCase
+	// failing holds the last attempt of every test that failed all of its attempts.
+	failing []*testCase
+	// flaky holds the names of the tests that passed on a later attempt.
+	flaky []string
+	// skipped holds the names of the tests that were skipped on a later attempt, which means the
+	// original failure was very likely a failed precondition.
+	skipped []string
+}
+
+// retryFailures runs the failing tests again until they pass, are skipped, or exhaust the attempts
+// the policy allows for them.  Every attempt is kept as a separate result.
+func retryFailures(ctx context.Context, policy *RetryPolicy, failing []*testCase, parallelism int, execute executeTestsFunc, out io.Writer) retryResult {
+	result := retryResult{}
+	if policy.Isolated {
+		parallelism = 1
+	}
+
+	for attempt := 2; ctx.Err() == nil; attempt++ {
+		var retries, exhausted []*testCase
+		for _, test := range failing {
+			if attempt > policy.attemptsFor(test.name) {
+				exhausted = append(exhausted, test)
+				continue
+			}
+			retries = append(retries, test.Retry())
+		}
+		result.failing = append(result.failing, exhausted...)
+		if len(retries) == 0 {
+			return result
+		}
+
+		if backoff := policy.backoffBefore(attempt); backoff > 0 {
+			fmt.Fprintf(out, "Waiting %s before retrying\n", backoff)
+			select {
+			case <-ctx.Done():
+				result.failing = append(result.failing, failing...)
+				return result
+			case <-time.After(backoff):
+			}
+		}
+
+		fmt.Fprintf(out, "Retry count: %d, attempt %d\n", len(retries), attempt)
+		execute(ctx, retries, parallelism)
+
+		failing = nil
+		for _, retry := range retries {
+			switch {
+			case retry.success:
+				result.flaky = append(result.flaky, retry.name)
+			case retry.skipped:
+				result.skipped = append(result.skipped, retry.name)
+			case retry.failed, retry.flake:
+				failing = append(failing, retry)
+			default:
+				// the run was interrupted before the retry completed
+				failing = append(failing, retry)
+				continue
+			}
+			if retry.flake {
+				// Retry tests that flaked are omitted so that the original test is counted as a failure.
+				fmt.Fprintf(out, "Ignoring retry that returned a flake, original failure is authoritative for test: %s\n", retry.name)
+				continue
+			}
+			result.retries = append(result.retries, retry)
+		}
+	}
+	result.failing = append(result.failing, failing...)
+	return result
+}
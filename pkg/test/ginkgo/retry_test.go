@@ -0,0 +1,151 @@
+package ginkgo
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseRetryOverride(t *testing.T) {
+	override, err := ParseRetryOverride(`\[sig-storage\].*size=1Gi=3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if override.MaxAttempts != 3 || !override.Pattern.MatchString("[sig-storage] volume size=1Gi") {
+		t.Errorf("unexpected override %#v", override)
+	}
+	for _, value := range []string{"", "=3", "foo", "foo=0", "foo=bar", "[=2"} {
+		if _, err := ParseRetryOverride(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{
+		Overrides: []RetryOverride{
+			{Pattern: regexp.MustCompile(`noisy`), MaxAttempts: 5},
+			{Pattern: regexp.MustCompile(`no`), MaxAttempts: 1},
+		},
+		Backoff: time.Second,
+	}
+	if got := policy.attemptsFor("other"); got != 2 {
+		t.Errorf("expected 2 attempts by default, got %d", got)
+	}
+	if got := policy.attemptsFor("noisy"); got != 5 {
+		t.Errorf("expected the first override to win, got %d", got)
+	}
+	if got := policy.attemptsFor("not retried"); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+	for attempt, expected := range map[int]time.Duration{1: 0, 2: time.Second, 3: 2 * time.Second, 4: 4 * time.Second} {
+		if got := policy.backoffBefore(attempt); got != expected {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, expected, got)
+		}
+	}
+}
+
+func TestRetryFailures(t *testing.T) {
+	// outcomes holds the state of every attempt after the first, by test name
+	outcomes := map[string][]TestState{
+		"passes-on-second":  {TestSucceeded},
+		"passes-on-third":   {TestFailed, TestSucceeded},
+		"always-fails":      {TestFailed, TestFailed, TestFailed},
+		"fails-once":        {TestFailed},
+		"skipped-on-retry":  {TestSkipped},
+		"flakes-on-retry":   {TestFlaked, TestSucceeded},
+		"never-retried-foo": {TestSucceeded},
+	}
+	var failing []*testCase
+	for name := range outcomes {
+		failing = append(failing, &testCase{name: name, failed: true})
+	}
+
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		Overrides: []RetryOverride{
+			{Pattern: regexp.MustCompile(`fails-once`), MaxAttempts: 2},
+			{Pattern: regexp.MustCompile(`never-retried`), MaxAttempts: 1},
+		},
+		Isolated: true,
+	}
+
+	var parallelisms []int
+	execute := func(ctx context.Context, tests []*testCase, parallelism int) {
+		parallelisms = append(parallelisms, parallelism)
+		for _, test := range tests {
+			state := outcomes[test.name][test.attempt()-2]
+			mutateTestCaseWithResults(test, &testRunResultHandle{testRunResult: &testRunResult{name: test.name, testState: state}})
+		}
+	}
+
+	result := retryFailures(context.TODO(), policy, failing, 10, execute, io.Discard)
+
+	names := func(tests []*testCase) []string {
+		ret := testNames(tests)
+		sort.Strings(ret)
+		return ret
+	}
+	sort.Strings(result.flaky)
+	if expected := []string{"flakes-on-retry", "passes-on-second", "passes-on-third"}; !reflect.DeepEqual(result.flaky, expected) {
+		t.Errorf("expected flaky %v, got %v", expected, result.flaky)
+	}
+	if expected := []string{"skipped-on-retry"}; !reflect.DeepEqual(result.skipped, expected) {
+		t.Errorf("expected skipped %v, got %v", expected, result.skipped)
+	}
+	if expected := []string{"always-fails", "fails-once", "never-retried-foo"}; !reflect.DeepEqual(names(result.failing), expected) {
+		t.Errorf("expected failing %v, got %v", expected, names(result.failing))
+	}
+	// every attempt is reported, except for retries that flaked
+	expectedRetries := []string{"always-fails", "always-fails", "fails-once", "flakes-on-retry", "passes-on-second", "passes-on-third", "passes-on-third", "skipped-on-retry"}
+	if got := names(result.retries); !reflect.DeepEqual(got, expectedRetries) {
+		t.Errorf("expected retries %v, got %v", expectedRetries, got)
+	}
+	if !reflect.DeepEqual(parallelisms, []int{1, 1}) {
+		t.Errorf("expected two isolated rounds of retries, got %v", parallelisms)
+	}
+
+	// every attempt is a separate junit test case with its number
+	junit := generateJUnitTestSuiteResults("suite", time.Second, append(failing, result.retries...), nil)
+	attempts := []string{}
+	for _, testCase := range junit.TestCases {
+		if testCase.Name != "passes-on-third" {
+			continue
+		}
+		for _, property := range testCase.Properties {
+			if property.Name == attemptProperty {
+				attempts = append(attempts, property.Value)
+			}
+		}
+	}
+	if expected := []string{"1", "2", "3"}; !reflect.DeepEqual(attempts, expected) {
+		t.Errorf("expected attempts %v, got %v", expected, attempts)
+	}
+}
+
+func TestRetryPolicyFlakesAllowed(t *testing.T) {
+	tests := []struct {
+		name                 string
+		policy               RetryPolicy
+		flaky                int
+		maximumAllowedFlakes int
+		expected             bool
+	}{
+		{name: "no flakes", expected: true},
+		{name: "suite allows no flakes", flaky: 1},
+		{name: "under the cap", flaky: 2, maximumAllowedFlakes: 2, expected: true},
+		{name: "over the cap", flaky: 3, maximumAllowedFlakes: 2},
+		{name: "all flakes allowed", policy: RetryPolicy{AllowAllFlakes: true}, flaky: 30, expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.flakesAllowed(test.flaky, test.maximumAllowedFlakes); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
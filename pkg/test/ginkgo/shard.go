@@ -0,0 +1,100 @@
+package ginkgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// shardProperty is the JUnit suite property recording which shard produced the results.
+const shardProperty = "Shard"
+
+// serialShard is the shard running the [Serial] tests of a sharded run.
+const serialShard = "serial"
+
+// testShard identifies the subset of a suite run by one of several runner processes against the
+// same cluster.  The numbered shards run the parallel tests side by side, and are assigned them by a
+// stable hash, so every process agrees on it without coordination.  [Serial] tests must not run at the
+// same time as any other test, so none of them are assigned to a numbered shard: they are all run by
+// the serial shard, which must be started after every numbered shard finished.
+type testShard struct {
+	// index is 1-based, and 0 for the serial shard
+	index int
+	total int
+}
+
+// parseTestShard parses a shard in the form INDEX/TOTAL, e.g. 3/8, or serial.
+func parseTestShard(value string) (*testShard, error) {
+	if value == serialShard {
+		return &testShard{}, nil
+	}
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected INDEX/TOTAL or %s, got %q", serialShard, value)
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard index %q: %w", parts[0], err)
+	}
+	total, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard total %q: %w", parts[1], err)
+	}
+	if total < 1 || index < 1 || index > total {
+		return nil, fmt.Errorf("shard %d/%d must satisfy 1 <= INDEX <= TOTAL", index, total)
+	}
+	return &testShard{index: index, total: total}, nil
+}
+
+func (s *testShard) serial() bool {
+	return s.index == 0
+}
+
+func (s *testShard) String() string {
+	if s.serial() {
+		return serialShard
+	}
+	return fmt.Sprintf("%d/%d", s.index, s.total)
+}
+
+// junitFilePrefix distinguishes the JUnit reports of the shards, so they can be collected into one
+// directory and recombined with merge-junit.
+func (s *testShard) junitFilePrefix(filePrefix string) string {
+	if s == nil {
+		return filePrefix
+	}
+	if s.serial() {
+		return fmt.Sprintf("%s_shard-%s", filePrefix, serialShard)
+	}
+	return fmt.Sprintf("%s_shard-%d-of-%d", filePrefix, s.index, s.total)
+}
+
+// Contains returns true if the test is assigned to this shard.
+func (s *testShard) Contains(test *testCase) bool {
+	if isSerialTest(test) || s.serial() {
+		return isSerialTest(test) && s.serial()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(shardKey(test)))
+	return int(h.Sum32()%uint32(s.total)) == s.index-1
+}
+
+// Filter returns the tests assigned to this shard.  A nil shard contains every test.
+func (s *testShard) Filter(tests []*testCase) []*testCase {
+	if s == nil {
+		return tests
+	}
+	matches, _ := splitTests(tests, s.Contains)
+	return matches
+}
+
+// shardKey determines which parallel tests must be placed on the same shard.  Tests sharing a
+// testExclusion may not run at the same time, which can only be guaranteed when a single runner owns
+// all of them.
+func shardKey(test *testCase) string {
+	if len(test.testExclusion) > 0 {
+		return test.testExclusion
+	}
+	return test.name
+}
@@ -0,0 +1,75 @@
+package ginkgo
+
+import (
+	"testing"
+)
+
+func Test_parseTestShard(t *testing.T) {
+	for _, value := range []string{"", "3", "0/8", "9/8", "1/0", "a/b", "1/2/3", "Serial"} {
+		if _, err := parseTestShard(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+	shard, err := parseTestShard("3/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shard.String() != "3/8" || shard.junitFilePrefix("junit_e2e") != "junit_e2e_shard-3-of-8" {
+		t.Errorf("unexpected shard %s", shard)
+	}
+	shard, err = parseTestShard("serial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shard.String() != "serial" || shard.junitFilePrefix("junit_e2e") != "junit_e2e_shard-serial" {
+		t.Errorf("unexpected shard %s", shard)
+	}
+}
+
+func Test_testShardFilter(t *testing.T) {
+	tests := makeTestCases()
+	total := 5
+
+	seen := map[*testCase]int{}
+	for i := 1; i <= total; i++ {
+		shard := &testShard{index: i, total: total}
+		for _, test := range shard.Filter(tests) {
+			seen[test]++
+			if isSerialTest(test) {
+				t.Errorf("expected serial test %q to not run in shard %s", test.name, shard)
+			}
+		}
+		// assignment must be stable
+		if len(shard.Filter(tests)) != len(shard.Filter(copyTests(tests))) {
+			t.Errorf("shard %s is not deterministic", shard)
+		}
+	}
+
+	serial, err := parseTestShard("serial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serialTests := serial.Filter(tests)
+	if len(serialTests) == 0 {
+		t.Fatalf("expected serial tests")
+	}
+	for _, test := range serialTests {
+		seen[test]++
+		if !isSerialTest(test) {
+			t.Errorf("expected parallel test %q to not run in the serial shard", test.name)
+		}
+	}
+
+	if len(seen) != len(tests) {
+		t.Errorf("expected every test to be in a shard, got %d of %d", len(seen), len(tests))
+	}
+	for test, count := range seen {
+		if count != 1 {
+			t.Errorf("expected %q to be in exactly one shard, got %d", test.name, count)
+		}
+	}
+
+	if got := (*testShard)(nil).Filter(tests); len(got) != len(tests) {
+		t.Errorf("expected a nil shard to contain all tests")
+	}
+}
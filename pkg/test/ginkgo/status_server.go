@@ -0,0 +1,274 @@
+package ginkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+// runStatusRecorder wraps the monitor recorder of a suite run.  It keeps a live summary of the run,
+// derived from the e2e test intervals every test records when it starts and finishes, and streams
+// every new interval to the subscribers of the status server.
+type runStatusRecorder struct {
+	monitorapi.Recorder
+
+	lock          sync.Mutex
+	start         time.Time
+	expectedTests int
+	// running are the tests started and not finished yet, by their run, see AnnotationTestRun.
+	running     map[string]runningTest
+	completed   map[string]int
+	failures    []string
+	subscribers map[chan runStatusEvent]struct{}
+}
+
+// runStatusEvent is a single server-sent event.
+type runStatusEvent struct {
+	name string
+	data []byte
+}
+
+// runStatus is the JSON document served at /status.
+type runStatus struct {
+	Start          time.Time      `json:"start"`
+	ElapsedSeconds float64        `json:"elapsedSeconds"`
+	ExpectedTests  int            `json:"expectedTests,omitempty"`
+	CompletedTests int            `json:"completedTests"`
+	Completed      map[string]int `json:"completed"`
+	Running        []runningTest  `json:"running"`
+	Failures       []string       `json:"failures"`
+	// ETA is estimated from the rate at which tests completed so far.
+	ETA *time.Time `json:"eta,omitempty"`
+}
+
+type runningTest struct {
+	Name           string    `json:"name"`
+	Start          time.Time `json:"start"`
+	RunningSeconds float64   `json:"runningSeconds"`
+}
+
+// testCompletion is the data of a "test" event.
+type testCompletion struct {
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+}
+
+func newRunStatusRecorder(delegate monitorapi.Recorder, start time.Time) *runStatusRecorder {
+	return &runStatusRecorder{
+		Recorder:    delegate,
+		start:       start,
+		running:     map[string]runningTest{},
+		completed:   map[string]int{},
+		subscribers: map[chan runStatusEvent]struct{}{},
+	}
+}
+
+func (s *runStatusRecorder) setExpectedTests(expectedTests int) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.expectedTests = expectedTests
+}
+
+func (s *runStatusRecorder) Record(conditions ...monitorapi.Condition) {
+	s.RecordAt(time.Now().UTC(), conditions...)
+}
+
+func (s *runStatusRecorder) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
+	s.Recorder.RecordAt(t, conditions...)
+	for _, condition := range conditions {
+		s.observe(monitorapi.Interval{Condition: condition, From: t, To: t})
+	}
+}
+
+func (s *runStatusRecorder) AddIntervals(eventIntervals ...monitorapi.Interval) {
+	s.Recorder.AddIntervals(eventIntervals...)
+	for _, interval := range eventIntervals {
+		s.observe(interval)
+	}
+}
+
+func (s *runStatusRecorder) StartInterval(interval monitorapi.Interval) int {
+	ret := s.Recorder.StartInterval(interval)
+	s.observe(interval)
+	return ret
+}
+
+func (s *runStatusRecorder) EndInterval(startedInterval int, t time.Time) *monitorapi.Interval {
+	ret := s.Recorder.EndInterval(startedInterval, t)
+	if ret != nil {
+		s.observe(*ret)
+	}
+	return ret
+}
+
+func (s *runStatusRecorder) observe(interval monitorapi.Interval) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if interval.Source == monitorapi.SourceE2ETest {
+		name := interval.Locator.Keys[monitorapi.LocatorE2ETestKey]
+		// runs of the same test at the same time, like retries or with --count, are told apart by their run
+		run := name + "\x00" + interval.Message.Annotations[monitorapi.AnnotationTestRun]
+		switch interval.Message.Reason {
+		case monitorapi.E2ETestStarted:
+			s.running[run] = runningTest{Name: name, Start: interval.From}
+		case monitorapi.E2ETestFinished:
+			completion := testCompletion{
+				Name:   name,
+				Status: interval.Message.Annotations[monitorapi.AnnotationStatus],
+			}
+			if started, ok := s.running[run]; ok {
+				completion.DurationSeconds = interval.From.Sub(started.Start).Seconds()
+				delete(s.running, run)
+			}
+			s.completed[completion.Status]++
+			if completion.Status == "Failed" || completion.Status == "Unknown" {
+				s.failures = append(s.failures, name)
+			}
+			if data, err := json.Marshal(completion); err == nil {
+				s.publishLocked(runStatusEvent{name: "test", data: data})
+			}
+		}
+	}
+
+	if len(s.subscribers) == 0 {
+		return
+	}
+	if data, err := monitorserialization.IntervalToOneLineJSON(interval); err == nil {
+		s.publishLocked(runStatusEvent{name: "interval", data: data})
+	}
+}
+
+// publishLocked sends the event to every subscriber.  Slow subscribers miss events rather than
+// holding up the recorder.
+func (s *runStatusRecorder) publishLocked(event runStatusEvent) {
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (s *runStatusRecorder) subscribe() chan runStatusEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	subscriber := make(chan runStatusEvent, 1000)
+	s.subscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+func (s *runStatusRecorder) unsubscribe(subscriber chan runStatusEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.subscribers, subscriber)
+}
+
+func (s *runStatusRecorder) status(now time.Time) runStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status := runStatus{
+		Start:          s.start,
+		ElapsedSeconds: now.Sub(s.start).Seconds(),
+		ExpectedTests:  s.expectedTests,
+		Completed:      map[string]int{},
+		Running:        []runningTest{},
+		Failures:       append([]string{}, s.failures...),
+	}
+	for state, count := range s.completed {
+		status.Completed[state] = count
+		status.CompletedTests += count
+	}
+	for _, running := range s.running {
+		running.RunningSeconds = now.Sub(running.Start).Seconds()
+		status.Running = append(status.Running, running)
+	}
+	sort.Slice(status.Running, func(i, j int) bool {
+		if !status.Running[i].Start.Equal(status.Running[j].Start) {
+			return status.Running[i].Start.Before(status.Running[j].Start)
+		}
+		return status.Running[i].Name < status.Running[j].Name
+	})
+
+	if status.CompletedTests > 0 && status.ExpectedTests > status.CompletedTests {
+		elapsed := now.Sub(s.start)
+		remaining := time.Duration(float64(elapsed) / float64(status.CompletedTests) * float64(status.ExpectedTests-status.CompletedTests))
+		eta := now.Add(remaining)
+		status.ETA = &eta
+	}
+	return status
+}
+
+func (s *runStatusRecorder) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.MarshalIndent(s.status(time.Now()), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		subscriber := s.subscribe()
+		defer s.unsubscribe(subscriber)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-subscriber:
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+				flusher.Flush()
+			}
+		}
+	})
+	return mux
+}
+
+// serveRunStatus serves the live status of the run on listen until the context is done.
+func serveRunStatus(ctx context.Context, listen string, status *runStatusRecorder, errOut io.Writer) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("could not listen on --status-listen: %w", err)
+	}
+	server := &http.Server{
+		Handler:           status.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(errOut, "error: Run status server failed: %v\n", err)
+		}
+	}()
+	fmt.Fprintf(errOut, "Serving run status on http://%s/status and http://%s/events\n", listener.Addr(), listener.Addr())
+	return nil
+}
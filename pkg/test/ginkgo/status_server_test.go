@@ -0,0 +1,144 @@
+package ginkgo
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func testInterval(name string, reason monitorapi.IntervalReason, status string, at time.Time) monitorapi.Interval {
+	msg := monitorapi.NewMessage().HumanMessage(string(reason)).Reason(reason)
+	if len(status) > 0 {
+		msg = msg.WithAnnotation(monitorapi.AnnotationStatus, status)
+	}
+	return monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+		Locator(monitorapi.NewLocator().E2ETest(name)).
+		Message(msg).
+		Build(at, at)
+}
+
+func Test_runStatusRecorder(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	status := newRunStatusRecorder(monitor.NewRecorder(), start)
+	status.setExpectedTests(4)
+
+	server := httptest.NewServer(status.handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	status.AddIntervals(
+		testInterval("passes", monitorapi.E2ETestStarted, "", start),
+		testInterval("fails", monitorapi.E2ETestStarted, "", start.Add(time.Minute)),
+		testInterval("runs", monitorapi.E2ETestStarted, "", start.Add(2*time.Minute)),
+		testInterval("passes", monitorapi.E2ETestFinished, "Passed", start.Add(3*time.Minute)),
+		testInterval("fails", monitorapi.E2ETestFinished, "Failed", start.Add(4*time.Minute)),
+	)
+
+	if got := len(status.Intervals(time.Time{}, time.Time{})); got != 5 {
+		t.Errorf("expected intervals to be recorded by the delegate, got %d", got)
+	}
+
+	now := start.Add(10 * time.Minute)
+	current := status.status(now)
+	if current.CompletedTests != 2 || current.Completed["Passed"] != 1 || current.Completed["Failed"] != 1 {
+		t.Errorf("unexpected completed counts: %#v", current.Completed)
+	}
+	if len(current.Running) != 1 || current.Running[0].Name != "runs" || current.Running[0].RunningSeconds != 480 {
+		t.Errorf("unexpected running tests: %#v", current.Running)
+	}
+	if len(current.Failures) != 1 || current.Failures[0] != "fails" {
+		t.Errorf("unexpected failures: %v", current.Failures)
+	}
+	// two tests took ten minutes, so the remaining two are expected to take another ten
+	if current.ETA == nil || !current.ETA.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("unexpected ETA: %v", current.ETA)
+	}
+
+	statusResp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer statusResp.Body.Close()
+	served := runStatus{}
+	if err := json.NewDecoder(statusResp.Body).Decode(&served); err != nil {
+		t.Fatal(err)
+	}
+	if served.CompletedTests != 2 || served.ExpectedTests != 4 {
+		t.Errorf("unexpected served status: %#v", served)
+	}
+
+	// the first events are the intervals of the starting tests, then the first completion
+	reader := bufio.NewReader(resp.Body)
+	var events []string
+	var passed testCompletion
+	for len(events) < 5 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(line, "event: ") {
+			events = append(events, strings.TrimSpace(strings.TrimPrefix(line, "event: ")))
+		}
+		if strings.HasPrefix(line, "data: {\"name\":\"passes\"") {
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &passed); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if strings.Join(events, ",") != "interval,interval,interval,test,interval" {
+		t.Errorf("unexpected events: %v", events)
+	}
+	if passed.Status != "Passed" || passed.DurationSeconds != 180 {
+		t.Errorf("unexpected completion: %#v", passed)
+	}
+}
+
+func Test_runStatusRecorderConcurrentRuns(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	status := newRunStatusRecorder(monitor.NewRecorder(), start)
+	run := func(interval monitorapi.Interval, run string) monitorapi.Interval {
+		interval.Message.Annotations[monitorapi.AnnotationTestRun] = run
+		return interval
+	}
+
+	// the same test runs twice at the same time, e.g. with --count, and the first run finishes first
+	status.AddIntervals(
+		run(testInterval("repeated", monitorapi.E2ETestStarted, "", start), "1"),
+		run(testInterval("repeated", monitorapi.E2ETestStarted, "", start.Add(time.Minute)), "2"),
+	)
+	subscriber := status.subscribe()
+	defer status.unsubscribe(subscriber)
+	status.AddIntervals(run(testInterval("repeated", monitorapi.E2ETestFinished, "Passed", start.Add(3*time.Minute)), "1"))
+
+	current := status.status(start.Add(10 * time.Minute))
+	if len(current.Running) != 1 || current.Running[0].Name != "repeated" || current.Running[0].RunningSeconds != 540 {
+		t.Errorf("expected the second run to still be running, got %#v", current.Running)
+	}
+	completion := testCompletion{}
+	if err := json.Unmarshal((<-subscriber).data, &completion); err != nil {
+		t.Fatal(err)
+	}
+	if completion.DurationSeconds != 180 {
+		t.Errorf("expected the duration of the first run, got %#v", completion)
+	}
+
+	status.AddIntervals(run(testInterval("repeated", monitorapi.E2ETestFinished, "Passed", start.Add(4*time.Minute)), "2"))
+	if current := status.status(start.Add(10 * time.Minute)); len(current.Running) != 0 || current.Completed["Passed"] != 2 {
+		t.Errorf("expected both runs to be completed, got %#v", current)
+	}
+}
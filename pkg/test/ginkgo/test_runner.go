@@ -8,8 +8,10 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -22,6 +24,9 @@ type testSuiteRunner interface {
 	RunOneTest(ctx context.Context, test *testCase)
 }
 
+// lastTestRun is the number of the last test run started, which identifies the intervals of the run.
+var lastTestRun atomic.Int64
+
 // testRunner contains all the content required to run a test.  It must be threadsafe and must be re-useable
 // across multiple parallel RunOneTest invocations.
 type testSuiteRunnerImpl struct {
@@ -44,11 +49,13 @@ func (r *testSuiteRunnerImpl) RunOneTest(ctx context.Context, test *testCase) {
 	defer r.maybeAbortOnFailureFn(testRunResult)
 
 	// record the test happening with the monitor
+	run := strconv.FormatInt(lastTestRun.Add(1), 10)
 	r.testOutput.monitorRecorder.AddIntervals(monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
 		Locator(monitorapi.NewLocator().E2ETest(test.name)).
-		Message(monitorapi.NewMessage().HumanMessage("started").Reason(monitorapi.E2ETestStarted)).BuildNow())
+		Message(monitorapi.NewMessage().HumanMessage("started").Reason(monitorapi.E2ETestStarted).
+			WithAnnotation(monitorapi.AnnotationTestRun, run)).BuildNow())
 
-	defer recordTestResultInMonitor(testRunResult, r.testOutput.monitorRecorder)
+	defer recordTestResultInMonitor(testRunResult, run, r.testOutput.monitorRecorder)
 
 	// log the results to systemout
 	r.testSuiteProgress.LogTestStart(r.testOutput.out, test.name)
@@ -57,6 +64,11 @@ func (r *testSuiteRunnerImpl) RunOneTest(ctx context.Context, test *testCase) {
 
 	testRunResult.testRunResult = r.commandContext.RunTestInNewProcess(ctx, test)
 	mutateTestCaseWithResults(test, testRunResult)
+
+	if err := r.testOutput.ledger.Record(test); err != nil {
+		fmt.Fprintf(os.Stderr, "error: Unable to record %q in the run ledger: %v\n", test.name, err)
+	}
+	r.testOutput.leaks.TestFinished(test)
 }
 
 func mutateTestCaseWithResults(test *testCase, testRunResult *testRunResultHandle) {
@@ -125,6 +137,8 @@ type testOutputConfig struct {
 	testOutputLock  *sync.Mutex
 	out             io.Writer
 	monitorRecorder monitorapi.Recorder
+	ledger          *runLedger
+	leaks           *leakDetector
 
 	includeSuccessfulOutput bool
 }
@@ -173,11 +187,13 @@ func isTestFailed(testState TestState) bool {
 }
 
 // testOutputLock prevents parallel tests from interleaving their output.
-func newTestOutputConfig(testOutputLock *sync.Mutex, out io.Writer, monitorRecorder monitorapi.Recorder, includeSuccessfulOutput bool) testOutputConfig {
+func newTestOutputConfig(testOutputLock *sync.Mutex, out io.Writer, monitorRecorder monitorapi.Recorder, ledger *runLedger, leaks *leakDetector, includeSuccessfulOutput bool) testOutputConfig {
 	return testOutputConfig{
 		testOutputLock:          testOutputLock,
 		out:                     out,
 		monitorRecorder:         monitorRecorder,
+		ledger:                  ledger,
+		leaks:                   leaks,
 		includeSuccessfulOutput: includeSuccessfulOutput,
 	}
 }
@@ -257,10 +273,10 @@ func recordTestResultInLog(testRunResult *testRunResultHandle, out io.Writer, in
 	}
 }
 
-func recordTestResultInMonitor(testRunResult *testRunResultHandle, monitorRecorder monitorapi.Recorder) {
+func recordTestResultInMonitor(testRunResult *testRunResultHandle, run string, monitorRecorder monitorapi.Recorder) {
 	eventLevel := monitorapi.Warning
 
-	msg := monitorapi.NewMessage().HumanMessage("e2e test finished")
+	msg := monitorapi.NewMessage().HumanMessage("e2e test finished").WithAnnotation(monitorapi.AnnotationTestRun, run)
 
 	switch testRunResult.testState {
 	case TestFlaked:
@@ -304,52 +320,64 @@ func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase
 		return ret
 	}
 
-	ret.start = time.Now()
-	testBinary, testName := c.extractCommands(test)
-	command := exec.Command(testBinary, "run-test", testName)
-	command.Env = append(os.Environ(), updateEnvVars(c.env)...)
-
 	timeout := c.timeout
 	if test.testTimeout != 0 {
 		timeout = test.testTimeout
 	}
 
+	ret.start = time.Now()
+	testBinary, testName := c.extractCommands(test)
+	args := []string{"run-test", testName}
+	if test.binaryAPIVersion == externalBinaryAPIVersionV1 {
+		args = []string{"run-test", "--output=json", testName}
+	}
+	command := exec.Command(testBinary, args...)
+	command.Env = append(os.Environ(), updateEnvVars(c.env)...)
+
 	testOutputBytes, err := runWithTimeout(ctx, command, timeout)
 	ret.end = time.Now()
 
 	ret.testOutputBytes = testOutputBytes
+	ret.testState = testStateFromExit(ctx, err)
+	if test.binaryAPIVersion == externalBinaryAPIVersionV1 && ctx.Err() == nil {
+		if state, output, ok := externalTestState(testOutputBytes); ok {
+			ret.testState = state
+			ret.testOutputBytes = output
+		}
+	}
+	return ret
+}
+
+// testStateFromExit maps the way a run-test process exited to the state of its test.
+func testStateFromExit(ctx context.Context, err error) TestState {
 	if err == nil {
-		ret.testState = TestSucceeded
-		return ret
+		return TestSucceeded
 	}
 
 	if ctx.Err() != nil {
-		ret.testState = TestSkipped
-		return ret
+		return TestSkipped
 	}
 
 	if exitErr, ok := err.(*exec.ExitError); ok {
 		switch exitErr.ProcessState.Sys().(syscall.WaitStatus).ExitStatus() {
 		case 1:
 			// failed
-			ret.testState = TestFailed
+			return TestFailed
 		case 2:
 			// timeout (ABRT is an exit code 2)
-			ret.testState = TestFailedTimeout
+			return TestFailedTimeout
 		case 3:
 			// skipped
-			ret.testState = TestSkipped
+			return TestSkipped
 		case 4:
 			// flaky, do not retry
-			ret.testState = TestFlaked
+			return TestFlaked
 		default:
-			ret.testState = TestUnknown
+			return TestUnknown
 		}
-		return ret
 	}
 
-	ret.testState = TestFailed
-	return ret
+	return TestFailed
 }
 
 func updateEnvVars(envs []string) []string {
@@ -362,10 +390,12 @@ func updateEnvVars(envs []string) []string {
 	// copied from provider.go
 	// TODO: add error handling, and maybe turn this into sharable helper?
 	config := &clusterdiscovery.ClusterConfiguration{}
-	clientConfig, _ := framework.LoadConfig(true)
-	clusterState, _ := clusterdiscovery.DiscoverClusterState(clientConfig)
-	if clusterState != nil {
-		config, _ = clusterdiscovery.LoadConfig(clusterState)
+	// without a kubeconfig the tests run with the skeleton provider
+	if clientConfig, err := framework.LoadConfig(true); err == nil && clientConfig != nil {
+		clusterState, _ := clusterdiscovery.DiscoverClusterState(clientConfig)
+		if clusterState != nil {
+			config, _ = clusterdiscovery.LoadConfig(clusterState)
+		}
 	}
 	if len(config.ProviderName) == 0 {
 		config.ProviderName = "skeleton"
@@ -1,6 +1,7 @@
 package ginkgo
 
 import (
+	"fmt"
 	"regexp"
 	"time"
 
@@ -75,8 +76,10 @@ type testCase struct {
 	rawName string
 	// binaryName is the name of the external binary
 	binaryName string
-	spec       types.TestSpec
-	locations  []types.CodeLocation
+	// binaryAPIVersion is the version of the external binary contract, empty for legacy binaries
+	binaryAPIVersion string
+	spec             types.TestSpec
+	locations        []types.CodeLocation
 
 	// identifies which tests can be run in parallel (ginkgo runs suites linearly)
 	testExclusion string
@@ -95,23 +98,36 @@ type testCase struct {
 	success  bool
 	timedOut bool
 
+	// quarantine is set when the test is known to be flaky, in which case a failure is reported as a flake
+	quarantine *QuarantinedTest
+
 	previous *testCase
 }
 
 func (t *testCase) Retry() *testCase {
 	copied := &testCase{
-		name:          t.name,
-		spec:          t.spec,
-		rawName:       t.rawName,
-		binaryName:    t.binaryName,
-		locations:     t.locations,
-		testExclusion: t.testExclusion,
+		name:             t.name,
+		spec:             t.spec,
+		rawName:          t.rawName,
+		binaryName:       t.binaryName,
+		binaryAPIVersion: t.binaryAPIVersion,
+		locations:        t.locations,
+		testExclusion:    t.testExclusion,
 
 		previous: t,
 	}
 	return copied
 }
 
+// attempt returns how many times this test has been run, including this run.
+func (t *testCase) attempt() int {
+	attempt := 1
+	for previous := t.previous; previous != nil; previous = previous.previous {
+		attempt++
+	}
+	return attempt
+}
+
 type ClusterStabilityDuringTest string
 
 var (
@@ -129,6 +145,9 @@ type TestSuite struct {
 	Description string
 
 	Matches TestMatchFunc
+	// namedMatches are the filters added with AddNamedRequiredMatchFunc, by name, to tell which of
+	// them excluded a test.
+	namedMatches []namedTestMatchFunc
 
 	// The number of times to execute each test in this suite.
 	Count int
@@ -136,6 +155,12 @@ type TestSuite struct {
 	Parallelism int
 	// The number of flakes that may occur before this test is marked as a failure.
 	MaximumAllowedFlakes int
+	// Quarantined lists tests that are known to be flaky. They still run, but their failures are
+	// reported as flakes.
+	Quarantined []QuarantinedTest
+	// RetryPolicy controls how failures are retried. When nil, failures are retried once and only
+	// if there are no more than MaximumAllowedFlakes of them.
+	RetryPolicy *RetryPolicy
 
 	ClusterStabilityDuringTest ClusterStabilityDuringTest
 
@@ -144,6 +169,51 @@ type TestSuite struct {
 
 type TestMatchFunc func(name string) bool
 
+type namedTestMatchFunc struct {
+	name    string
+	matches TestMatchFunc
+}
+
+// QuarantinedTest marks the tests matching Pattern as known to be flaky until Expires.
+type QuarantinedTest struct {
+	Pattern   *regexp.Regexp
+	Component string
+	Bug       string
+	Expires   time.Time
+}
+
+// String describes the quarantine for the test report.
+func (q *QuarantinedTest) String() string {
+	return fmt.Sprintf("component %s, bug %s, expires %s", q.Component, q.Bug, q.Expires.Format("2006-01-02"))
+}
+
+// quarantineFor returns the first quarantine matching the named test, or nil.
+func (s *TestSuite) quarantineFor(name string) *QuarantinedTest {
+	for i := range s.Quarantined {
+		if s.Quarantined[i].Pattern.MatchString(name) {
+			return &s.Quarantined[i]
+		}
+	}
+	return nil
+}
+
+// applyQuarantine reports failures of quarantined tests as flakes and returns the tests whose
+// failures were forgiven.
+func (s *TestSuite) applyQuarantine(tests []*testCase) []*testCase {
+	var forgiven []*testCase
+	for _, test := range tests {
+		test.quarantine = s.quarantineFor(test.name)
+		if test.quarantine == nil || !test.failed {
+			continue
+		}
+		test.failed = false
+		test.timedOut = false
+		test.flake = true
+		forgiven = append(forgiven, test)
+	}
+	return forgiven
+}
+
 func (s *TestSuite) Filter(tests []*testCase) []*testCase {
 	matches := make([]*testCase, 0, len(tests))
 	for _, test := range tests {
@@ -170,6 +240,34 @@ func (s *TestSuite) AddRequiredMatchFunc(matchFn TestMatchFunc) {
 	}
 }
 
+// AddNamedRequiredMatchFunc adds a required filter like AddRequiredMatchFunc, which ExcludedBy
+// reports by name.
+func (s *TestSuite) AddNamedRequiredMatchFunc(name string, matchFn TestMatchFunc) {
+	if matchFn == nil {
+		return
+	}
+	s.AddRequiredMatchFunc(matchFn)
+	s.namedMatches = append(s.namedMatches, namedTestMatchFunc{name: name, matches: matchFn})
+}
+
+// ExcludedBy returns the names of the filters that exclude the named test.  A test excluded by the
+// definition of the suite, or by a filter without a name, is excluded by "suite".
+func (s *TestSuite) ExcludedBy(name string) []string {
+	if s.Matches == nil || s.Matches(name) {
+		return nil
+	}
+	var excludedBy []string
+	for _, filter := range s.namedMatches {
+		if !filter.matches(name) {
+			excludedBy = append(excludedBy, filter.name)
+		}
+	}
+	if len(excludedBy) == 0 {
+		excludedBy = append(excludedBy, "suite")
+	}
+	return excludedBy
+}
+
 func testNames(tests []*testCase) []string {
 	var names []string
 	for _, t := range tests {
@@ -0,0 +1,104 @@
+package testsuites
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/test/ginkgo"
+)
+
+// QuarantineFile lists tests that are known to be flaky. Quarantined tests still run, but their
+// failures are reported as flakes. Unlike [SkippedUntil:...], this does not require editing the
+// test source, so it works for vendored tests as well.
+//
+//	quarantine:
+//	- name: '\[sig-network\] Services should be rejected when no endpoints exist'
+//	  component: Networking / ovn-kubernetes
+//	  bug: https://issues.redhat.com/browse/OCPBUGS-12345
+//	  expires: 2024-12-31
+type QuarantineFile struct {
+	Quarantine []QuarantineEntry `json:"quarantine"`
+}
+
+// QuarantineEntry quarantines every test whose name matches the Name regular expression.
+type QuarantineEntry struct {
+	Name      string `json:"name"`
+	Component string `json:"component"`
+	Bug       string `json:"bug"`
+	// Expires is a date in the form YYYY-MM-DD. The quarantine covers the whole day.
+	Expires string `json:"expires"`
+}
+
+// LoadQuarantineFile reads a quarantine file in YAML or JSON. Every entry must name an owning
+// component, a bug, and an expiry date. Expired entries are an error, so that a quarantine cannot
+// silently outlive the bug it was added for.
+func LoadQuarantineFile(path string, now time.Time) ([]ginkgo.QuarantinedTest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &QuarantineFile{}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("unable to parse quarantine file %s: %w", path, err)
+	}
+
+	var quarantined []ginkgo.QuarantinedTest
+	var errs []error
+	for i, entry := range file.Quarantine {
+		test, err := entry.toQuarantinedTest()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("quarantine[%d]: %w", i, err))
+			continue
+		}
+		// the quarantine covers the whole day it expires on
+		if !now.Before(test.Expires.AddDate(0, 0, 1)) {
+			errs = append(errs, fmt.Errorf("quarantine[%d] for %q expired on %s, fix %s or extend the quarantine", i, entry.Name, entry.Expires, entry.Bug))
+			continue
+		}
+		quarantined = append(quarantined, test)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid quarantine file %s: %w", path, errors.NewAggregate(errs))
+	}
+	return quarantined, nil
+}
+
+func (e QuarantineEntry) toQuarantinedTest() (ginkgo.QuarantinedTest, error) {
+	var missing []string
+	if len(e.Name) == 0 {
+		missing = append(missing, "name")
+	}
+	if len(e.Component) == 0 {
+		missing = append(missing, "component")
+	}
+	if len(e.Bug) == 0 {
+		missing = append(missing, "bug")
+	}
+	if len(e.Expires) == 0 {
+		missing = append(missing, "expires")
+	}
+	if len(missing) > 0 {
+		return ginkgo.QuarantinedTest{}, fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	pattern, err := regexp.Compile(e.Name)
+	if err != nil {
+		return ginkgo.QuarantinedTest{}, fmt.Errorf("invalid name: %w", err)
+	}
+	expires, err := time.Parse("2006-01-02", e.Expires)
+	if err != nil {
+		return ginkgo.QuarantinedTest{}, fmt.Errorf("invalid expires, expected YYYY-MM-DD: %w", err)
+	}
+	return ginkgo.QuarantinedTest{
+		Pattern:   pattern,
+		Component: e.Component,
+		Bug:       e.Bug,
+		Expires:   expires,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package testsuites
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadQuarantineFile(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		content     string
+		expectErr   string
+		expectNames []string
+	}{
+		{
+			name: "yaml",
+			content: `quarantine:
+- name: '\[sig-network\] Services should .*'
+  component: Networking
+  bug: https://issues.redhat.com/browse/OCPBUGS-1
+  expires: 2024-07-01
+- name: 'expires today'
+  component: Storage
+  bug: https://issues.redhat.com/browse/OCPBUGS-2
+  expires: "2024-06-15"
+`,
+			expectNames: []string{`\[sig-network\] Services should .*`, "expires today"},
+		},
+		{
+			name:        "json",
+			content:     `{"quarantine": [{"name": "foo", "component": "Etcd", "bug": "https://bugs/3", "expires": "2024-07-01"}]}`,
+			expectNames: []string{"foo"},
+		},
+		{
+			name: "expired",
+			content: `quarantine:
+- name: 'old'
+  component: Networking
+  bug: https://issues.redhat.com/browse/OCPBUGS-4
+  expires: 2024-06-14
+`,
+			expectErr: `quarantine[0] for "old" expired on 2024-06-14`,
+		},
+		{
+			name: "missing fields",
+			content: `quarantine:
+- name: 'foo'
+  expires: 2024-07-01
+`,
+			expectErr: "quarantine[0]: missing component, bug",
+		},
+		{
+			name: "invalid regex",
+			content: `quarantine:
+- name: '[sig-network'
+  component: Networking
+  bug: https://issues.redhat.com/browse/OCPBUGS-5
+  expires: 2024-07-01
+`,
+			expectErr: "quarantine[0]: invalid name",
+		},
+		{
+			name:      "unknown field",
+			content:   `{"quarantine": [{"name": "foo", "owner": "me"}]}`,
+			expectErr: "unable to parse quarantine file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "quarantine")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			quarantined, err := LoadQuarantineFile(path, now)
+			if len(tt.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, q := range quarantined {
+				names = append(names, q.Pattern.String())
+			}
+			if strings.Join(names, "\n") != strings.Join(tt.expectNames, "\n") {
+				t.Errorf("expected %v, got %v", tt.expectNames, names)
+			}
+		})
+	}
+}
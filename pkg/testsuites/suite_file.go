@@ -0,0 +1,171 @@
+package testsuites
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/test/ginkgo"
+)
+
+// SuiteFile declares test suites without changing the binary. A test belongs to a suite if it
+// matches any of its include queries, or if there are none, none of its exclude queries, and its
+// query if it has one. Disabled tests are never included, like in the standard suites.
+//
+//	suites:
+//	- name: example/networking
+//	  description: The parallel conformance tests of the network components.
+//	  include:
+//	  - 'Suite:openshift/conformance/parallel* && sig-network'
+//	  - 'Suite:openshift/conformance/parallel* && sig-network-edge'
+//	  exclude:
+//	  - 'Feature:IPv6DualStack'
+//	  query: '!Slow && !apigroup:operator.openshift.io'
+//	  parallelism: 10
+//	  testTimeout: 20m
+//	  maximumAllowedFlakes: 2
+//	  clusterStabilityDuringTest: Stable
+type SuiteFile struct {
+	Suites []SuiteDefinition `json:"suites"`
+}
+
+// SuiteDefinition is a test suite of a SuiteFile. Include, exclude and query are ginkgo.LabelQuery
+// expressions, e.g. 'sig-network && Serial' or 'Suite:openshift/conformance/*'.
+type SuiteDefinition struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Include     []string `json:"include,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+	// Query is a label query the tests must satisfy as well.
+	Query string `json:"query,omitempty"`
+	// Parallelism is the maximum number of tests running in parallel, 0 uses the default.
+	Parallelism int `json:"parallelism,omitempty"`
+	// TestTimeout is the duration, e.g. 30m, a test may run unless it sets its own [Timeout:].
+	TestTimeout                string `json:"testTimeout,omitempty"`
+	MaximumAllowedFlakes       int    `json:"maximumAllowedFlakes,omitempty"`
+	ClusterStabilityDuringTest string `json:"clusterStabilityDuringTest,omitempty"`
+}
+
+// LoadSuiteFile reads the suites of a suite file in YAML or JSON. Every suite must have a name that
+// no other suite, in the file or in existing, has, and valid queries and settings.
+func LoadSuiteFile(path string, existing []*ginkgo.TestSuite) ([]*ginkgo.TestSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &SuiteFile{}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("unable to parse suite file %s: %w", path, err)
+	}
+	if len(file.Suites) == 0 {
+		return nil, fmt.Errorf("suite file %s declares no suites", path)
+	}
+
+	names := map[string]bool{}
+	for _, suite := range existing {
+		names[suite.Name] = true
+	}
+	var suites []*ginkgo.TestSuite
+	var errs []error
+	for i, definition := range file.Suites {
+		suite, err := definition.toTestSuite()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("suites[%d]: %w", i, err))
+			continue
+		}
+		if names[suite.Name] {
+			errs = append(errs, fmt.Errorf("suites[%d]: suite %q already exists", i, suite.Name))
+			continue
+		}
+		names[suite.Name] = true
+		suites = append(suites, suite)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid suite file %s: %w", path, errors.NewAggregate(errs))
+	}
+	return suites, nil
+}
+
+func (d SuiteDefinition) toTestSuite() (*ginkgo.TestSuite, error) {
+	if len(d.Name) == 0 {
+		return nil, fmt.Errorf("missing name")
+	}
+	if d.Parallelism < 0 {
+		return nil, fmt.Errorf("parallelism must not be negative")
+	}
+	if d.MaximumAllowedFlakes < 0 {
+		return nil, fmt.Errorf("maximumAllowedFlakes must not be negative")
+	}
+	switch ginkgo.ClusterStabilityDuringTest(d.ClusterStabilityDuringTest) {
+	case "", ginkgo.Stable, ginkgo.Disruptive:
+	default:
+		return nil, fmt.Errorf("unknown clusterStabilityDuringTest %q, expected Stable or Disruptive", d.ClusterStabilityDuringTest)
+	}
+	var testTimeout time.Duration
+	if len(d.TestTimeout) > 0 {
+		var err error
+		if testTimeout, err = time.ParseDuration(d.TestTimeout); err != nil || testTimeout <= 0 {
+			return nil, fmt.Errorf("invalid testTimeout %q, expected a positive duration like 30m", d.TestTimeout)
+		}
+	}
+	include, err := parseLabelQueries(d.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include: %w", err)
+	}
+	exclude, err := parseLabelQueries(d.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude: %w", err)
+	}
+	var query *ginkgo.LabelQuery
+	if len(d.Query) > 0 {
+		if query, err = ginkgo.ParseLabelQuery(d.Query); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ginkgo.TestSuite{
+		Name:        d.Name,
+		Description: strings.TrimSpace(d.Description),
+		Matches: func(name string) bool {
+			if isDisabled(name) {
+				return false
+			}
+			if len(include) > 0 && !matchesAnyLabelQuery(include, name) {
+				return false
+			}
+			if query != nil && !query.Matches(name) {
+				return false
+			}
+			return !matchesAnyLabelQuery(exclude, name)
+		},
+		Parallelism:                d.Parallelism,
+		MaximumAllowedFlakes:       d.MaximumAllowedFlakes,
+		ClusterStabilityDuringTest: ginkgo.ClusterStabilityDuringTest(d.ClusterStabilityDuringTest),
+		TestTimeout:                testTimeout,
+	}, nil
+}
+
+func parseLabelQueries(queries []string) ([]*ginkgo.LabelQuery, error) {
+	var parsed []*ginkgo.LabelQuery
+	for _, query := range queries {
+		q, err := ginkgo.ParseLabelQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, q)
+	}
+	return parsed, nil
+}
+
+func matchesAnyLabelQuery(queries []*ginkgo.LabelQuery, name string) bool {
+	for _, query := range queries {
+		if query.Matches(name) {
+			return true
+		}
+	}
+	return false
+}
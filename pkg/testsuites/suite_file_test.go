@@ -0,0 +1,125 @@
+package testsuites
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo"
+)
+
+func TestLoadSuiteFile(t *testing.T) {
+	existing := []*ginkgo.TestSuite{{Name: "openshift/conformance/parallel"}}
+
+	tests := []struct {
+		name      string
+		content   string
+		expectErr string
+		validate  func(t *testing.T, suites []*ginkgo.TestSuite)
+	}{
+		{
+			name: "yaml",
+			content: `suites:
+- name: example/networking
+  description: |
+    The network tests.
+  include:
+  - 'Suite:openshift/conformance/* && sig-network'
+  - sig-network-edge
+  exclude:
+  - Serial
+  query: '!Slow'
+  parallelism: 10
+  testTimeout: 20m
+  maximumAllowedFlakes: 2
+  clusterStabilityDuringTest: Disruptive
+`,
+			validate: func(t *testing.T, suites []*ginkgo.TestSuite) {
+				if len(suites) != 1 {
+					t.Fatalf("expected one suite, got %d", len(suites))
+				}
+				suite := suites[0]
+				if suite.Name != "example/networking" || suite.Description != "The network tests." || suite.Parallelism != 10 ||
+					suite.TestTimeout != 20*time.Minute || suite.MaximumAllowedFlakes != 2 || suite.ClusterStabilityDuringTest != ginkgo.Disruptive {
+					t.Errorf("unexpected suite %#v", suite)
+				}
+				for name, expected := range map[string]bool{
+					"[sig-network] pods communicate [Suite:openshift/conformance/parallel]":          true,
+					"[sig-network] pods communicate [Suite:openshift/conformance/parallel/minimal]":  true,
+					"[sig-network-edge] routes work":                                                 true,
+					"[sig-network-edge] routes work [Slow]":                                          false,
+					"[sig-network] pods communicate":                                                 false,
+					"[sig-network] pods communicate [Serial] [Suite:openshift/conformance/serial]":   false,
+					"[sig-network] pods communicate [Disabled:Broken] [Suite:openshift/conformance]": false,
+					"[sig-storage] volumes mount [Suite:openshift/conformance/parallel]":             false,
+				} {
+					if suite.Matches(name) != expected {
+						t.Errorf("expected %q to match %t", name, expected)
+					}
+				}
+			},
+		},
+		{
+			name:    "json without include",
+			content: `{"suites": [{"name": "example/all", "exclude": ["Slow"]}, {"name": "example/none", "include": ["Feature:Nothing"]}]}`,
+			validate: func(t *testing.T, suites []*ginkgo.TestSuite) {
+				if len(suites) != 2 || !suites[0].Matches("[sig-cli] oc works") || suites[0].Matches("[sig-cli] oc works [Slow]") || suites[1].Matches("[sig-cli] oc works") {
+					t.Errorf("unexpected suites %v", suites)
+				}
+			},
+		},
+		{
+			name:      "empty",
+			content:   `suites: []`,
+			expectErr: "declares no suites",
+		},
+		{
+			name:      "unknown field",
+			content:   "suites:\n- name: example/all\n  parallel: 3\n",
+			expectErr: "unable to parse suite file",
+		},
+		{
+			name: "invalid settings",
+			content: `suites:
+- description: no name
+- name: example/a
+  testTimeout: soon
+- name: example/b
+  clusterStabilityDuringTest: Upgrade
+- name: example/c
+  include: ['sig-network ||']
+- name: example/d
+  parallelism: -1
+- name: example/e
+  query: 'sig-network &&'
+`,
+			expectErr: "suites[0]: missing name",
+		},
+		{
+			name:      "duplicate names",
+			content:   "suites:\n- name: openshift/conformance/parallel\n- name: example/a\n- name: example/a\n",
+			expectErr: `suites[0]: suite "openshift/conformance/parallel" already exists`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suites.yaml")
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			suites, err := LoadSuiteFile(path, existing)
+			if len(test.expectErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.validate(t, suites)
+		})
+	}
+}
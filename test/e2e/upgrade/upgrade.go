@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
@@ -71,12 +72,27 @@ func AllTests() []upgrades.Test {
 }
 
 var (
-	upgradeToImage             string
-	upgradeTests               = []upgrades.Test{}
-	upgradeAbortAt             int
-	upgradeDisruptRebootPolicy string
+	upgradeToImage string
+	upgradeTests   = []upgrades.Test{}
+	// upgradeOptions are the options of every hop of the upgrade that upgradeHopOptions does not override.
+	upgradeOptions hopOptions
+	// upgradeHopOptions are the options of the hops of a multi-hop upgrade, by hop starting at 1.
+	upgradeHopOptions = map[int]*hopOptions{}
 )
 
+// hopOptions control how the cluster is upgraded to one of the images of SetToImage.
+type hopOptions struct {
+	// abortAt is the percentage of operators updated at which the upgrade is rolled back, 0 to not abort.
+	abortAt             int
+	disruptRebootPolicy string
+	// channel, if set, is the channel set on the cluster version along with the desired update.
+	channel string
+	// pauseWorkerPools pauses the machine config pools other than master, so that only the control plane
+	// is updated, as in EUS to EUS upgrades. A later hop that does not pause them unpauses them once the
+	// control plane reached its version.
+	pauseWorkerPools bool
+}
+
 // upgradeAbortAtRandom is a special value indicating the abort should happen at a random percentage
 // between (0,100].
 const upgradeAbortAtRandom = -1
@@ -95,14 +111,7 @@ func SetToImage(image string) {
 }
 
 func SetUpgradeDisruptReboot(policy string) error {
-	switch policy {
-	case "graceful", "force":
-		upgradeDisruptRebootPolicy = policy
-		return nil
-	default:
-		upgradeDisruptRebootPolicy = ""
-		return fmt.Errorf("disrupt-reboot must be empty, 'graceful', or 'force'")
-	}
+	return upgradeOptions.setDisruptReboot(policy)
 }
 
 // SetUpgradeAbortAt defines abort behavior during an upgrade. Allowed values are:
@@ -110,11 +119,67 @@ func SetUpgradeDisruptReboot(policy string) error {
 // * empty string - do not abort
 // * integer between 0-100 - once this percentage of operators have updated, rollback to the previous version
 func SetUpgradeAbortAt(policy string) error {
+	return upgradeOptions.setAbortAt(policy)
+}
+
+// SetUpgradeOption sets one of the abort-at, disrupt-reboot, channel or pause-worker-pools options of the
+// upgrade. hop is the hop of a multi-hop upgrade the option applies to, starting at 1 for the first image
+// of SetToImage, or 0 for every hop. The options of every hop must be set before those of single hops.
+func SetUpgradeOption(hop int, key, value string) error {
+	opts := &upgradeOptions
+	if hop > 0 {
+		if _, ok := upgradeHopOptions[hop]; !ok {
+			hopOpts := upgradeOptions
+			upgradeHopOptions[hop] = &hopOpts
+		}
+		opts = upgradeHopOptions[hop]
+	}
+	switch key {
+	case "abort-at":
+		return opts.setAbortAt(value)
+	case "disrupt-reboot":
+		return opts.setDisruptReboot(value)
+	case "channel":
+		opts.channel = value
+		return nil
+	case "pause-worker-pools":
+		paused, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("pause-worker-pools must be 'true' or 'false'")
+		}
+		opts.pauseWorkerPools = paused
+		return nil
+	default:
+		return fmt.Errorf("unrecognized upgrade option: %s", key)
+	}
+}
+
+// optionsForHop returns the options of a hop, starting at 1.
+func optionsForHop(hop int) hopOptions {
+	if opts, ok := upgradeHopOptions[hop]; ok {
+		return *opts
+	}
+	return upgradeOptions
+}
+
+func (o *hopOptions) setDisruptReboot(policy string) error {
+	switch policy {
+	case "", "graceful", "force":
+		o.disruptRebootPolicy = policy
+		return nil
+	default:
+		o.disruptRebootPolicy = ""
+		return fmt.Errorf("disrupt-reboot must be empty, 'graceful', or 'force'")
+	}
+}
+
+func (o *hopOptions) setAbortAt(policy string) error {
 	if len(policy) == 0 {
-		upgradeAbortAt = 0
+		o.abortAt = 0
+		return nil
 	}
 	if policy == "random" {
-		upgradeAbortAt = upgradeAbortAtRandom
+		o.abortAt = upgradeAbortAtRandom
 		return nil
 	}
 	if val, err := strconv.Atoi(policy); err == nil {
@@ -122,15 +187,40 @@ func SetUpgradeAbortAt(policy string) error {
 			return fmt.Errorf("abort-at must be empty, set to 'random', or an integer in [0,100], inclusive")
 		}
 		if val == 0 {
-			upgradeAbortAt = 1
+			o.abortAt = 1
 		} else {
-			upgradeAbortAt = val
+			o.abortAt = val
 		}
 		return nil
 	}
 	return fmt.Errorf("abort-at must be empty, set to 'random', or an integer in [0,100], inclusive")
 }
 
+// upgradeHop is one of the upgrades of a multi-hop upgrade.
+type upgradeHop struct {
+	hopOptions
+	// number is the hop, starting at 1, of count hops.
+	number, count int
+}
+
+// testName tags the name of a test recorded during a multi-hop upgrade with its hop, so that every
+// hop reports its own result.
+func (h upgradeHop) testName(name string) string {
+	if h.count < 2 {
+		return name
+	}
+	return fmt.Sprintf("%s [Hop:%d]", name, h.number)
+}
+
+// note prefixes the note of a cluster event recorded during a multi-hop upgrade with hop/NUMBER, so
+// that the monitor can attribute the intervals between the events to the hop.
+func (h upgradeHop) note(note string) string {
+	if h.count < 2 {
+		return note
+	}
+	return fmt.Sprintf("hop/%d %s", h.number, note)
+}
+
 var _ = g.Describe("[sig-arch][Feature:ClusterUpgrade]", func() {
 	f := framework.NewDefaultFramework("cluster-upgrade")
 	f.SkipNamespaceCreation = true
@@ -184,8 +274,9 @@ var _ = g.Describe("[sig-arch][Feature:ClusterUpgrade]", func() {
 			upgradeTests,
 			func() {
 				for i := 1; i < len(upgCtx.Versions); i++ {
+					hop := upgradeHop{hopOptions: optionsForHop(i), number: i, count: len(upgCtx.Versions) - 1}
 					framework.ExpectNoError(
-						clusterUpgrade(f, client, dynamicClient, config, upgCtx.Versions[i]),
+						clusterUpgrade(f, client, dynamicClient, config, upgCtx.Versions[i], hop),
 						fmt.Sprintf("during upgrade to %s", upgCtx.Versions[i].NodeImage))
 				}
 			},
@@ -314,14 +405,14 @@ func getUpgradeContext(c configv1client.Interface, upgradeImage string) (*upgrad
 
 var errControlledAbort = fmt.Errorf("beginning abort")
 
-func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynamic.Interface, config *rest.Config, version upgrades.VersionContext) error {
+func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynamic.Interface, config *rest.Config, version upgrades.VersionContext, hop upgradeHop) error {
 	fmt.Fprintf(os.Stderr, "\n\n\n")
 	defer func() { fmt.Fprintf(os.Stderr, "\n\n\n") }()
 
 	// ignore the failure here, we don't want this to fail the upgrade, we want it to fail this particular test.
 	_ = disruption.RecordJUnit(
 		f,
-		"[bz-Routing] console is not available via ingress",
+		hop.testName("[bz-Routing] console is not available via ingress"),
 		func() (error, bool) {
 			pollErr := wait.PollImmediateWithContext(context.TODO(), 1*time.Second, 10*time.Minute, func(ctx context.Context) (bool, error) {
 				consoleSampler := disruptioningress.CreateConsoleRouteAvailableWithNewConnections(config)
@@ -431,10 +522,15 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 	framework.Logf("Upgrade time limit set as %0.2f", upgradeDurationLimit.Minutes())
 
 	framework.Logf("Starting upgrade to version=%s image=%s attempt=%s", version.Version.String(), version.NodeImage, uid)
-	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeStartedReason, fmt.Sprintf("version/%s image/%s", version.Version.String(), version.NodeImage), false)
+	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeStartedReason, hop.note(fmt.Sprintf("version/%s image/%s", version.Version.String(), version.NodeImage)), false)
+
+	if err := hop.setWorkerPoolsBeforeUpdate(dc); err != nil {
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("failed to pause worker pools: %v", err)), true)
+		return err
+	}
 
 	// decide whether to abort at a percent
-	abortAt := upgradeAbortAt
+	abortAt := hop.abortAt
 	switch abortAt {
 	case 0:
 		// no abort
@@ -446,7 +542,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 	default:
 		maximumDuration *= 2
 		upgradeDurationLimit *= 2
-		framework.Logf("Upgrade will be aborted and the cluster will roll back to the current version after %d%% of operators have upgraded", upgradeAbortAt)
+		framework.Logf("Upgrade will be aborted and the cluster will roll back to the current version after %d%% of operators have upgraded", abortAt)
 	}
 
 	var (
@@ -461,12 +557,12 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 	defer monitor.Describe(f)
 
 	//used below in separate paths
-	clusterCompletesUpgradeTestName := "[sig-cluster-lifecycle] Cluster completes upgrade"
+	clusterCompletesUpgradeTestName := hop.testName("[sig-cluster-lifecycle] Cluster completes upgrade")
 
 	// trigger the update and record verification as an independent step
 	if err := disruption.RecordJUnit(
 		f,
-		"[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade",
+		hop.testName("[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade"),
 		func() (error, bool) {
 			cv, err := c.ConfigV1().ClusterVersions().Get(context.Background(), "version", metav1.GetOptions{})
 			if err != nil {
@@ -480,11 +576,15 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 				Image:   version.NodeImage,
 				Force:   true,
 			}
-			updateJSON, err := json.Marshal(desired)
+			spec := map[string]interface{}{"desiredUpdate": desired}
+			if len(hop.channel) > 0 {
+				framework.Logf("Changing the channel from %q to %q", cv.Spec.Channel, hop.channel)
+				spec["channel"] = hop.channel
+			}
+			patch, err := json.Marshal(map[string]interface{}{"spec": spec})
 			if err != nil {
 				return fmt.Errorf("marshal ClusterVersion patch: %v", err), false
 			}
-			patch := []byte(fmt.Sprintf(`{"spec":{"desiredUpdate": %s}}`, updateJSON))
 			cv, err = c.ConfigV1().ClusterVersions().Patch(context.Background(), original.ObjectMeta.Name, types.MergePatchType, patch, metav1.PatchOptions{})
 			if err != nil {
 				return err, false
@@ -532,13 +632,13 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			framework.Logf("Cluster version operator failed to acknowledge upgrade request")
 			return fmt.Errorf("Cluster did not complete upgrade: operator failed to acknowledge upgrade request"), false
 		})
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to acknowledge version: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("failed to acknowledge version: %v", err)), true)
 		return err
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go monitor.Disrupt(ctx, kubeClient, upgradeDisruptRebootPolicy)
+	go monitor.Disrupt(ctx, kubeClient, hop.disruptRebootPolicy)
 
 	// observe the upgrade, taking action as necessary
 	if err := disruption.RecordJUnit(
@@ -580,7 +680,7 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 					}); err != nil {
 						return false, err
 					}
-					recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeRollbackReason, fmt.Sprintf("version/%s image/%s", original.Status.Desired.Version, original.Status.Desired.Version), false)
+					recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeRollbackReason, hop.note(fmt.Sprintf("version/%s image/%s", original.Status.Desired.Version, original.Status.Desired.Version)), false)
 					aborted = true
 					action = "aborted upgrade"
 					return false, nil
@@ -596,12 +696,12 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			}
 
 			framework.Logf("Completed %s to %s", action, versionString(desired))
-			recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeVersionReason, fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Version), false)
+			recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeVersionReason, hop.note(fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Version)), false)
 
 			// record whether the cluster was fast or slow upgrading.  Don't fail the test, we still want signal on the actual tests themselves.
 			upgradeEnded := time.Now()
 			upgradeDuration := upgradeEnded.Sub(upgradeStarted)
-			testCaseName := fmt.Sprintf("[sig-cluster-lifecycle] cluster upgrade should complete in a reasonable time")
+			testCaseName := hop.testName("[sig-cluster-lifecycle] cluster upgrade should complete in a reasonable time")
 			failure := ""
 			if upgradeDuration > upgradeDurationLimit {
 				failure = fmt.Sprintf("%s to %s took too long: %0.2f minutes (for this platform/network, it should be less than %0.2f minutes)", action, versionString(desired), upgradeDuration.Minutes(), upgradeDurationLimit.Minutes())
@@ -611,14 +711,19 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			return nil, false
 		},
 	); err != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to reach cluster version: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("failed to reach cluster version: %v", err)), true)
+		return err
+	}
+
+	if err := hop.setWorkerPoolsAfterUpdate(dc); err != nil {
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("failed to unpause worker pools: %v", err)), true)
 		return err
 	}
 
 	var errMasterUpdating error
 	if err := disruption.RecordJUnit(
 		f,
-		"[sig-mco] Machine config pools complete upgrade",
+		hop.testName("[sig-mco] Machine config pools complete upgrade"),
 		func() (error, bool) {
 			framework.Logf("Waiting on pools to be upgraded")
 			if err := wait.PollImmediate(10*time.Second, 30*time.Minute, func() (bool, error) {
@@ -651,18 +756,18 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			return nil, false
 		},
 	); err != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to upgrade nodes: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("failed to upgrade nodes: %v", err)), true)
 		return err
 	}
 
 	if errMasterUpdating != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("master was updating after cluster version reached level: %v", errMasterUpdating), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("master was updating after cluster version reached level: %v", errMasterUpdating)), true)
 		return errMasterUpdating
 	}
 
 	if err := disruption.RecordJUnit(
 		f,
-		"[sig-cluster-lifecycle] ClusterOperators are available and not degraded after upgrade",
+		hop.testName("[sig-cluster-lifecycle] ClusterOperators are available and not degraded after upgrade"),
 		func() (error, bool) {
 			if err := operator.WaitForOperatorsToSettle(context.TODO(), c); err != nil {
 				return err, false
@@ -670,11 +775,11 @@ func clusterUpgrade(f *framework.Framework, c configv1client.Interface, dc dynam
 			return nil, false
 		},
 	); err != nil {
-		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, fmt.Sprintf("failed to settle operators: %v", err), true)
+		recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeFailedReason, hop.note(fmt.Sprintf("failed to settle operators: %v", err)), true)
 		return err
 	}
 
-	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeCompleteReason, fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Image), false)
+	recordClusterEvent(kubeClient, uid, "Upgrade", monitorapi.UpgradeCompleteReason, hop.note(fmt.Sprintf("version/%s image/%s", updated.Status.Desired.Version, updated.Status.Desired.Image)), false)
 	return nil
 }
 
@@ -707,6 +812,66 @@ func recordClusterEvent(client kubernetes.Interface, uid, action string, reason
 	}
 }
 
+// setWorkerPoolsBeforeUpdate pauses the worker pools before the desired update is set if the hop
+// pauses them. Pools paused by an earlier hop stay paused until the control plane reached the version
+// of this hop, so that the nodes do not start updating to the version of the earlier hop.
+func (h upgradeHop) setWorkerPoolsBeforeUpdate(dc dynamic.Interface) error {
+	if !h.pauseWorkerPools {
+		return nil
+	}
+	return setWorkerPoolsPaused(dc, true)
+}
+
+// setWorkerPoolsAfterUpdate unpauses the pools paused by earlier hops once the cluster version operator
+// reached the version of a hop that does not pause them, before waiting for the pools to update.
+func (h upgradeHop) setWorkerPoolsAfterUpdate(dc dynamic.Interface) error {
+	if h.pauseWorkerPools {
+		return nil
+	}
+	return setWorkerPoolsPaused(dc, false)
+}
+
+// pausedWorkerPools are the machine config pools paused by an earlier hop of the upgrade.
+var pausedWorkerPools = sets.NewString()
+
+// setWorkerPoolsPaused pauses the machine config pools other than master, or unpauses the pools that
+// an earlier hop paused. Pools that were paused before the upgrade are left alone.
+func setWorkerPoolsPaused(dc dynamic.Interface, paused bool) error {
+	if !paused && pausedWorkerPools.Len() == 0 {
+		return nil
+	}
+	mcps := dc.Resource(schema.GroupVersionResource{
+		Group:    "machineconfiguration.openshift.io",
+		Version:  "v1",
+		Resource: "machineconfigpools",
+	})
+	pools, err := mcps.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, p := range pools.Items {
+		name := p.GetName()
+		if name == "master" {
+			continue
+		}
+		isPaused, _, _ := unstructured.NestedBool(p.Object, "spec", "paused")
+		switch {
+		case paused && !isPaused:
+			pausedWorkerPools.Insert(name)
+		case !paused && pausedWorkerPools.Has(name):
+			pausedWorkerPools.Delete(name)
+		default:
+			continue
+		}
+		framework.Logf("Setting paused=%t on pool %s", paused, name)
+		patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, paused))
+		if _, err := mcps.Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("unable to set paused=%t on pool %s: %w", paused, name, err)
+		}
+	}
+	return nil
+}
+
 // TODO(runcom): drop this when MCO types are in openshift/api and we can use the typed client directly
 func IsPoolUpdated(dc dynamic.NamespaceableResourceInterface, name string) (poolUpToDate bool, poolIsUpdating bool) {
 	pool, err := dc.Get(context.Background(), name, metav1.GetOptions{})
@@ -0,0 +1,65 @@
+package upgrade
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestUpgradeHopWorkerPools(t *testing.T) {
+	pausedWorkerPools = sets.NewString()
+	defer func() { pausedWorkerPools = sets.NewString() }()
+
+	mcpResource := schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}
+	pool := func(name string, paused bool) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "machineconfiguration.openshift.io/v1",
+			"kind":       "MachineConfigPool",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"paused": paused},
+		}}
+	}
+	dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{mcpResource: "MachineConfigPoolList"},
+		pool("master", false), pool("worker", false), pool("infra", true),
+	)
+	expectPaused := func(step string, expected map[string]bool) {
+		t.Helper()
+		for name, paused := range expected {
+			p, err := dc.Resource(mcpResource).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isPaused, _, _ := unstructured.NestedBool(p.Object, "spec", "paused"); isPaused != paused {
+				t.Errorf("%s: expected pool %s paused=%t, got %t", step, name, paused, isPaused)
+			}
+		}
+	}
+
+	eus := upgradeHop{hopOptions: hopOptions{pauseWorkerPools: true}, number: 1, count: 2}
+	if err := eus.setWorkerPoolsBeforeUpdate(dc); err != nil {
+		t.Fatal(err)
+	}
+	expectPaused("before the update of the pausing hop", map[string]bool{"master": false, "worker": true, "infra": true})
+	if err := eus.setWorkerPoolsAfterUpdate(dc); err != nil {
+		t.Fatal(err)
+	}
+	expectPaused("after the update of the pausing hop", map[string]bool{"master": false, "worker": true, "infra": true})
+
+	// the pools stay paused while the control plane updates to the version of the next hop
+	next := upgradeHop{number: 2, count: 2}
+	if err := next.setWorkerPoolsBeforeUpdate(dc); err != nil {
+		t.Fatal(err)
+	}
+	expectPaused("before the update of the next hop", map[string]bool{"master": false, "worker": true, "infra": true})
+	if err := next.setWorkerPoolsAfterUpdate(dc); err != nil {
+		t.Fatal(err)
+	}
+	expectPaused("after the update of the next hop", map[string]bool{"master": false, "worker": false, "infra": true})
+}